@@ -12,6 +12,7 @@ import (
 	qf "github.com/tobgu/qframe"
 	"github.com/tobgu/qframe/config/csv"
 	"github.com/tobgu/qframe/config/groupby"
+	"github.com/tobgu/qframe/config/newqf"
 	"github.com/tobgu/qframe/filter"
 	"github.com/tobgu/qframe/types"
 )
@@ -238,6 +239,83 @@ func BenchmarkQFrame_SortSorted(b *testing.B) {
 	}
 }
 
+func BenchmarkQFrame_SortAlgorithms(b *testing.B) {
+	size := 10 * frameSize
+	ints := genInts(seed1, size)
+	floats := make([]float64, size)
+	for i, x := range genInts(seed2, size) {
+		floats[i] = float64(x) / 3
+	}
+
+	data := qf.New(map[string]interface{}{
+		"I":    ints,
+		"F":    floats,
+		"E":    genStringsWithCardinality(seed3, size, 20, 10),
+		"ZERO": make([]int, size),
+	}, newqf.Enums(map[string][]string{"E": nil}))
+
+	zero := qf.Order{Column: "ZERO"}
+	table := []struct {
+		name        string
+		orders      []qf.Order
+		parallelism int
+	}{
+		// Radix sort
+		{name: "int radix", orders: []qf.Order{{Column: "I"}}},
+		{name: "float radix", orders: []qf.Order{{Column: "F"}}},
+		{name: "enum radix", orders: []qf.Order{{Column: "E"}}},
+
+		// Sorting by an additional constant column forces a comparison based sort
+		{name: "int quicksort", orders: []qf.Order{{Column: "I"}, zero}},
+		{name: "float quicksort", orders: []qf.Order{{Column: "F"}, zero}},
+		{name: "enum quicksort", orders: []qf.Order{{Column: "E"}, zero}},
+		{name: "int merge sort parallelism=2", orders: []qf.Order{{Column: "I"}, zero}, parallelism: 2},
+		{name: "int merge sort parallelism=4", orders: []qf.Order{{Column: "I"}, zero}, parallelism: 4},
+		{name: "int merge sort parallelism=8", orders: []qf.Order{{Column: "I"}, zero}, parallelism: 8},
+	}
+
+	for _, tc := range table {
+		b.Run(tc.name, func(b *testing.B) {
+			df := data.WithParallelism(tc.parallelism)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				newData := df.Sort(tc.orders...)
+				if newData.Err != nil {
+					b.Errorf("Unexpected sort error: %s", newData.Err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkQFrame_TopN(b *testing.B) {
+	data := qf.New(map[string]interface{}{
+		"S1": genInts(seed1, 10*frameSize),
+		"S2": genInts(seed2, 10*frameSize)})
+	orders := []qf.Order{{Column: "S1"}, {Column: "S2", Reverse: true}}
+
+	b.Run("TopN", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			newData := data.TopN(100, orders...)
+			if newData.Err != nil {
+				b.Errorf("Unexpected top n error: %s", newData.Err)
+			}
+		}
+	})
+
+	b.Run("Sort and Slice", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			newData := data.Sort(orders...).Slice(0, 100)
+			if newData.Err != nil {
+				b.Errorf("Unexpected sort error: %s", newData.Err)
+			}
+		}
+	})
+}
+
 func csvBytes(rowCount int) []byte {
 	buf := new(bytes.Buffer)
 	writer := stdcsv.NewWriter(buf)
@@ -286,6 +364,30 @@ func BenchmarkQFrame_ReadCSV(b *testing.B) {
 	}
 }
 
+func BenchmarkQFrame_ReadBinary(b *testing.B) {
+	rowCount := 100000
+	df := qf.ReadCSV(bytes.NewReader(csvBytes(rowCount)))
+	buf := new(bytes.Buffer)
+	if err := df.ToBinary(buf); err != nil {
+		b.Fatalf("Unexpected binary error: %s", err)
+	}
+	input := buf.Bytes()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		df := qf.ReadBinary(bytes.NewReader(input))
+		if df.Err != nil {
+			b.Errorf("Unexpected binary error: %s", df.Err)
+		}
+
+		if df.Len() != rowCount {
+			b.Errorf("Unexpected size: %d", df.Len())
+		}
+	}
+}
+
 func BenchmarkQFrame_ReadCSVEnum(b *testing.B) {
 	rowCount := 100000
 	cardinality := 20
@@ -659,12 +761,15 @@ func BenchmarkGroupBy(b *testing.B) {
 		cardinality2 int
 		cardinality3 int
 		cols         []string
+		parallelism  int
 	}{
 		{name: "single col", size: 100000, cardinality1: 1000, cardinality2: 10, cardinality3: 2, cols: []string{"COL1"}},
 		{name: "triple col", size: 100000, cardinality1: 1000, cardinality2: 10, cardinality3: 2, cols: []string{"COL1", "COL2", "COL3"}},
 		{name: "high cardinality", size: 100000, cardinality1: 50000, cardinality2: 1, cardinality3: 1, cols: []string{"COL1"}},
 		{name: "low cardinality", size: 100000, cardinality1: 5, cardinality2: 1, cardinality3: 1, cols: []string{"COL1"}},
 		{name: "small frame", size: 100, cardinality1: 20, cardinality2: 1, cardinality3: 1, cols: []string{"COL1"}},
+		{name: "high cardinality parallel", size: 1000000, cardinality1: 500000, cardinality2: 1, cardinality3: 1, cols: []string{"COL1"}, parallelism: 4},
+		{name: "high cardinality serial", size: 1000000, cardinality1: 500000, cardinality2: 1, cardinality3: 1, cols: []string{"COL1"}, parallelism: 1},
 	}
 
 	for _, tc := range table {
@@ -684,7 +789,7 @@ func BenchmarkGroupBy(b *testing.B) {
 						"COL3": genStringsWithCardinality(seed3, tc.size, tc.cardinality3, 10),
 					}
 				}
-				df := qf.New(input)
+				df := qf.New(input).WithParallelism(tc.parallelism)
 				b.ReportAllocs()
 				b.ResetTimer()
 				var stats qf.GroupStats
@@ -0,0 +1,80 @@
+package corr
+
+import (
+	"github.com/tobgu/qframe/qerrors"
+)
+
+const (
+	// Pearson is the Pearson product-moment correlation coefficient.
+	Pearson = "pearson"
+
+	// Spearman is the Spearman rank correlation coefficient, the Pearson
+	// correlation of the ranks of the values. Tied values get the average rank.
+	Spearman = "spearman"
+
+	// Kendall is the Kendall rank correlation coefficient, tau-b, which
+	// adjusts for ties.
+	Kendall = "kendall"
+)
+
+// Config holds configuration for correlation and covariance calculations on QFrames.
+// It should be considered a private implementation detail and should never be
+// referenced or used directly outside of the QFrame code. To manipulate it
+// use the functions returning ConfigFunc below.
+type Config struct {
+	Columns    []string
+	Method     string
+	MinPeriods int
+}
+
+// ConfigFunc is a function that operates on a Config object.
+type ConfigFunc func(c *Config)
+
+// NewConfig creates a new Config object.
+// This function should never be called from outside QFrame.
+func NewConfig(configFns []ConfigFunc) (Config, error) {
+	config := Config{Method: Pearson, MinPeriods: 1}
+	for _, f := range configFns {
+		f(&config)
+	}
+
+	switch config.Method {
+	case Pearson, Spearman, Kendall:
+	default:
+		return config, qerrors.InvalidArgument.New("corr.NewConfig", "unknown method: %s", config.Method)
+	}
+
+	if config.MinPeriods < 1 {
+		return config, qerrors.InvalidArgument.New("corr.NewConfig", "min periods must be positive, was %d", config.MinPeriods)
+	}
+
+	return config, nil
+}
+
+// Columns sets the columns to calculate the correlation, or covariance, between.
+// The columns must be of type int or float. Leaving this configuration option out
+// will use all int and float columns in the QFrame.
+func Columns(columns ...string) ConfigFunc {
+	return func(c *Config) {
+		c.Columns = columns
+	}
+}
+
+// Method sets the method used to calculate the correlation, one of Pearson,
+// Spearman or Kendall.
+// Default is Pearson.
+func Method(method string) ConfigFunc {
+	return func(c *Config) {
+		c.Method = method
+	}
+}
+
+// MinPeriods sets the minimum number of rows, where both columns are non null,
+// that are required to calculate the correlation, or covariance, between two columns.
+// NaN is returned for pairs of columns with fewer rows.
+// Default is 1.
+func MinPeriods(n int) ConfigFunc {
+	return func(c *Config) {
+		c.MinPeriods = n
+	}
+}
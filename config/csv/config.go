@@ -118,6 +118,60 @@ func Headers(headers []string) ConfigFunc {
 	}
 }
 
+// Compression configures decompression of the CSV input.
+// Default is no compression.
+//
+// compression - Any of the compressions in package qframe/types. Use types.CompressionAuto
+// to detect the compression from the input.
+func Compression(compression types.Compression) ConfigFunc {
+	return func(c *Config) {
+		c.Compression = compression
+	}
+}
+
+// BadRowPolicy determines how rows that cannot be read are handled.
+type BadRowPolicy qfio.BadRowPolicy
+
+const (
+	// FailOnBadRow fails reading on the first bad row. This is the default.
+	FailOnBadRow = BadRowPolicy(qfio.BadRowFail)
+
+	// SkipBadRows drops bad rows.
+	SkipBadRows = BadRowPolicy(qfio.BadRowSkip)
+
+	// CollectBadRows drops bad rows and reports them.
+	CollectBadRows = BadRowPolicy(qfio.BadRowCollect)
+)
+
+// BadRow describes a row that could not be read, see BadRows.
+type BadRow = qfio.BadRow
+
+// BadRows sets the policy for rows that cannot be read. A row is bad if it has
+// the wrong number of columns or if a field in a column with a type set by Types
+// cannot be converted into that type.
+//
+// policy - Any of the policies above.
+// collected - Bad rows, in line order, are appended to collected if the policy is CollectBadRows.
+func BadRows(policy BadRowPolicy, collected *[]BadRow) ConfigFunc {
+	return func(c *Config) {
+		c.BadRowPolicy = qfio.BadRowPolicy(policy)
+		c.BadRows = nil
+		if policy == CollectBadRows {
+			c.BadRows = collected
+		}
+	}
+}
+
+// NullInvalid makes fields in float, int and enum columns, with a type set by Types,
+// that cannot be converted into the column type read as missing values rather than
+// being handled as bad rows. Int columns containing missing values are read as float
+// columns. Default is false.
+func NullInvalid(nullInvalid bool) ConfigFunc {
+	return func(c *Config) {
+		c.NullInvalid = nullInvalid
+	}
+}
+
 // ToConfig holds configuration for writing CSV files
 type ToConfig qfio.ToCsvConfig
 
@@ -127,7 +181,7 @@ type ToConfigFunc func(*ToConfig)
 // NewConfig creates a new ToConfig object.
 // This function should never be called from outside QFrame.
 func NewToConfig(ff []ToConfigFunc) ToConfig {
-	conf := ToConfig{Header: true} //Default
+	conf := ToConfig{Header: true, Delimiter: ',', LineTerminator: "\n", FloatPrecision: -1} //Default
 	for _, f := range ff {
 		f(&conf)
 	}
@@ -148,3 +202,72 @@ func Columns(cols []string) ToConfigFunc {
 		c.Columns = cols
 	}
 }
+
+// ToDelimiter configures the delimiter/separator between columns when writing.
+// Only byte representable delimiters are supported. Quote, carriage return
+// and newline are not valid delimiters. Default is ','.
+//
+// delimiter - The delimiter to use.
+func ToDelimiter(delimiter byte) ToConfigFunc {
+	return func(c *ToConfig) {
+		c.Delimiter = delimiter
+	}
+}
+
+// NullRep sets the string written for null values. Default is the empty string.
+// Non null strings equal to nullRep are quoted to keep them apart from null.
+//
+// nullRep - The null representation, eg. "NULL" or "\N".
+func NullRep(nullRep string) ToConfigFunc {
+	return func(c *ToConfig) {
+		c.NullRep = nullRep
+	}
+}
+
+// QuoteStyle determines which fields are quoted when writing CSV.
+type QuoteStyle qfio.QuoteStyle
+
+const (
+	// QuoteMinimal only quotes fields containing the delimiter, quotes, line breaks
+	// or leading white space. This is the default.
+	QuoteMinimal = QuoteStyle(qfio.QuoteMinimal)
+
+	// QuoteAll quotes all fields except null values.
+	QuoteAll = QuoteStyle(qfio.QuoteAll)
+
+	// QuoteNonNumeric quotes all fields except null values and values in int and float columns.
+	QuoteNonNumeric = QuoteStyle(qfio.QuoteNonNumeric)
+)
+
+// Quoting sets the quoting policy used when writing fields.
+func Quoting(style QuoteStyle) ToConfigFunc {
+	return func(c *ToConfig) {
+		c.Quoting = qfio.QuoteStyle(style)
+	}
+}
+
+// LineTerminator sets the string used to terminate each line. Default is "\n".
+func LineTerminator(terminator string) ToConfigFunc {
+	return func(c *ToConfig) {
+		c.LineTerminator = terminator
+	}
+}
+
+// FloatPrecision sets the number of decimals used when writing floats.
+// The default, -1, uses the smallest number of digits necessary to represent
+// the value exactly.
+func FloatPrecision(precision int) ToConfigFunc {
+	return func(c *ToConfig) {
+		c.FloatPrecision = precision
+	}
+}
+
+// ToCompression configures compression of the written CSV.
+// Default is no compression.
+//
+// compression - types.CompressionNone, types.CompressionGzip or types.CompressionZstd.
+func ToCompression(compression types.Compression) ToConfigFunc {
+	return func(c *ToConfig) {
+		c.Compression = compression
+	}
+}
@@ -0,0 +1,96 @@
+package cut
+
+import (
+	"github.com/tobgu/qframe/qerrors"
+)
+
+// Config holds configuration for binning of numeric columns using Cut and QCut.
+// It should be considered a private implementation detail and should never be
+// referenced or used directly outside of the QFrame code. To manipulate it
+// use the functions returning ConfigFunc below.
+type Config struct {
+	Bins      int
+	Edges     []float64
+	Labels    []string
+	Left      bool
+	Precision int
+}
+
+// ConfigFunc is a function that operates on a Config object.
+type ConfigFunc func(c *Config)
+
+// NewConfig creates a new Config object. Errors are reported for operation,
+// the name of the function called.
+// This function should never be called from outside QFrame.
+func NewConfig(operation string, configFns []ConfigFunc) (Config, error) {
+	config := Config{Precision: 3}
+	for _, f := range configFns {
+		f(&config)
+	}
+
+	if config.Bins == 0 && len(config.Edges) == 0 {
+		return config, qerrors.InvalidArgument.New(operation, "one of bins or edges must be specified")
+	}
+
+	if config.Bins != 0 && len(config.Edges) != 0 {
+		return config, qerrors.InvalidArgument.New(operation, "bins and edges are mutually exclusive")
+	}
+
+	if config.Bins < 0 {
+		return config, qerrors.InvalidArgument.New(operation, "number of bins must be positive, was %d", config.Bins)
+	}
+
+	if len(config.Edges) == 1 {
+		return config, qerrors.InvalidArgument.New(operation, "at least two edges must be specified")
+	}
+
+	if config.Precision < 0 {
+		return config, qerrors.InvalidArgument.New(operation, "precision must be non negative, was %d", config.Precision)
+	}
+
+	return config, nil
+}
+
+// Bins sets the number of bins. Cut creates bins of equal width between the
+// minimum and maximum value of the column. QCut creates bins holding (about)
+// the same number of values.
+func Bins(n int) ConfigFunc {
+	return func(c *Config) {
+		c.Bins = n
+	}
+}
+
+// Edges sets the edges of the bins explicitly, n edges create n-1 bins. For Cut
+// the edges are values, for QCut they are quantiles in the range [0, 1].
+// The edges must be strictly increasing.
+func Edges(edges ...float64) ConfigFunc {
+	return func(c *Config) {
+		c.Edges = edges
+	}
+}
+
+// Labels sets the labels of the bins, one per bin in bin order. By default
+// bins are labeled by the interval they represent, eg. "(1.5, 3]".
+func Labels(labels ...string) ConfigFunc {
+	return func(c *Config) {
+		c.Labels = labels
+	}
+}
+
+// Left configures if the bins should be closed on the left, [a, b), rather than
+// on the right, (a, b]. The outermost edge that is open is included in the
+// first, or last, bin to make sure that the minimum, or maximum, value is binned.
+// Default is false.
+func Left(b bool) ConfigFunc {
+	return func(c *Config) {
+		c.Left = b
+	}
+}
+
+// Precision sets the number of decimals of the edges in the default labels.
+// Default is 3.
+func Precision(p int) ConfigFunc {
+	return func(c *Config) {
+		c.Precision = p
+	}
+}
@@ -0,0 +1,106 @@
+package fixedwidth
+
+import (
+	qfio "github.com/tobgu/qframe/internal/io"
+	"github.com/tobgu/qframe/types"
+)
+
+// Config holds configuration for reading fixed width text files into QFrames.
+// It should be considered a private implementation detail and should never be
+// referenced or used directly outside of the QFrame code. To manipulate it
+// use the functions returning ConfigFunc below.
+type Config qfio.FixedWidthConfig
+
+// ConfigFunc is a function that operates on a Config object.
+type ConfigFunc func(*Config)
+
+// NewConfig creates a new Config object.
+// This function should never be called from outside QFrame.
+func NewConfig(ff []ConfigFunc) Config {
+	conf := Config{}
+	for _, f := range ff {
+		f(&conf)
+	}
+	return conf
+}
+
+// Column describes the position and type of a column in the file.
+type Column struct {
+	// Name is the name of the column in the resulting QFrame.
+	Name string
+
+	// Start is the zero based byte offset of the column within each line.
+	Start int
+
+	// Width is the number of bytes occupied by the column.
+	Width int
+
+	// Type is the type name of the column. For a list of type names see package qframe/types.
+	// If empty a best effort attempt will be done to auto detect the type.
+	Type string
+}
+
+// Columns specifies the columns to read, in order. This option is mandatory.
+//
+// Fields are trimmed of leading and trailing spaces before being converted. Lines that
+// are too short to hold a field produce an empty value for that field.
+func Columns(columns ...Column) ConfigFunc {
+	return func(c *Config) {
+		c.Columns = make([]qfio.FixedWidthColumn, len(columns))
+		for i, col := range columns {
+			c.Columns[i] = qfio.FixedWidthColumn{
+				Name: col.Name, Start: col.Start, Width: col.Width, Type: types.DataType(col.Type)}
+		}
+	}
+}
+
+// EmptyNull configures if empty fields should be considered as empty strings (default) or null.
+//
+// emptyNull - If set to true empty fields will be translated to null.
+func EmptyNull(emptyNull bool) ConfigFunc {
+	return func(c *Config) {
+		c.EmptyNull = emptyNull
+	}
+}
+
+// IgnoreEmptyLines configures if a line without any characters should be ignored or interpreted
+// as a row of empty fields.
+//
+// ignoreEmptyLines - If set to true empty lines will not produce any data.
+func IgnoreEmptyLines(ignoreEmptyLines bool) ConfigFunc {
+	return func(c *Config) {
+		c.IgnoreEmptyLines = ignoreEmptyLines
+	}
+}
+
+// SkipLines sets a number of leading lines, eg. headers, that should not be read as data.
+func SkipLines(count int) ConfigFunc {
+	return func(c *Config) {
+		c.SkipLines = count
+	}
+}
+
+// EnumValues is used to list the possible values and internal order of these values for an enum column.
+//
+// values - map column name -> list of valid values.
+//
+// Note that the column must be specified as having an enum type for this option to take effect.
+// See csv.EnumValues for further details.
+func EnumValues(values map[string][]string) ConfigFunc {
+	return func(c *Config) {
+		c.EnumVals = make(map[string][]string)
+		for k, v := range values {
+			c.EnumVals[k] = v
+		}
+	}
+}
+
+// Compression configures decompression of the input.
+// Default is no compression.
+//
+// compression - Any of the compressions in package qframe/types.
+func Compression(compression types.Compression) ConfigFunc {
+	return func(c *Config) {
+		c.Compression = compression
+	}
+}
@@ -7,6 +7,7 @@ package groupby
 type Config struct {
 	Columns     []string
 	GroupByNull bool
+	Unordered   bool
 	// dropNulls?
 }
 
@@ -47,3 +48,14 @@ func Null(b bool) ConfigFunc {
 		c.GroupByNull = b
 	}
 }
+
+// Unordered configures if the ordering of groups may be relaxed. By default groups,
+// and distinct rows, are ordered by the position of their first row in the QFrame.
+// When grouping in parallel this requires a sort of the groups that can be avoided
+// by relaxing the ordering, the order of the groups is then undefined.
+// Default is false.
+func Unordered(b bool) ConfigFunc {
+	return func(c *Config) {
+		c.Unordered = b
+	}
+}
@@ -0,0 +1,75 @@
+package json
+
+import (
+	"github.com/tobgu/qframe/config/newqf"
+	qfio "github.com/tobgu/qframe/internal/io"
+	"github.com/tobgu/qframe/types"
+)
+
+// Config holds configuration for reading JSON.
+// It should be considered a private implementation detail and should never be
+// referenced or used directly outside of the QFrame code. To manipulate it
+// use the functions returning ConfigFunc below.
+type Config qfio.JSONConfig
+
+// ConfigFunc is a function that operates on a Config object.
+type ConfigFunc func(*Config)
+
+// NewConfig creates a new Config object.
+// This function should never be called from outside QFrame.
+func NewConfig(ff []ConfigFunc) Config {
+	conf := Config{Compression: types.CompressionAuto}
+	for _, f := range ff {
+		f(&conf)
+	}
+	return conf
+}
+
+// Compression configures decompression of the JSON input.
+// Default is types.CompressionAuto, detecting the compression from the input.
+//
+// compression - Any of the compressions in package qframe/types.
+func Compression(compression types.Compression) ConfigFunc {
+	return func(c *Config) {
+		c.Compression = compression
+	}
+}
+
+// QFrame configures the creation of the QFrame from the JSON data
+// using the same options as qframe.New, see package newqf.
+func QFrame(fns ...newqf.ConfigFunc) ConfigFunc {
+	return func(c *Config) {
+		conf := newqf.NewConfig(fns)
+		c.ColumnOrder = conf.ColumnOrder
+		c.EnumColumns = conf.EnumColumns
+	}
+}
+
+// ToConfig holds configuration for writing JSON.
+// It should be considered a private implementation detail and should never be
+// referenced or used directly outside of the QFrame code. To manipulate it
+// use the functions returning ToConfigFunc below.
+type ToConfig qfio.ToJSONConfig
+
+// ToConfigFunc is a function that operates on a ToConfig object.
+type ToConfigFunc func(*ToConfig)
+
+// NewToConfig creates a new ToConfig object.
+// This function should never be called from outside QFrame.
+func NewToConfig(ff []ToConfigFunc) ToConfig {
+	conf := ToConfig{}
+	for _, f := range ff {
+		f(&conf)
+	}
+	return conf
+}
+
+// ToCompression configures compression of the written JSON.
+// Default is no compression.
+//
+// compression - types.CompressionNone, types.CompressionGzip or types.CompressionZstd.
+func ToCompression(compression types.Compression) ToConfigFunc {
+	return func(c *ToConfig) {
+		c.Compression = compression
+	}
+}
@@ -1,6 +1,8 @@
 package sql
 
 import (
+	"strings"
+
 	qsqlio "github.com/tobgu/qframe/internal/io/sql"
 )
 
@@ -70,6 +72,8 @@ func Postgres() ConfigFunc {
 	return func(c *Config) {
 		EscapeChar('"')(c)
 		Incrementing()(c)
+		ParamLimit(65535)(c)
+		c.Dialect = qsqlio.PostgresDialect
 	}
 }
 
@@ -79,6 +83,10 @@ func Postgres() ConfigFunc {
 func SQLite() ConfigFunc {
 	return func(c *Config) {
 		EscapeChar('"')(c)
+		// SQLITE_MAX_VARIABLE_NUMBER defaults to 999
+		// in versions prior to 3.32.0.
+		ParamLimit(999)(c)
+		c.Dialect = qsqlio.SQLiteDialect
 	}
 }
 
@@ -88,6 +96,8 @@ func SQLite() ConfigFunc {
 func MySQL() ConfigFunc {
 	return func(c *Config) {
 		EscapeChar('`')(c)
+		ParamLimit(65535)(c)
+		c.Dialect = qsqlio.MySQLDialect
 	}
 }
 
@@ -128,3 +138,98 @@ func Precision(i int) ConfigFunc {
 		c.Precision = i
 	}
 }
+
+// BatchSize sets the maximum number of rows written
+// by each INSERT statement. Rows are inserted using
+// multi-row VALUES clauses which is a lot faster than
+// inserting one row at a time. The number of rows per
+// statement is reduced if needed to stay within the
+// ParamLimit of the database. Default is 1.
+func BatchSize(rows int) ConfigFunc {
+	return func(c *Config) {
+		c.BatchSize = rows
+	}
+}
+
+// ParamLimit sets the maximum number of parameter
+// markers allowed in a single statement. It is set
+// by the dialect functions Postgres, SQLite and MySQL
+// and only needs to be set explicitly for other
+// databases or non default database configurations.
+func ParamLimit(limit int) ConfigFunc {
+	return func(c *Config) {
+		c.ParamLimit = limit
+	}
+}
+
+// ConflictColumns enables "insert or update" semantics
+// when writing. Rows conflicting with existing rows on
+// the given columns are updated instead of inserted.
+// The columns must be covered by a unique index or
+// primary key in the database.
+//
+// ON CONFLICT ... DO UPDATE is generated for PostgreSQL
+// and SQLite. ON DUPLICATE KEY UPDATE is generated for
+// MySQL which considers conflicts on any unique index,
+// regardless of the columns given here.
+//
+// When inserting in batches, see BatchSize, rows with the
+// same values in the conflict columns are written using
+// separate statements. PostgreSQL does not allow a single
+// statement to update the same row more than once.
+func ConflictColumns(columns ...string) ConfigFunc {
+	return func(c *Config) {
+		c.ConflictColumns = columns
+	}
+}
+
+// UpdateColumns sets the columns that are updated when
+// a row conflicts with an existing row, see ConflictColumns.
+// By default all columns except the conflict columns are
+// updated. If no columns are given conflicting rows are
+// left as is.
+func UpdateColumns(columns ...string) ConfigFunc {
+	return func(c *Config) {
+		c.UpdateColumns = make([]string, len(columns))
+		copy(c.UpdateColumns, columns)
+	}
+}
+
+// CreateTable indicates that the table should be created,
+// using the column types of the QFrame, before writing to it.
+// See QFrame.CreateTableSQL for details on the generated SQL.
+func CreateTable() ConfigFunc {
+	return func(c *Config) {
+		c.CreateTable = true
+	}
+}
+
+// PrimaryKey sets the columns making up the primary key
+// of created tables.
+func PrimaryKey(columns ...string) ConfigFunc {
+	return func(c *Config) {
+		c.PrimaryKey = make([]string, len(columns))
+		copy(c.PrimaryKey, columns)
+	}
+}
+
+// EnumTypes sets the database type names, as reported by
+// the driver column type metadata, of columns that should
+// be read into enum columns. Eg. EnumTypes("MOOD") for a
+// Postgres column of the user defined enum type "mood".
+func EnumTypes(typeNames ...string) ConfigFunc {
+	return func(c *Config) {
+		c.EnumTypes = make(map[string]bool, len(typeNames))
+		for _, name := range typeNames {
+			c.EnumTypes[strings.ToUpper(name)] = true
+		}
+	}
+}
+
+// ChunkSize sets the maximum number of rows in each QFrame
+// when reading query results in chunks, see qframe.ReadSQLChunks.
+func ChunkSize(rows int) ConfigFunc {
+	return func(c *Config) {
+		c.ChunkSize = rows
+	}
+}
@@ -0,0 +1,64 @@
+package valuecounts
+
+// Config holds configuration for counting the distinct values of QFrames.
+// It should be considered a private implementation detail and should never be
+// referenced or used directly outside of the QFrame code. To manipulate it
+// use the functions returning ConfigFunc below.
+type Config struct {
+	Columns   []string
+	Normalize bool
+	Unsorted  bool
+	DropNull  bool
+}
+
+// ConfigFunc is a function that operates on a Config object.
+type ConfigFunc func(c *Config)
+
+// NewConfig creates a new Config object.
+// This function should never be called from outside QFrame.
+func NewConfig(configFns []ConfigFunc) Config {
+	var config Config
+	for _, f := range configFns {
+		f(&config)
+	}
+
+	return config
+}
+
+// Columns sets the columns whose combinations of values should be counted.
+// Leaving this configuration option out will count combinations of all columns
+// in the QFrame.
+func Columns(columns ...string) ConfigFunc {
+	return func(c *Config) {
+		c.Columns = columns
+	}
+}
+
+// Normalize configures if the relative frequency of each value should be returned,
+// in a float column named "proportion", instead of the number of occurrences, in
+// an int column named "count".
+// Default is false.
+func Normalize(b bool) ConfigFunc {
+	return func(c *Config) {
+		c.Normalize = b
+	}
+}
+
+// Sort configures if the values should be sorted by descending frequency. If not
+// sorted the values are ordered by the position of their first row in the QFrame.
+// Values with the same frequency keep that order also when sorted.
+// Default is true.
+func Sort(b bool) ConfigFunc {
+	return func(c *Config) {
+		c.Unsorted = !b
+	}
+}
+
+// DropNull configures if rows with Na/null in any of the counted columns should
+// be excluded. If not excluded nulls are counted as a value of their own.
+// Default is false.
+func DropNull(b bool) ConfigFunc {
+	return func(c *Config) {
+		c.DropNull = b
+	}
+}
@@ -0,0 +1,2 @@
+// Package qmat provides conversion between QFrame and gonum.org/v1/gonum/mat
+package qmat
@@ -0,0 +1,102 @@
+package qmat
+
+import (
+	"strconv"
+
+	"gonum.org/v1/gonum/mat"
+
+	"github.com/tobgu/qframe"
+	"github.com/tobgu/qframe/config/newqf"
+	"github.com/tobgu/qframe/qerrors"
+	"github.com/tobgu/qframe/types"
+)
+
+// NewDense returns a new Dense matrix with one row per row in qf and
+// one column per column in columns, or per int and float column in qf
+// if no columns are given. The columns must be numeric, null float
+// values are NaN in the matrix.
+//
+// The labelled matrices returned by QFrame.Corr and QFrame.Cov can be
+// converted without specifying any columns:
+//
+//	m, err := qmat.NewDense(qf.Corr())
+func NewDense(qf qframe.QFrame, columns ...string) (*mat.Dense, error) {
+	if qf.Err != nil {
+		return nil, qerrors.Propagate("NewDense", qf.Err)
+	}
+
+	if len(columns) == 0 {
+		for _, col := range qf.ColumnNames() {
+			if t := qf.ColumnTypeMap()[col]; t == types.Int || t == types.Float {
+				columns = append(columns, col)
+			}
+		}
+	}
+
+	if qf.Len() == 0 || len(columns) == 0 {
+		return nil, qerrors.InvalidArgument.New("NewDense", "cannot create an empty matrix")
+	}
+
+	rows := qf.Len()
+	data := make([]float64, rows*len(columns))
+	for j, col := range columns {
+		switch qf.ColumnTypeMap()[col] {
+		case types.Int:
+			view := qf.MustIntView(col)
+			for i := 0; i < rows; i++ {
+				data[i*len(columns)+j] = float64(view.ItemAt(i))
+			}
+		case types.Float:
+			view := qf.MustFloatView(col)
+			for i := 0; i < rows; i++ {
+				data[i*len(columns)+j] = view.ItemAt(i)
+			}
+		default:
+			if !qf.Contains(col) {
+				return nil, qerrors.UnknownColumn.New("NewDense", "QFrame does not contain column %s", col)
+			}
+			return nil, qerrors.TypeMismatch.New("NewDense", "column %s is not a numeric column", col)
+		}
+	}
+
+	return mat.NewDense(rows, len(columns), data), nil
+}
+
+// MustNewDense returns a new Dense matrix and panics
+// when an error is encountered.
+func MustNewDense(qf qframe.QFrame, columns ...string) *mat.Dense {
+	m, err := NewDense(qf, columns...)
+	if err != nil {
+		panic(qerrors.Propagate("MustNewDense", err))
+	}
+	return m
+}
+
+// NewQFrame returns a new QFrame with one float column per column in m.
+// The columns are named by columns, or by their positions, "0", "1", ...,
+// if no columns are given.
+func NewQFrame(m mat.Matrix, columns ...string) qframe.QFrame {
+	rows, cols := m.Dims()
+	if len(columns) == 0 {
+		columns = make([]string, cols)
+		for j := range columns {
+			columns[j] = strconv.Itoa(j)
+		}
+	}
+
+	if len(columns) != cols {
+		return qframe.QFrame{Err: qerrors.InvalidArgument.New(
+			"NewQFrame", "expected %d column names, one per matrix column, was %d", cols, len(columns))}
+	}
+
+	data := make(map[string]interface{}, cols)
+	for j, col := range columns {
+		values := make([]float64, rows)
+		for i := range values {
+			values[i] = m.At(i, j)
+		}
+		data[col] = values
+	}
+
+	return qframe.New(data, newqf.ColumnOrder(columns...))
+}
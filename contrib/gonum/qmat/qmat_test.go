@@ -0,0 +1,88 @@
+package qmat_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+
+	"github.com/tobgu/qframe"
+	"github.com/tobgu/qframe/config/newqf"
+	"github.com/tobgu/qframe/contrib/gonum/qmat"
+	"github.com/tobgu/qframe/qerrors"
+)
+
+func TestNewDense(t *testing.T) {
+	qf := qframe.New(map[string]interface{}{
+		"i": []int{1, 2, 3},
+		"s": []string{"a", "b", "c"},
+		"f": []float64{0.5, math.NaN(), 1.5},
+	}, newqf.ColumnOrder("i", "s", "f"))
+
+	m, err := qmat.NewDense(qf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]float64{{1, 0.5}, {2, math.NaN()}, {3, 1.5}}
+	if r, c := m.Dims(); r != 3 || c != 2 {
+		t.Fatalf("Unexpected dimensions: %d, %d", r, c)
+	}
+	for i, row := range expected {
+		for j, e := range row {
+			if a := m.At(i, j); a != e && !(math.IsNaN(a) && math.IsNaN(e)) {
+				t.Errorf("Unexpected value at %d, %d: expected %v, was %v", i, j, e, a)
+			}
+		}
+	}
+
+	m = qmat.MustNewDense(qf, "f", "i")
+	if r, c := m.Dims(); r != 3 || c != 2 || m.At(2, 0) != 1.5 || m.At(2, 1) != 3 {
+		t.Errorf("Unexpected matrix: %v", mat.Formatted(m))
+	}
+
+	for _, tc := range []struct {
+		columns []string
+		kind    qerrors.Kind
+	}{
+		{columns: []string{"s"}, kind: qerrors.TypeMismatch},
+		{columns: []string{"x"}, kind: qerrors.UnknownColumn},
+	} {
+		if _, err := qmat.NewDense(qf, tc.columns...); !errors.Is(err, tc.kind) {
+			t.Errorf("Unexpected error for %v: %v", tc.columns, err)
+		}
+	}
+}
+
+func TestNewDense_Corr(t *testing.T) {
+	qf := qframe.New(map[string]interface{}{
+		"a": []float64{1, 2, 3, 4},
+		"b": []float64{4, 3, 2, 1},
+	}, newqf.ColumnOrder("a", "b"))
+
+	m := qmat.MustNewDense(qf.Corr())
+	if !mat.EqualApprox(m, mat.NewDense(2, 2, []float64{1, -1, -1, 1}), 1e-12) {
+		t.Errorf("Unexpected matrix: %v", mat.Formatted(m))
+	}
+}
+
+func TestNewQFrame(t *testing.T) {
+	m := mat.NewDense(2, 2, []float64{1, 2, 3, 4})
+	expected := qframe.New(map[string]interface{}{
+		"x": []float64{1, 3},
+		"y": []float64{2, 4},
+	}, newqf.ColumnOrder("x", "y"))
+
+	if equal, reason := expected.Equals(qmat.NewQFrame(m, "x", "y")); !equal {
+		t.Error(reason)
+	}
+
+	if names := qmat.NewQFrame(m).ColumnNames(); names[0] != "0" || names[1] != "1" {
+		t.Errorf("Unexpected column names: %v", names)
+	}
+
+	if qf := qmat.NewQFrame(m, "x"); !errors.Is(qf.Err, qerrors.InvalidArgument) {
+		t.Errorf("Unexpected error: %v", qf.Err)
+	}
+}
@@ -3,7 +3,11 @@ package qplot
 import (
 	"strconv"
 
+	"gonum.org/v1/plot/plotter"
+
 	"github.com/tobgu/qframe"
+	"github.com/tobgu/qframe/config/cut"
+	"github.com/tobgu/qframe/config/valuecounts"
 	"github.com/tobgu/qframe/qerrors"
 	"github.com/tobgu/qframe/types"
 )
@@ -319,3 +323,48 @@ func MustNewXErrorer(low, high string, qf qframe.QFrame) XErrorer {
 // specific AFAICT to be generalized here. It can easily
 // be implemented by wrapping a QFrame or composing
 // several ValueFunc together.
+
+// NewHistogramBins returns histogram bins with the number of
+// values in col that fall within each pair of consecutive edges.
+// The bins are closed on the right, see QFrame.Cut, edges may
+// be calculated using QFrame.CutEdges or QFrame.QCutEdges.
+// Null values and values outside of the edges are not counted.
+func NewHistogramBins(col string, edges []float64, qf qframe.QFrame) ([]plotter.HistogramBin, error) {
+	if len(edges) < 2 {
+		return nil, qerrors.InvalidArgument.New("NewHistogramBins", "at least two edges must be specified")
+	}
+
+	labels := make([]string, len(edges)-1)
+	for i := range labels {
+		labels[i] = strconv.Itoa(i)
+	}
+
+	counts := qf.Select(col).
+		Cut("bin", col, cut.Edges(edges...), cut.Labels(labels...)).
+		ValueCounts(valuecounts.Columns("bin"), valuecounts.DropNull(true), valuecounts.Sort(false))
+	if counts.Err != nil {
+		return nil, qerrors.Propagate("NewHistogramBins", counts.Err)
+	}
+
+	bins := make([]plotter.HistogramBin, len(labels))
+	for i := range bins {
+		bins[i].Min, bins[i].Max = edges[i], edges[i+1]
+	}
+
+	binView, countView := counts.MustEnumView("bin"), counts.MustIntView("count")
+	for i := 0; i < counts.Len(); i++ {
+		b, _ := strconv.Atoi(*binView.ItemAt(i))
+		bins[b].Weight = float64(countView.ItemAt(i))
+	}
+	return bins, nil
+}
+
+// MustNewHistogramBins returns histogram bins with the number
+// of values in col that fall within each pair of consecutive edges.
+func MustNewHistogramBins(col string, edges []float64, qf qframe.QFrame) []plotter.HistogramBin {
+	bins, err := NewHistogramBins(col, edges, qf)
+	if err != nil {
+		panic(qerrors.Propagate("MustNewHistogramBins", err))
+	}
+	return bins
+}
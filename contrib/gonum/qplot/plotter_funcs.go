@@ -1,6 +1,8 @@
 package qplot
 
 import (
+	"image/color"
+
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
 	"gonum.org/v1/plot/vg"
@@ -65,6 +67,29 @@ func HistogramPlotter(xyer plotter.XYer, n int, cfg HistogramConfig) PlotterFunc
 	}
 }
 
+// BinnedHistogramPlotter returns a new PlotterFunc that plots a histogram
+// of precalculated bins, such as those returned by NewHistogramBins.
+// Unlike for HistogramPlotter the bins may be of different widths, the
+// mean width is used when normalizing the histogram.
+func BinnedHistogramPlotter(bins []plotter.HistogramBin, cfg HistogramConfig) PlotterFunc {
+	return func(plt *plot.Plot) (plot.Plotter, error) {
+		if len(bins) == 0 {
+			return nil, qerrors.InvalidArgument.New("BinnedHistogramPlotter", "no bins to plot")
+		}
+
+		pltr := &plotter.Histogram{
+			Bins:      bins,
+			Width:     (bins[len(bins)-1].Max - bins[0].Min) / float64(len(bins)),
+			FillColor: color.Gray{Y: 128},
+			LineStyle: plotter.DefaultLineStyle,
+		}
+		if cfg != nil {
+			cfg(plt, pltr)
+		}
+		return pltr, nil
+	}
+}
+
 // PolygonConfig is an optional function which
 // configures a Polygon after creation.
 type PolygonConfig func(*plot.Plot, *plotter.Polygon)
@@ -1,8 +1,15 @@
 package qplot_test
 
 import (
+	"math"
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
 
+	"github.com/tobgu/qframe"
+	"github.com/tobgu/qframe/config/cut"
 	"github.com/tobgu/qframe/contrib/gonum/qplot"
 )
 
@@ -14,3 +21,42 @@ var (
 	_ plotter.YErrorer   = (*qplot.YErrorer)(nil)
 	_ plotter.XErrorer   = (*qplot.XErrorer)(nil)
 )
+
+func TestNewHistogramBins(t *testing.T) {
+	qf := qframe.New(map[string]interface{}{
+		"count": []float64{0, 1, 2.5, 4, math.NaN(), 5, 10, 11},
+	})
+
+	edges, err := qf.QCutEdges("count", cut.Bins(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bins, err := qplot.NewHistogramBins("count", edges, qf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []plotter.HistogramBin{{Min: 0, Max: 4, Weight: 4}, {Min: 4, Max: 11, Weight: 3}}
+	if !reflect.DeepEqual(bins, expected) {
+		t.Errorf("Unexpected bins: %v", bins)
+	}
+
+	plt, err := plot.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pltr, err := qplot.BinnedHistogramPlotter(bins, nil)(plt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if xmin, xmax, _, ymax := pltr.(plot.DataRanger).DataRange(); xmin != 0 || xmax != 11 || ymax != 4 {
+		t.Errorf("Unexpected data range: %v, %v, %v", xmin, xmax, ymax)
+	}
+
+	if _, err := qplot.NewHistogramBins("count", []float64{1}, qf); err == nil {
+		t.Error("Expected error")
+	}
+}
@@ -0,0 +1,247 @@
+package qframe
+
+import (
+	"math"
+	"sort"
+
+	"github.com/tobgu/qframe/config/corr"
+	"github.com/tobgu/qframe/config/newqf"
+	"github.com/tobgu/qframe/qerrors"
+	"github.com/tobgu/qframe/types"
+)
+
+// Corr returns the correlation matrix of the specified numeric columns, or of all int
+// and float columns if none are specified. The returned QFrame has a string column named
+// "column" with the names of the columns followed by one float column per column. The
+// value in row i of column j is the correlation between column i and column j.
+//
+// The correlation is calculated using the Pearson, Spearman or Kendall method. Nulls
+// are handled pairwise, each pair of columns uses the rows where both columns are non
+// null. The correlation is NaN when fewer than the minimum number of rows remain or
+// when either column is constant.
+//
+// Time complexity O(m^2 * n) where m = number of columns, n = number of rows, for the Pearson
+// method. O(m^2 * n * log(n)) for the Spearman method and O(m^2 * n^2) for the Kendall method.
+func (qf QFrame) Corr(configFns ...corr.ConfigFunc) QFrame {
+	return qf.pairwise("Corr", configFns, true, map[string]pairFunc{
+		corr.Pearson:  pearson,
+		corr.Spearman: func(x, y []float64) float64 { return pearson(rank(x), rank(y)) },
+		corr.Kendall:  kendall,
+	})
+}
+
+// Cov returns the covariance matrix of the specified numeric columns, or of all int
+// and float columns if none are specified. The covariance is the sample covariance,
+// normalized by n-1. The Spearman method returns the covariance of the ranks of the
+// values, the Kendall method is not supported. Apart from that the returned QFrame and
+// the handling of nulls are the same as for Corr.
+//
+// Time complexity O(m^2 * n) where m = number of columns, n = number of rows, for the Pearson
+// method. O(m^2 * n * log(n)) for the Spearman method.
+func (qf QFrame) Cov(configFns ...corr.ConfigFunc) QFrame {
+	return qf.pairwise("Cov", configFns, false, map[string]pairFunc{
+		corr.Pearson:  covariance,
+		corr.Spearman: func(x, y []float64) float64 { return covariance(rank(x), rank(y)) },
+	})
+}
+
+type pairFunc func(x, y []float64) float64
+
+// pairwise calculates the function in methods of the configured method for all pairs of
+// columns. If unitDiagonal is set the value for a column paired with itself is 1, unless
+// undefined, which avoids rounding errors.
+func (qf QFrame) pairwise(operation string, configFns []corr.ConfigFunc, unitDiagonal bool, methods map[string]pairFunc) QFrame {
+	if qf.Err != nil {
+		return qf
+	}
+
+	config, err := corr.NewConfig(configFns)
+	if err != nil {
+		return qf.withErr(qerrors.Propagate(operation, err))
+	}
+
+	fn, ok := methods[config.Method]
+	if !ok {
+		return qf.withErr(qerrors.InvalidArgument.New(operation, "method %s not supported", config.Method))
+	}
+
+	columns := config.Columns
+	if len(columns) == 0 {
+		for _, col := range qf.columns {
+			if dt := col.DataType(); dt == types.Int || dt == types.Float {
+				columns = append(columns, col.name)
+			}
+		}
+	}
+
+	for _, col := range columns {
+		if col == "column" {
+			return qf.withErr(qerrors.InvalidArgument.New(operation, "cannot calculate %s of column named column", operation))
+		}
+	}
+
+	// The values of all columns and the pairwise complete values of one pair at the time
+	if _, err := qf.checkMemory(operation, 8*(len(columns)+2)*qf.Len()); err != nil {
+		return qf.withErr(err)
+	}
+
+	values := make([][]float64, len(columns))
+	for i, col := range columns {
+		if values[i], err = qf.numericValues(operation, col); err != nil {
+			return qf.withErr(err)
+		}
+	}
+
+	result := make([][]float64, len(columns))
+	for i := range result {
+		result[i] = make([]float64, len(columns))
+	}
+
+	x, y := make([]float64, 0, qf.Len()), make([]float64, 0, qf.Len())
+	for i := range columns {
+		for j := i; j < len(columns); j++ {
+			if err := qf.checkCanceled(operation); err != nil {
+				return qf.withErr(err)
+			}
+
+			x, y = x[:0], y[:0]
+			for k := range values[i] {
+				if !math.IsNaN(values[i][k]) && !math.IsNaN(values[j][k]) {
+					x, y = append(x, values[i][k]), append(y, values[j][k])
+				}
+			}
+
+			r := math.NaN()
+			if len(x) >= config.MinPeriods {
+				r = fn(x, y)
+			}
+
+			if i == j && unitDiagonal && !math.IsNaN(r) {
+				r = 1
+			}
+			result[i][j], result[j][i] = r, r
+		}
+	}
+
+	data := make(map[string]interface{}, len(columns)+1)
+	data["column"] = columns
+	for i, col := range columns {
+		data[col] = result[i]
+	}
+
+	out := New(data, newqf.ColumnOrder(append([]string{"column"}, columns...)...))
+	out.execution = qf.execution
+	return out
+}
+
+// pearson returns the Pearson correlation coefficient of x and y, NaN if it is undefined.
+func pearson(x, y []float64) float64 {
+	if len(x) < 2 {
+		return math.NaN()
+	}
+
+	mx, my := mean(x), mean(y)
+	var sxy, sxx, syy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+
+	if sxx == 0 || syy == 0 {
+		return math.NaN()
+	}
+
+	return math.Max(-1, math.Min(1, sxy/math.Sqrt(sxx*syy)))
+}
+
+// covariance returns the sample covariance of x and y, NaN if it is undefined.
+func covariance(x, y []float64) float64 {
+	if len(x) < 2 {
+		return math.NaN()
+	}
+
+	mx, my := mean(x), mean(y)
+	var sxy float64
+	for i := range x {
+		sxy += (x[i] - mx) * (y[i] - my)
+	}
+
+	return sxy / float64(len(x)-1)
+}
+
+func mean(x []float64) float64 {
+	var sum float64
+	for _, v := range x {
+		sum += v
+	}
+	return sum / float64(len(x))
+}
+
+// rank returns the ranks, starting at 1, of the values in x. Tied values
+// get the average of the ranks that they span.
+func rank(x []float64) []float64 {
+	order := make([]int, len(x))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return x[order[i]] < x[order[j]] })
+
+	ranks := make([]float64, len(x))
+	for start := 0; start < len(order); {
+		end := start + 1
+		for end < len(order) && x[order[end]] == x[order[start]] {
+			end++
+		}
+
+		r := float64(start+end+1) / 2
+		for _, ix := range order[start:end] {
+			ranks[ix] = r
+		}
+		start = end
+	}
+	return ranks
+}
+
+// kendall returns Kendall's tau-b of x and y, NaN if it is undefined.
+func kendall(x, y []float64) float64 {
+	var concordant, discordant, tiedX, tiedY int
+	for i := range x {
+		for j := i + 1; j < len(x); j++ {
+			sx, sy := sign(x[i]-x[j]), sign(y[i]-y[j])
+			switch {
+			case sx == 0 && sy == 0:
+				tiedX++
+				tiedY++
+			case sx == 0:
+				tiedX++
+			case sy == 0:
+				tiedY++
+			case sx == sy:
+				concordant++
+			default:
+				discordant++
+			}
+		}
+	}
+
+	pairs := len(x) * (len(x) - 1) / 2
+	denominator := math.Sqrt(float64(pairs-tiedX) * float64(pairs-tiedY))
+	if denominator == 0 {
+		return math.NaN()
+	}
+
+	return float64(concordant-discordant) / denominator
+}
+
+func sign(x float64) int {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	default:
+		return 0
+	}
+}
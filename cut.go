@@ -0,0 +1,286 @@
+package qframe
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/tobgu/qframe/config/cut"
+	"github.com/tobgu/qframe/internal/ecolumn"
+	"github.com/tobgu/qframe/internal/fcolumn"
+	"github.com/tobgu/qframe/internal/icolumn"
+	"github.com/tobgu/qframe/qerrors"
+)
+
+// Cut bins the values of the numeric column srcCol into intervals and writes the bin
+// of each row to dstCol. The bins are either given explicitly using cut.Edges or
+// calculated as cut.Bins intervals of equal width spanning the values of srcCol.
+//
+// dstCol is an enum column whose values are the bin labels in bin order, sorting on it
+// hence sorts rows by bin. Values outside the bins, and null/NaN values, are binned as null.
+// Bins are closed on the right, (a, b], unless configured using cut.Left, with the lowest
+// edge included in the first bin.
+//
+// Cut is typically combined with ValueCounts to produce a histogram of the values.
+//
+// Time complexity O(n * log(m)) where n = number of rows, m = number of bins.
+func (qf QFrame) Cut(dstCol, srcCol string, configFns ...cut.ConfigFunc) QFrame {
+	return qf.cut("Cut", dstCol, srcCol, false, configFns)
+}
+
+// QCut bins the values of the numeric column srcCol into intervals based on quantiles
+// and writes the bin of each row to dstCol. The quantiles are either given explicitly
+// using cut.Edges or calculated as cut.Bins intervals containing (about) the same
+// number of values. Quantiles are interpolated linearly between the closest values.
+//
+// Quantiles that result in bins without width, which may happen when many values are
+// equal, are reported as an error. Apart from how the edges are calculated QCut works
+// like Cut.
+//
+// Time complexity O(n * log(n)) where n = number of rows.
+func (qf QFrame) QCut(dstCol, srcCol string, configFns ...cut.ConfigFunc) QFrame {
+	return qf.cut("QCut", dstCol, srcCol, true, configFns)
+}
+
+// CutEdges returns the edges of the bins that Cut would use given the same configuration.
+//
+// Time complexity O(n) where n = number of rows.
+func (qf QFrame) CutEdges(srcCol string, configFns ...cut.ConfigFunc) ([]float64, error) {
+	edges, _, _, err := qf.binEdges("CutEdges", srcCol, false, configFns)
+	return edges, err
+}
+
+// QCutEdges returns the edges of the bins that QCut would use given the same configuration.
+//
+// Time complexity O(n * log(n)) where n = number of rows.
+func (qf QFrame) QCutEdges(srcCol string, configFns ...cut.ConfigFunc) ([]float64, error) {
+	edges, _, _, err := qf.binEdges("QCutEdges", srcCol, true, configFns)
+	return edges, err
+}
+
+func (qf QFrame) cut(operation, dstCol, srcCol string, quantiles bool, configFns []cut.ConfigFunc) QFrame {
+	if qf.Err != nil {
+		return qf
+	}
+
+	edges, values, config, err := qf.binEdges(operation, srcCol, quantiles, configFns)
+	if err != nil {
+		return qf.withErr(err)
+	}
+
+	labels, err := binLabels(operation, edges, config)
+	if err != nil {
+		return qf.withErr(err)
+	}
+
+	data := make([]*string, qf.columnsByName[srcCol].Len())
+	for i, x := range values {
+		if b := binOf(edges, x, config.Left); b >= 0 {
+			data[qf.index[i]] = &labels[b]
+		}
+	}
+
+	col, err := ecolumn.New(data, labels)
+	if err != nil {
+		return qf.withErr(qerrors.Propagate(operation, err))
+	}
+
+	return qf.setColumn(dstCol, col)
+}
+
+// binEdges returns the edges of the bins together with the values of srcCol, in
+// the order of the index, and the parsed config. NaN is used for null values.
+func (qf QFrame) binEdges(operation, srcCol string, quantiles bool, configFns []cut.ConfigFunc) (edges, values []float64, config cut.Config, err error) {
+	if qf.Err != nil {
+		return nil, nil, config, qf.Err
+	}
+
+	config, err = cut.NewConfig(operation, configFns)
+	if err != nil {
+		return nil, nil, config, err
+	}
+
+	values, err = qf.numericValues(operation, srcCol)
+	if err != nil {
+		return nil, nil, config, err
+	}
+
+	if quantiles {
+		edges, err = quantileEdges(operation, values, config)
+	} else {
+		edges, err = widthEdges(operation, values, config)
+	}
+	if err != nil {
+		return nil, nil, config, err
+	}
+
+	for i := 1; i < len(edges); i++ {
+		if !(edges[i] > edges[i-1]) {
+			return nil, nil, config, qerrors.InvalidArgument.New(operation,
+				"bin edges must be strictly increasing, edge %d is %v and edge %d is %v", i-1, edges[i-1], i, edges[i])
+		}
+	}
+
+	return edges, values, config, nil
+}
+
+func (qf QFrame) numericValues(operation, colName string) ([]float64, error) {
+	col, ok := qf.columnsByName[colName]
+	if !ok {
+		return nil, unknownCol(operation, colName)
+	}
+
+	values := make([]float64, qf.Len())
+	switch c := col.Column.(type) {
+	case icolumn.Column:
+		v := c.View(qf.index)
+		for i := range values {
+			values[i] = float64(v.ItemAt(i))
+		}
+	case fcolumn.Column:
+		v := c.View(qf.index)
+		for i := range values {
+			values[i] = v.ItemAt(i)
+		}
+	default:
+		return nil, qerrors.TypeMismatch.New(operation, "column %s is of type %s, must be int or float", colName, col.DataType())
+	}
+
+	return values, nil
+}
+
+func widthEdges(operation string, values []float64, config cut.Config) ([]float64, error) {
+	if len(config.Edges) > 0 {
+		return config.Edges, nil
+	}
+
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, x := range values {
+		if !math.IsNaN(x) {
+			min, max = math.Min(min, x), math.Max(max, x)
+		}
+	}
+
+	if math.IsInf(min, 1) {
+		return nil, qerrors.InvalidArgument.New(operation, "cannot calculate bins without non null values")
+	}
+
+	if min == max {
+		// Widen the range to give the bins a width, the same adjustment as done by pandas
+		adjust := 0.001
+		if min != 0 {
+			adjust *= math.Abs(min)
+		}
+		min, max = min-adjust, max+adjust
+	}
+
+	edges := make([]float64, config.Bins+1)
+	width := (max - min) / float64(config.Bins)
+	for i := range edges {
+		edges[i] = min + float64(i)*width
+	}
+	edges[config.Bins] = max
+	return edges, nil
+}
+
+func quantileEdges(operation string, values []float64, config cut.Config) ([]float64, error) {
+	qs := config.Edges
+	if len(qs) == 0 {
+		qs = make([]float64, config.Bins+1)
+		for i := range qs {
+			qs[i] = float64(i) / float64(config.Bins)
+		}
+	}
+
+	for _, q := range qs {
+		if !(q >= 0 && q <= 1) {
+			return nil, qerrors.InvalidArgument.New(operation, "quantiles must be in the range [0, 1], was %v", q)
+		}
+	}
+
+	sorted := make([]float64, 0, len(values))
+	for _, x := range values {
+		if !math.IsNaN(x) {
+			sorted = append(sorted, x)
+		}
+	}
+
+	if len(sorted) == 0 {
+		return nil, qerrors.InvalidArgument.New(operation, "cannot calculate quantiles without non null values")
+	}
+
+	sort.Float64s(sorted)
+	edges := make([]float64, len(qs))
+	for i, q := range qs {
+		edges[i] = quantile(sorted, q)
+	}
+	return edges, nil
+}
+
+// binOf returns the position of the bin that x belongs to, -1 if none.
+func binOf(edges []float64, x float64, left bool) int {
+	last := len(edges) - 1
+	if math.IsNaN(x) || x < edges[0] || x > edges[last] {
+		return -1
+	}
+
+	if left {
+		if x == edges[last] {
+			return last - 1
+		}
+		return sort.Search(len(edges), func(i int) bool { return edges[i] > x }) - 1
+	}
+
+	if x == edges[0] {
+		return 0
+	}
+	return sort.SearchFloat64s(edges, x) - 1
+}
+
+func binLabels(operation string, edges []float64, config cut.Config) ([]string, error) {
+	count := len(edges) - 1
+	labels := config.Labels
+	if labels == nil {
+		labels = make([]string, count)
+		for i := range labels {
+			lower, upper := formatEdge(edges[i], config.Precision), formatEdge(edges[i+1], config.Precision)
+			switch {
+			case config.Left && i == count-1:
+				labels[i] = "[" + lower + ", " + upper + "]"
+			case config.Left:
+				labels[i] = "[" + lower + ", " + upper + ")"
+			case i == 0:
+				labels[i] = "[" + lower + ", " + upper + "]"
+			default:
+				labels[i] = "(" + lower + ", " + upper + "]"
+			}
+		}
+	}
+
+	if len(labels) != count {
+		return nil, qerrors.InvalidArgument.New(operation, "expected %d labels, one per bin, was %d", count, len(labels))
+	}
+
+	seen := make(map[string]struct{}, count)
+	for _, l := range labels {
+		if _, ok := seen[l]; ok {
+			return nil, qerrors.InvalidArgument.New(operation, "bin labels must be unique, %s occurs more than once", l)
+		}
+		seen[l] = struct{}{}
+	}
+
+	return labels, nil
+}
+
+func formatEdge(x float64, precision int) string {
+	p := math.Pow(10, float64(precision))
+	if rounded := math.Round(x*p) / p; !math.IsInf(rounded, 0) && !math.IsNaN(rounded) {
+		x = rounded
+	}
+
+	if x == 0 {
+		// Avoid "-0"
+		x = 0
+	}
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
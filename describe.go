@@ -0,0 +1,223 @@
+package qframe
+
+import (
+	"math"
+	"sort"
+
+	"github.com/tobgu/qframe/config/newqf"
+	"github.com/tobgu/qframe/internal/bcolumn"
+	"github.com/tobgu/qframe/internal/ecolumn"
+	"github.com/tobgu/qframe/internal/fcolumn"
+	"github.com/tobgu/qframe/internal/icolumn"
+	"github.com/tobgu/qframe/internal/scolumn"
+)
+
+// Column names of the QFrame returned by Describe, in order.
+var describeColumns = []string{
+	"column", "type", "count", "nulls", "distinct", "min", "max", "mean", "std",
+	"25%", "50%", "75%", "top", "freq", "true_ratio"}
+
+// columnSummary holds the statistics of one column. Statistics that do
+// not apply to the type of the column are NaN, nil or 0.
+type columnSummary struct {
+	count, nulls, distinct int
+	min, max, mean, std    float64
+	q25, q50, q75          float64
+	top                    *string
+	freq                   int
+	trueRatio              float64
+}
+
+func newColumnSummary() columnSummary {
+	nan := math.NaN()
+	return columnSummary{min: nan, max: nan, mean: nan, std: nan, q25: nan, q50: nan, q75: nan, trueRatio: nan}
+}
+
+// numericSummary accumulates statistics of numeric values. Mean and variance
+// are calculated using Welford's online algorithm to avoid loss of precision.
+type numericSummary struct {
+	columnSummary
+	m2     float64
+	values []float64
+	seen   map[float64]struct{}
+}
+
+func newNumericSummary(size int) *numericSummary {
+	s := &numericSummary{
+		columnSummary: newColumnSummary(),
+		values:        make([]float64, 0, size),
+		seen:          make(map[float64]struct{})}
+	s.min, s.max, s.mean = math.Inf(1), math.Inf(-1), 0
+	return s
+}
+
+func (s *numericSummary) add(x float64) {
+	s.count++
+	delta := x - s.mean
+	s.mean += delta / float64(s.count)
+	s.m2 += delta * (x - s.mean)
+	s.min = math.Min(s.min, x)
+	s.max = math.Max(s.max, x)
+	s.values = append(s.values, x)
+	s.seen[x] = struct{}{}
+}
+
+func (s *numericSummary) summary() columnSummary {
+	result := s.columnSummary
+	result.distinct = len(s.seen)
+	if s.count == 0 {
+		result.min, result.max, result.mean = math.NaN(), math.NaN(), math.NaN()
+		return result
+	}
+
+	if s.count > 1 {
+		result.std = math.Sqrt(s.m2 / float64(s.count-1))
+	}
+
+	sort.Float64s(s.values)
+	result.q25 = quantile(s.values, 0.25)
+	result.q50 = quantile(s.values, 0.5)
+	result.q75 = quantile(s.values, 0.75)
+	return result
+}
+
+// quantile returns the q quantile of the sorted values using linear
+// interpolation between the closest ranks.
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lo := int(pos)
+	if lo+1 >= len(sorted) {
+		return sorted[lo]
+	}
+
+	frac := pos - float64(lo)
+	return sorted[lo] + frac*(sorted[lo+1]-sorted[lo])
+}
+
+func describeStrings(size int, itemAt func(i int) *string) columnSummary {
+	s := newColumnSummary()
+	counts := make(map[string]int)
+	for i := 0; i < size; i++ {
+		x := itemAt(i)
+		if x == nil {
+			s.nulls++
+			continue
+		}
+
+		s.count++
+		c := counts[*x] + 1
+		counts[*x] = c
+		if c > s.freq {
+			s.top, s.freq = x, c
+		}
+	}
+
+	s.distinct = len(counts)
+	return s
+}
+
+func (qf QFrame) describeColumn(col namedColumn) columnSummary {
+	switch c := col.Column.(type) {
+	case icolumn.Column:
+		v := c.View(qf.index)
+		s := newNumericSummary(v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s.add(float64(v.ItemAt(i)))
+		}
+		return s.summary()
+	case fcolumn.Column:
+		v := c.View(qf.index)
+		s := newNumericSummary(v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if x := v.ItemAt(i); math.IsNaN(x) {
+				s.nulls++
+			} else {
+				s.add(x)
+			}
+		}
+		return s.summary()
+	case bcolumn.Column:
+		v := c.View(qf.index)
+		s := newColumnSummary()
+		trueCount := 0
+		for i := 0; i < v.Len(); i++ {
+			if v.ItemAt(i) {
+				trueCount++
+			}
+		}
+
+		s.count = v.Len()
+		if trueCount > 0 {
+			s.distinct++
+		}
+		if trueCount < s.count {
+			s.distinct++
+		}
+		if s.count > 0 {
+			s.trueRatio = float64(trueCount) / float64(s.count)
+		}
+		return s
+	case scolumn.Column:
+		v := c.View(qf.index)
+		return describeStrings(v.Len(), v.ItemAt)
+	case ecolumn.Column:
+		v := c.View(qf.index)
+		return describeStrings(v.Len(), v.ItemAt)
+	default:
+		// Null column, all values are missing
+		s := newColumnSummary()
+		s.nulls = qf.Len()
+		return s
+	}
+}
+
+// Describe returns a QFrame with summary statistics of each column in the QFrame, one row
+// per column in column order. The returned QFrame has the following columns:
+//   - column, type: Name and data type of the column.
+//   - count, nulls: Number of non null and null values.
+//   - distinct: Number of distinct non null values.
+//   - min, max, mean, std, 25%, 50%, 75%: Minimum, maximum, mean, sample standard deviation
+//     and quartiles of numeric columns, NaN for other column types. Quartiles are
+//     interpolated linearly between the closest values.
+//   - top, freq: Most frequent value and its frequency for string and enum columns. If
+//     several values are equally frequent the one first reaching that frequency is used.
+//     Null and 0 for other column types.
+//   - true_ratio: Ratio of true values in bool columns, NaN for other column types.
+//
+// NaN is considered null for float columns.
+//
+// Time complexity O(m * n * log(n)) where m = number of columns, n = number of rows. Each
+// column is traversed once, sorting the values of numeric columns to find the quartiles
+// dominates.
+func (qf QFrame) Describe() QFrame {
+	if qf.Err != nil {
+		return qf
+	}
+
+	summaries := make([]columnSummary, len(qf.columns))
+	for i, col := range qf.columns {
+		summaries[i] = qf.describeColumn(col)
+	}
+
+	n := len(qf.columns)
+	names, dataTypes := make([]string, n), make([]string, n)
+	counts, nulls, distincts, freqs := make([]int, n), make([]int, n), make([]int, n), make([]int, n)
+	mins, maxs, means, stds := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+	q25s, q50s, q75s, trueRatios := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+	tops := make([]*string, n)
+	for i, s := range summaries {
+		names[i], dataTypes[i] = qf.columns[i].name, string(qf.columns[i].DataType())
+		counts[i], nulls[i], distincts[i], freqs[i] = s.count, s.nulls, s.distinct, s.freq
+		mins[i], maxs[i], means[i], stds[i] = s.min, s.max, s.mean, s.std
+		q25s[i], q50s[i], q75s[i], trueRatios[i] = s.q25, s.q50, s.q75, s.trueRatio
+		tops[i] = s.top
+	}
+
+	result := New(map[string]interface{}{
+		"column": names, "type": dataTypes, "count": counts, "nulls": nulls, "distinct": distincts,
+		"min": mins, "max": maxs, "mean": means, "std": stds, "25%": q25s, "50%": q50s, "75%": q75s,
+		"top": tops, "freq": freqs, "true_ratio": trueRatios,
+	}, newqf.ColumnOrder(describeColumns...))
+	result.execution = qf.execution
+	return result
+}
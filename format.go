@@ -0,0 +1,686 @@
+package qframe
+
+import (
+	"database/sql"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/tobgu/qframe/config/csv"
+	"github.com/tobgu/qframe/config/fixedwidth"
+	"github.com/tobgu/qframe/config/json"
+	"github.com/tobgu/qframe/config/newqf"
+	qsql "github.com/tobgu/qframe/config/sql"
+	"github.com/tobgu/qframe/internal/column"
+	qfio "github.com/tobgu/qframe/internal/io"
+	qfsqlio "github.com/tobgu/qframe/internal/io/sql"
+	"github.com/tobgu/qframe/internal/math/integer"
+	qfstrings "github.com/tobgu/qframe/internal/strings"
+	"github.com/tobgu/qframe/qerrors"
+)
+
+// FormatOptions are the options common to all data sources and sinks.
+type FormatOptions struct {
+	// Schema, if not empty, describes the expected columns. Read coerces
+	// the QFrame returned by the source, and Write the QFrame passed to
+	// the sink, into the schema, see QFrame.Coerce. Sources may also use
+	// the schema when reading, eg. to decide the types to parse.
+	Schema Schema
+
+	// Config holds the format specific configuration, typically the
+	// configuration functions of the format, for example csv.ConfigFunc
+	// when reading CSV. Sources and sinks return an error for configuration
+	// of a type they do not support.
+	Config []interface{}
+}
+
+// FormatOption is a functional option for data sources and sinks.
+type FormatOption func(*FormatOptions)
+
+// NewFormatOptions creates new options from the option functions.
+func NewFormatOptions(ff []FormatOption) FormatOptions {
+	opts := FormatOptions{}
+	for _, f := range ff {
+		f(&opts)
+	}
+	return opts
+}
+
+// WithSchema sets the schema of the data read or written.
+func WithSchema(schema Schema) FormatOption {
+	return func(o *FormatOptions) {
+		o.Schema = schema
+	}
+}
+
+// WithConfig adds format specific configuration, see the
+// documentation of Read and Write for the built in formats.
+func WithConfig(config ...interface{}) FormatOption {
+	return func(o *FormatOptions) {
+		o.Config = append(o.Config, config...)
+	}
+}
+
+func configErr(op, format string, config interface{}) error {
+	return qerrors.InvalidArgument.New(op, "unsupported %s configuration of type %v", format, reflect.TypeOf(config))
+}
+
+// DataSource reads a QFrame from a source. The type of src
+// depends on the format, it is typically an io.Reader.
+type DataSource interface {
+	Read(src interface{}, opts FormatOptions) QFrame
+}
+
+// DataSink writes a QFrame to a destination. The type of dst
+// depends on the format, it is typically an io.Writer.
+type DataSink interface {
+	Write(qf QFrame, dst interface{}, opts FormatOptions) error
+}
+
+// DataSourceFunc is a function implementing DataSource.
+type DataSourceFunc func(src interface{}, opts FormatOptions) QFrame
+
+// Read calls fn(src, opts).
+func (fn DataSourceFunc) Read(src interface{}, opts FormatOptions) QFrame {
+	return fn(src, opts)
+}
+
+// DataSinkFunc is a function implementing DataSink.
+type DataSinkFunc func(qf QFrame, dst interface{}, opts FormatOptions) error
+
+// Write calls fn(qf, dst, opts).
+func (fn DataSinkFunc) Write(qf QFrame, dst interface{}, opts FormatOptions) error {
+	return fn(qf, dst, opts)
+}
+
+var (
+	formatsMu sync.RWMutex
+	sources   = map[string]DataSource{}
+	sinks     = map[string]DataSink{}
+)
+
+// RegisterSource makes a data source available by the provided format name.
+// If RegisterSource is called twice with the same name or if source is nil,
+// it panics.
+func RegisterSource(format string, source DataSource) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	if source == nil {
+		panic("qframe: RegisterSource source is nil")
+	}
+
+	if _, dup := sources[format]; dup {
+		panic("qframe: RegisterSource called twice for format " + format)
+	}
+	sources[format] = source
+}
+
+// RegisterSink makes a data sink available by the provided format name.
+// If RegisterSink is called twice with the same name or if sink is nil,
+// it panics.
+func RegisterSink(format string, sink DataSink) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	if sink == nil {
+		panic("qframe: RegisterSink sink is nil")
+	}
+
+	if _, dup := sinks[format]; dup {
+		panic("qframe: RegisterSink called twice for format " + format)
+	}
+	sinks[format] = sink
+}
+
+// SourceFormats returns a sorted list of the names of the registered data sources.
+func SourceFormats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	result := make([]string, 0, len(sources))
+	for name := range sources {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// SinkFormats returns a sorted list of the names of the registered data sinks.
+func SinkFormats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	result := make([]string, 0, len(sinks))
+	for name := range sinks {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Read returns a QFrame read from src using the data source registered for format.
+//
+// The built in formats, and the configuration they accept using WithConfig, are:
+//   - "csv", reading from an io.Reader, see ReadCSV. Accepts csv.ConfigFunc.
+//   - "json", reading from an io.Reader, see ReadJSONWithConfig. Accepts json.ConfigFunc.
+//   - "fixedwidth", reading from an io.Reader, see ReadFixedWidth. Accepts fixedwidth.ConfigFunc.
+//   - "binary", reading from an io.Reader, see ReadBinary.
+//   - "sql", reading from a *sql.Tx, see ReadSQLWithArgs. Accepts qsql.ConfigFunc and SQLArgs.
+func Read(format string, src interface{}, opts ...FormatOption) QFrame {
+	formatsMu.RLock()
+	source, ok := sources[format]
+	formatsMu.RUnlock()
+	if !ok {
+		return QFrame{Err: qerrors.InvalidArgument.New("Read", "unknown format %s", format)}
+	}
+
+	conf := NewFormatOptions(opts)
+	result := source.Read(src, conf)
+	if len(conf.Schema.Fields) > 0 {
+		result = result.Coerce(conf.Schema)
+	}
+	return result
+}
+
+// Write writes the QFrame to dst using the data sink registered for format.
+//
+// The built in formats, and the configuration they accept using WithConfig, are:
+//   - "csv", writing to an io.Writer, see ToCSV. Accepts csv.ToConfigFunc.
+//   - "json", writing to an io.Writer, see ToJSON. Accepts json.ToConfigFunc.
+//   - "binary", writing to an io.Writer, see ToBinary.
+//   - "sql", writing to a *sql.Tx, see ToSQL. Accepts qsql.ConfigFunc.
+func (qf QFrame) Write(format string, dst interface{}, opts ...FormatOption) error {
+	if qf.Err != nil {
+		return qerrors.Propagate("Write", qf.Err)
+	}
+
+	formatsMu.RLock()
+	sink, ok := sinks[format]
+	formatsMu.RUnlock()
+	if !ok {
+		return qerrors.InvalidArgument.New("Write", "unknown format %s", format)
+	}
+
+	conf := NewFormatOptions(opts)
+	if len(conf.Schema.Fields) > 0 {
+		qf = qf.Coerce(conf.Schema)
+		if qf.Err != nil {
+			return qerrors.Propagate("Write", qf.Err)
+		}
+	}
+
+	return sink.Write(qf, dst, conf)
+}
+
+//////////////////////////
+//// Built in formats ////
+//////////////////////////
+
+func init() {
+	RegisterSource("csv", DataSourceFunc(readCSVSource))
+	RegisterSink("csv", DataSinkFunc(writeCSVSink))
+	RegisterSource("json", DataSourceFunc(readJSONSource))
+	RegisterSink("json", DataSinkFunc(writeJSONSink))
+	RegisterSource("fixedwidth", DataSourceFunc(readFixedWidthSource))
+	RegisterSource("binary", DataSourceFunc(readBinarySource))
+	RegisterSink("binary", DataSinkFunc(writeBinarySink))
+	RegisterSource("sql", DataSourceFunc(readSQLSource))
+	RegisterSink("sql", DataSinkFunc(writeSQLSink))
+}
+
+func reader(format string, src interface{}) (io.Reader, error) {
+	r, ok := src.(io.Reader)
+	if !ok {
+		return nil, qerrors.InvalidArgument.New("Read", "%s source must be an io.Reader, was %v", format, reflect.TypeOf(src))
+	}
+	return r, nil
+}
+
+func writer(format string, dst interface{}) (io.Writer, error) {
+	w, ok := dst.(io.Writer)
+	if !ok {
+		return nil, qerrors.InvalidArgument.New("Write", "%s sink must be an io.Writer, was %v", format, reflect.TypeOf(dst))
+	}
+	return w, nil
+}
+
+func tx(op string, v interface{}) (*sql.Tx, error) {
+	t, ok := v.(*sql.Tx)
+	if !ok {
+		return nil, qerrors.InvalidArgument.New(op, "sql source and sink must be a *sql.Tx, was %v", reflect.TypeOf(v))
+	}
+	return t, nil
+}
+
+/////////////
+//// CSV ////
+/////////////
+
+func readCSVSource(src interface{}, opts FormatOptions) QFrame {
+	r, err := reader("csv", src)
+	if err != nil {
+		return QFrame{Err: err}
+	}
+
+	confFuncs := make([]csv.ConfigFunc, 0, len(opts.Config))
+	for _, c := range opts.Config {
+		fn, ok := c.(csv.ConfigFunc)
+		if !ok {
+			return QFrame{Err: configErr("Read", "csv", c)}
+		}
+		confFuncs = append(confFuncs, fn)
+	}
+
+	// The schema is only used to decide which columns to read as strings
+	// and enums here, the result is coerced into the schema by Read.
+	conf := csv.NewConfig(confFuncs)
+	opts.Schema.csvTypes(&conf)
+	return readCSV(r, conf)
+}
+
+func readCSV(reader io.Reader, conf csv.Config) QFrame {
+	data, columns, err := qfio.ReadCSV(reader, qfio.CSVConfig(conf))
+	if err != nil {
+		return QFrame{Err: err}
+	}
+
+	return New(data, newqf.ColumnOrder(columns...)).coerceSchema(conf.Schema)
+}
+
+func writeCSVSink(qf QFrame, dst interface{}, opts FormatOptions) error {
+	w, err := writer("csv", dst)
+	if err != nil {
+		return err
+	}
+
+	confFuncs := make([]csv.ToConfigFunc, 0, len(opts.Config))
+	for _, c := range opts.Config {
+		fn, ok := c.(csv.ToConfigFunc)
+		if !ok {
+			return configErr("Write", "csv", c)
+		}
+		confFuncs = append(confFuncs, fn)
+	}
+
+	return writeCSV(qf, w, csv.NewToConfig(confFuncs))
+}
+
+func writeCSV(qf QFrame, writer io.Writer, conf csv.ToConfig) error {
+	if qf.Err != nil {
+		return qerrors.Propagate("ToCSV", qf.Err)
+	}
+
+	switch conf.Delimiter {
+	case '"', '\r', '\n':
+		// The output could not be read back
+		return qerrors.InvalidArgument.New("ToCSV", "invalid delimiter %q", conf.Delimiter)
+	}
+
+	var iterCols []namedColumn
+	if conf.Columns != nil {
+		if len(conf.Columns) != len(qf.columns) {
+			return qerrors.InvalidArgument.New("ToCSV", "wrong number of columns: expected: %d", len(qf.columns))
+		}
+		iterCols = make([]namedColumn, len(qf.columns))
+		for i := range conf.Columns {
+			cName := conf.Columns[i]
+			if col, ok := qf.columnsByName[cName]; !ok {
+				return qerrors.UnknownColumn.New("ToCSV", "%s: column does not exist in QFrame", cName)
+			} else {
+				iterCols[i] = col
+			}
+		}
+	} else {
+		iterCols = qf.columns
+	}
+
+	header := make([]string, 0, len(iterCols))
+	columns := make([]column.Column, 0, len(iterCols))
+	for _, s := range iterCols {
+		header = append(header, s.name)
+		columns = append(columns, s.Column)
+	}
+
+	return qfio.WriteCSV(writer, header, columns, qf.index, qfio.ToCsvConfig(conf))
+}
+
+//////////////
+//// JSON ////
+//////////////
+
+func readJSONSource(src interface{}, opts FormatOptions) QFrame {
+	r, err := reader("json", src)
+	if err != nil {
+		return QFrame{Err: err}
+	}
+
+	confFuncs := make([]json.ConfigFunc, 0, len(opts.Config))
+	for _, c := range opts.Config {
+		fn, ok := c.(json.ConfigFunc)
+		if !ok {
+			return QFrame{Err: configErr("Read", "json", c)}
+		}
+		confFuncs = append(confFuncs, fn)
+	}
+
+	return readJSON(r, json.NewConfig(confFuncs))
+}
+
+func readJSON(reader io.Reader, conf json.Config) QFrame {
+	data, err := qfio.UnmarshalJSON(reader, qfio.JSONConfig(conf))
+	if err != nil {
+		return QFrame{Err: err}
+	}
+
+	return New(data, newqf.ColumnOrder(conf.ColumnOrder...), newqf.Enums(conf.EnumColumns)).coerceSchema(conf.Schema)
+}
+
+func writeJSONSink(qf QFrame, dst interface{}, opts FormatOptions) error {
+	w, err := writer("json", dst)
+	if err != nil {
+		return err
+	}
+
+	confFuncs := make([]json.ToConfigFunc, 0, len(opts.Config))
+	for _, c := range opts.Config {
+		fn, ok := c.(json.ToConfigFunc)
+		if !ok {
+			return configErr("Write", "json", c)
+		}
+		confFuncs = append(confFuncs, fn)
+	}
+
+	return writeJSON(qf, w, json.NewToConfig(confFuncs))
+}
+
+func writeJSON(qf QFrame, writer io.Writer, conf json.ToConfig) (err error) {
+	if qf.Err != nil {
+		return qerrors.Propagate("ToJSON", qf.Err)
+	}
+
+	cw, err := qfio.Compress(writer, conf.Compression)
+	if err != nil {
+		return qerrors.Propagate("ToJSON", err)
+	}
+	defer func() {
+		if closeErr := cw.Close(); err == nil && closeErr != nil {
+			err = qerrors.IO.Propagate("ToJSON close", closeErr)
+		}
+	}()
+	writer = cw
+
+	colByteNames := make([][]byte, len(qf.columns))
+	for i, col := range qf.columns {
+		colByteNames[i] = qfstrings.QuotedBytes(col.name)
+	}
+
+	// Custom JSON generator for records due to performance reasons
+	jsonBuf := []byte{'['}
+	_, err = writer.Write(jsonBuf)
+	if err != nil {
+		return err
+	}
+
+	for i, ix := range qf.index {
+		jsonBuf = jsonBuf[:0]
+		if i > 0 {
+			jsonBuf = append(jsonBuf, byte(','))
+		}
+
+		jsonBuf = append(jsonBuf, byte('{'))
+
+		for j, col := range qf.columns {
+			jsonBuf = append(jsonBuf, colByteNames[j]...)
+			jsonBuf = append(jsonBuf, byte(':'))
+			jsonBuf = col.AppendByteStringAt(jsonBuf, ix)
+			jsonBuf = append(jsonBuf, byte(','))
+		}
+
+		if jsonBuf[len(jsonBuf)-1] == ',' {
+			jsonBuf = jsonBuf[:len(jsonBuf)-1]
+		}
+
+		jsonBuf = append(jsonBuf, byte('}'))
+
+		_, err = writer.Write(jsonBuf)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = writer.Write([]byte{']'})
+	return err
+}
+
+/////////////////////
+//// Fixed width ////
+/////////////////////
+
+func readFixedWidthSource(src interface{}, opts FormatOptions) QFrame {
+	r, err := reader("fixedwidth", src)
+	if err != nil {
+		return QFrame{Err: err}
+	}
+
+	// The column types are part of the mandatory column configuration,
+	// columns are converted to the schema types by Read if needed.
+	confFuncs := make([]fixedwidth.ConfigFunc, 0, len(opts.Config))
+	for _, c := range opts.Config {
+		fn, ok := c.(fixedwidth.ConfigFunc)
+		if !ok {
+			return QFrame{Err: configErr("Read", "fixedwidth", c)}
+		}
+		confFuncs = append(confFuncs, fn)
+	}
+
+	return readFixedWidth(r, fixedwidth.NewConfig(confFuncs))
+}
+
+func readFixedWidth(reader io.Reader, conf fixedwidth.Config) QFrame {
+	data, columns, err := qfio.ReadFixedWidth(reader, qfio.FixedWidthConfig(conf))
+	if err != nil {
+		return QFrame{Err: err}
+	}
+
+	return New(data, newqf.ColumnOrder(columns...))
+}
+
+////////////////
+//// Binary ////
+////////////////
+
+func readBinarySource(src interface{}, opts FormatOptions) QFrame {
+	r, err := reader("binary", src)
+	if err != nil {
+		return QFrame{Err: err}
+	}
+
+	if len(opts.Config) > 0 {
+		return QFrame{Err: configErr("Read", "binary", opts.Config[0])}
+	}
+
+	return readBinary(r)
+}
+
+func readBinary(reader io.Reader) QFrame {
+	b, err := io.ReadAll(reader)
+	if err != nil {
+		return QFrame{Err: qerrors.Propagate("ReadBinary", err)}
+	}
+
+	return fromBinary(b)
+}
+
+func writeBinarySink(qf QFrame, dst interface{}, opts FormatOptions) error {
+	w, err := writer("binary", dst)
+	if err != nil {
+		return err
+	}
+
+	if len(opts.Config) > 0 {
+		return configErr("Write", "binary", opts.Config[0])
+	}
+
+	return writeBinary(qf, w)
+}
+
+func writeBinary(qf QFrame, writer io.Writer) error {
+	if qf.Err != nil {
+		return qerrors.Propagate("ToBinary", qf.Err)
+	}
+
+	names := make([]string, len(qf.columns))
+	columns := make([]column.Column, len(qf.columns))
+	for i, col := range qf.columns {
+		names[i] = col.name
+		columns[i] = col.Column
+	}
+
+	return qfio.WriteBinary(writer, names, columns, qf.index)
+}
+
+/////////////
+//// SQL ////
+/////////////
+
+// SQLArgs are the arguments of the query executed by the "sql" data source.
+type SQLArgs []interface{}
+
+func readSQLSource(src interface{}, opts FormatOptions) QFrame {
+	t, err := tx("Read", src)
+	if err != nil {
+		return QFrame{Err: err}
+	}
+
+	var args SQLArgs
+	confFuncs := make([]qsql.ConfigFunc, 0, len(opts.Config))
+	for _, c := range opts.Config {
+		switch c := c.(type) {
+		case qsql.ConfigFunc:
+			confFuncs = append(confFuncs, c)
+		case SQLArgs:
+			args = append(args, c...)
+		default:
+			return QFrame{Err: configErr("Read", "sql", c)}
+		}
+	}
+
+	return readSQL(t, args, qsql.NewConfig(confFuncs))
+}
+
+func readSQL(tx *sql.Tx, queryArgs []interface{}, conf qsql.Config) QFrame {
+	// The MySQL can only use prepared
+	// statements to return "native" types, otherwise
+	// everything is returned as text.
+	// see https://github.com/go-sql-driver/mysql/issues/407
+	stmt, err := tx.Prepare(conf.Query)
+	if err != nil {
+		return QFrame{Err: err}
+	}
+	defer stmt.Close()
+	rows, err := stmt.Query(queryArgs...)
+	if err != nil {
+		return QFrame{Err: err}
+	}
+	data, columns, err := qfsqlio.ReadSQL(rows, qfsqlio.SQLConfig(conf))
+	if err != nil {
+		return QFrame{Err: err}
+	}
+	return New(data, newqf.ColumnOrder(columns...))
+}
+
+func writeSQLSink(qf QFrame, dst interface{}, opts FormatOptions) error {
+	t, err := tx("Write", dst)
+	if err != nil {
+		return err
+	}
+
+	confFuncs := make([]qsql.ConfigFunc, 0, len(opts.Config))
+	for _, c := range opts.Config {
+		fn, ok := c.(qsql.ConfigFunc)
+		if !ok {
+			return configErr("Write", "sql", c)
+		}
+		confFuncs = append(confFuncs, fn)
+	}
+
+	return writeSQL(qf, t, qfsqlio.SQLConfig(qsql.NewConfig(confFuncs)))
+}
+
+func writeSQL(qf QFrame, tx *sql.Tx, conf qfsqlio.SQLConfig) error {
+	if qf.Err != nil {
+		return qerrors.Propagate("ToSQL", qf.Err)
+	}
+	builders := make([]qfsqlio.ArgBuilder, len(qf.columns))
+	var err error
+	for i, column := range qf.columns {
+		builders[i], err = qfsqlio.NewArgBuilder(column.Column)
+		if err != nil {
+			return qerrors.Propagate("ToSQL", err)
+		}
+	}
+
+	if err := qf.checkColumns("ToSQL", conf.ConflictColumns); err != nil {
+		return err
+	}
+
+	if err := qf.checkColumns("ToSQL", conf.UpdateColumns); err != nil {
+		return err
+	}
+
+	if conf.CreateTable {
+		ddl, err := qf.createTableSQL(conf)
+		if err != nil {
+			return qerrors.Propagate("ToSQL", err)
+		}
+
+		if _, err := tx.Exec(ddl); err != nil {
+			return qerrors.IO.Propagate("ToSQL", err)
+		}
+	}
+
+	colNames := qf.ColumnNames()
+	batchSize := qfsqlio.RowsPerInsert(len(colNames), conf)
+	var stmt *sql.Stmt
+	if qf.Len() >= batchSize {
+		stmt, err = tx.Prepare(qfsqlio.InsertBatch(colNames, batchSize, conf))
+		if err != nil {
+			return qerrors.IO.Propagate("ToSQL", err)
+		}
+		defer stmt.Close()
+	}
+
+	var keyBuilders []qfsqlio.ArgBuilder
+	if batchSize > 1 {
+		for i, column := range qf.columns {
+			if contains(conf.ConflictColumns, column.name) {
+				keyBuilders = append(keyBuilders, builders[i])
+			}
+		}
+	}
+
+	args := make([]interface{}, 0, batchSize*len(builders))
+	for start, end := 0, 0; start < qf.Len(); start = end {
+		end = integer.Min(start+batchSize, qf.Len())
+		if keyBuilders != nil {
+			end = qf.conflictFreeEnd(keyBuilders, start, end)
+		}
+
+		args = args[:0]
+		for i := start; i < end; i++ {
+			for _, b := range builders {
+				args = append(args, b(qf.index, i))
+			}
+		}
+
+		if end-start == batchSize {
+			_, err = stmt.Exec(args...)
+		} else {
+			// Last, partial, batch
+			_, err = tx.Exec(qfsqlio.InsertBatch(colNames, end-start, conf), args...)
+		}
+
+		if err != nil {
+			return qerrors.IO.Propagate("ToSQL", err)
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 module github.com/tobgu/qframe
 
 require (
+	github.com/klauspost/compress v1.17.4
 	github.com/mauricelam/genny v0.0.0-20190320071652-0800202903e5
 	gonum.org/v1/gonum v0.0.0-20180816165407-929014505bf4
 	gonum.org/v1/plot v0.0.0-20180905080458-5f3c436ce602
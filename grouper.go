@@ -4,6 +4,8 @@ import (
 	"github.com/tobgu/qframe/internal/grouper"
 	"github.com/tobgu/qframe/internal/icolumn"
 	"github.com/tobgu/qframe/internal/index"
+	"github.com/tobgu/qframe/internal/parallel"
+	qfsort "github.com/tobgu/qframe/internal/sort"
 	"github.com/tobgu/qframe/qerrors"
 	"github.com/tobgu/qframe/types"
 )
@@ -15,6 +17,8 @@ type GroupStats grouper.GroupStats
 
 // Grouper contains groups of rows produced by the QFrame.GroupBy function.
 type Grouper struct {
+	execution
+
 	indices        []index.Int
 	groupedColumns []string
 	columns        []namedColumn
@@ -69,7 +73,7 @@ func (g Grouper) Aggregate(aggs ...Aggregation) QFrame {
 	for _, agg := range aggs {
 		col, ok := g.columnsByName[agg.Column]
 		if !ok {
-			return QFrame{Err: qerrors.New("Aggregate", unknownCol(agg.Column))}
+			return QFrame{Err: unknownCol("Aggregate", agg.Column)}
 		}
 
 		newColumnName := agg.Column
@@ -80,7 +84,7 @@ func (g Grouper) Aggregate(aggs ...Aggregation) QFrame {
 
 		_, ok = newColumnsByName[newColumnName]
 		if ok {
-			return QFrame{Err: qerrors.New(
+			return QFrame{Err: qerrors.InvalidArgument.New(
 				"Aggregate",
 				"cannot aggregate on column that is part of group by or is already an aggregate: %s", newColumnName)}
 		}
@@ -95,7 +99,7 @@ func (g Grouper) Aggregate(aggs ...Aggregation) QFrame {
 
 			col.Column = icolumn.New(counts)
 		} else {
-			col.Column, err = col.Aggregate(g.indices, agg.Fn)
+			col.Column, err = col.Aggregate(g.indices, agg.Fn, g.workers())
 			if err != nil {
 				return QFrame{Err: qerrors.Propagate("Aggregate", err)}
 			}
@@ -105,7 +109,7 @@ func (g Grouper) Aggregate(aggs ...Aggregation) QFrame {
 		newColumns = append(newColumns, col)
 	}
 
-	return QFrame{columns: newColumns, columnsByName: newColumnsByName, index: index.NewAscending(uint32(len(g.indices)))}
+	return QFrame{columns: newColumns, columnsByName: newColumnsByName, index: index.NewAscending(uint32(len(g.indices))), execution: g.execution}
 }
 
 // QFrames returns a slice of QFrame where each frame represents the content of one group.
@@ -116,10 +120,51 @@ func (g Grouper) QFrames() ([]QFrame, error) {
 		return nil, g.Err
 	}
 
-	baseFrame := QFrame{columns: g.columns, columnsByName: g.columnsByName, index: index.Int{}}
+	baseFrame := QFrame{columns: g.columns, columnsByName: g.columnsByName, index: index.Int{}, execution: g.execution}
 	result := make([]QFrame, len(g.indices))
 	for i, ix := range g.indices {
 		result[i] = baseFrame.withIndex(ix)
 	}
 	return result, nil
 }
+
+// TopN returns a QFrame with the first n rows of each group if the group was sorted according
+// to the orders specified, see QFrame.TopN. The groups follow each other in group order.
+//
+// Time complexity O(m * n * log(k)) where m = number of columns to sort by, n = number of rows,
+// k = number of rows to return per group.
+func (g Grouper) TopN(n int, orders ...Order) QFrame {
+	if g.Err != nil {
+		return QFrame{Err: g.Err}
+	}
+
+	if n < 0 {
+		return QFrame{Err: qerrors.InvalidArgument.New("TopN", "n must be non negative, was %d", n)}
+	}
+
+	baseFrame := QFrame{columns: g.columns, columnsByName: g.columnsByName, index: index.Int{}, execution: g.execution}
+	comparables, err := baseFrame.orderComparables("TopN", orders)
+	if err != nil {
+		return QFrame{Err: err}
+	}
+
+	groupTops := make([]index.Int, len(g.indices))
+	_ = parallel.ForSize(len(g.indices), g.workers(), 1, func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			groupTops[i] = qfsort.TopN(g.indices[i], comparables, n)
+		}
+		return nil
+	})
+
+	size := 0
+	for _, ix := range groupTops {
+		size += len(ix)
+	}
+
+	newIx := make(index.Int, 0, size)
+	for _, ix := range groupTops {
+		newIx = append(newIx, ix...)
+	}
+
+	return baseFrame.withIndex(newIx)
+}
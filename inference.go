@@ -0,0 +1,261 @@
+package qframe
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/tobgu/qframe/config/csv"
+	"github.com/tobgu/qframe/internal/ecolumn"
+	qfio "github.com/tobgu/qframe/internal/io"
+	qfstrings "github.com/tobgu/qframe/internal/strings"
+	"github.com/tobgu/qframe/qerrors"
+	"github.com/tobgu/qframe/types"
+)
+
+// TypeCandidate describes whether the values of a column can be read as a certain type.
+type TypeCandidate struct {
+	Type types.DataType
+
+	// Fits is set if all values of the column can be read as Type.
+	Fits bool
+
+	// Row is the row number of the first value that cannot be read as Type, -1 if Fits.
+	Row int
+
+	// Line is the line number, in the input, of the first value that cannot be
+	// read as Type. Only set for CSV input, 0 otherwise.
+	Line int
+
+	// Value is the first value that cannot be read as Type.
+	Value string
+}
+
+// ColumnInference holds the result of the type inference for a column.
+type ColumnInference struct {
+	Name string
+
+	// Type is the type that the column would be read as, types.None if
+	// the column cannot be read at all and types.Undefined if the input
+	// contains no rows.
+	Type types.DataType
+
+	// Candidates lists all types considered, in the order tried.
+	Candidates []TypeCandidate
+
+	// RowCount is the number of rows.
+	RowCount int
+
+	// NullCount is the number of missing values. For CSV input this is
+	// the number of empty fields.
+	NullCount int
+
+	// DistinctCount is the number of distinct non missing values. Counting
+	// stops at the enum max cardinality + 1.
+	DistinctCount int
+
+	// EnumFit is set if the column would fit in an enum column.
+	EnumFit bool
+}
+
+// InferenceReport holds the result of type inference for all columns of an input.
+type InferenceReport struct {
+	Columns []ColumnInference
+}
+
+// Column returns the inference of the named column and true if it exists.
+func (r InferenceReport) Column(name string) (ColumnInference, bool) {
+	for _, c := range r.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return ColumnInference{}, false
+}
+
+// String returns a human readable summary of the report.
+func (r InferenceReport) String() string {
+	buf := new(bytes.Buffer)
+	for _, c := range r.Columns {
+		fmt.Fprintf(buf, "%s: %s, rows=%d, nulls=%d, distinct=%d, enum=%t\n",
+			c.Name, c.Type, c.RowCount, c.NullCount, c.DistinctCount, c.EnumFit)
+		for _, candidate := range c.Candidates {
+			if !candidate.Fits {
+				fmt.Fprintf(buf, "  not %s: row %d", candidate.Type, candidate.Row)
+				if candidate.Line > 0 {
+					fmt.Fprintf(buf, " (line %d)", candidate.Line)
+				}
+				fmt.Fprintf(buf, ", value %q\n", candidate.Value)
+			}
+		}
+	}
+	return buf.String()
+}
+
+// distinctCounter counts distinct values up to the enum max cardinality + 1.
+type distinctCounter map[string]struct{}
+
+func (d distinctCounter) add(s string) {
+	if len(d) <= ecolumn.MaxCardinality {
+		d[s] = struct{}{}
+	}
+}
+
+func (c *ColumnInference) finish(candidates []TypeCandidate, distinct distinctCounter) {
+	c.Candidates = candidates
+	c.DistinctCount = len(distinct)
+	c.EnumFit = c.DistinctCount <= ecolumn.MaxCardinality
+	c.Type = types.None
+	if c.RowCount == 0 {
+		c.Type = types.Undefined
+		return
+	}
+
+	for _, candidate := range candidates {
+		if candidate.Fits {
+			c.Type = candidate.Type
+			return
+		}
+	}
+}
+
+func newCandidates(typs ...types.DataType) []TypeCandidate {
+	result := make([]TypeCandidate, len(typs))
+	for i, t := range typs {
+		result[i] = TypeCandidate{Type: t, Fits: true, Row: -1}
+	}
+	return result
+}
+
+func (t *TypeCandidate) reject(row, line int, value string) {
+	if t.Fits {
+		t.Fits, t.Row, t.Line, t.Value = false, row, line, value
+	}
+}
+
+// InferCSV reads CSV data from reader and reports, per column, which types the
+// column could be read as, why other types were rejected, the number of missing
+// values and whether the column would fit in an enum column. The same configuration
+// as for ReadCSV can be used, only the options affecting the parsing of the CSV
+// are considered though.
+//
+// Time complexity O(m * n) where m = number of columns, n = number of rows.
+func InferCSV(reader io.Reader, confFuncs ...csv.ConfigFunc) (InferenceReport, error) {
+	conf := csv.NewConfig(confFuncs)
+	fields, err := qfio.ReadCSVFields(reader, qfio.CSVConfig(conf))
+	if err != nil {
+		return InferenceReport{}, qerrors.Propagate("InferCSV", err)
+	}
+
+	report := InferenceReport{Columns: make([]ColumnInference, len(fields.Headers))}
+	for col, name := range fields.Headers {
+		c := ColumnInference{Name: name, RowCount: fields.RowCount()}
+		// Same detection as when reading the CSV
+		candidates := newCandidates(qfio.CSVDetectTypes...)
+		distinct := distinctCounter{}
+		for row := 0; row < c.RowCount; row++ {
+			b := fields.Field(col, row)
+			line := fields.Lines[row]
+			for i := range candidates {
+				if candidates[i].Fits && !qfio.CSVFieldFits(candidates[i].Type, b) {
+					candidates[i].reject(row, line, string(b))
+				}
+			}
+
+			if len(b) == 0 {
+				c.NullCount++
+				continue
+			}
+
+			if _, ok := distinct[qfstrings.UnsafeBytesToString(b)]; !ok {
+				distinct.add(string(b))
+			}
+		}
+
+		c.finish(candidates, distinct)
+		report.Columns[col] = c
+	}
+
+	return report, nil
+}
+
+// InferJSON reads record oriented JSON data from reader and reports, per column,
+// which types the column could be read as, why other types were rejected, the number
+// of missing values and whether the column would fit in an enum column.
+//
+// Note that all JSON numbers are read as floats by ReadJSON.
+//
+// Time complexity O(m * n) where m = number of columns, n = number of rows.
+func InferJSON(reader io.Reader) (InferenceReport, error) {
+	records, err := qfio.ReadJSONRecords(reader)
+	if err != nil {
+		return InferenceReport{}, qerrors.Propagate("InferJSON", err)
+	}
+
+	// Columns in order of first appearance, keys of the
+	// same record are sorted since records are maps.
+	var names []string
+	seen := map[string]bool{}
+	for _, record := range records {
+		var recordNames []string
+		for name := range record {
+			if !seen[name] {
+				seen[name] = true
+				recordNames = append(recordNames, name)
+			}
+		}
+		sort.Strings(recordNames)
+		names = append(names, recordNames...)
+	}
+
+	report := InferenceReport{Columns: make([]ColumnInference, len(names))}
+	for i, name := range names {
+		c := ColumnInference{Name: name, RowCount: len(records)}
+		candidates := newCandidates(types.Float, types.Bool, types.String)
+		floatC, boolC, stringC := &candidates[0], &candidates[1], &candidates[2]
+		distinct := distinctCounter{}
+		for row, record := range records {
+			value, ok := record[name]
+			if !ok {
+				for j := range candidates {
+					candidates[j].reject(row, 0, "<missing>")
+				}
+				continue
+			}
+
+			switch v := value.(type) {
+			case nil:
+				c.NullCount++
+				floatC.reject(row, 0, "null")
+				boolC.reject(row, 0, "null")
+			case float64:
+				boolC.reject(row, 0, fmt.Sprint(v))
+				stringC.reject(row, 0, fmt.Sprint(v))
+				if !math.IsNaN(v) {
+					distinct.add(strconv.FormatFloat(v, 'g', -1, 64))
+				}
+			case bool:
+				floatC.reject(row, 0, strconv.FormatBool(v))
+				stringC.reject(row, 0, strconv.FormatBool(v))
+				distinct.add(strconv.FormatBool(v))
+			case string:
+				floatC.reject(row, 0, v)
+				boolC.reject(row, 0, v)
+				distinct.add(v)
+			default:
+				s := fmt.Sprint(v)
+				for j := range candidates {
+					candidates[j].reject(row, 0, s)
+				}
+			}
+		}
+
+		c.finish(candidates, distinct)
+		report.Columns[i] = c
+	}
+
+	return report, nil
+}
@@ -70,17 +70,17 @@ func (c Column) filterBuiltIn(index index.Int, comparator string, comparatee int
 	case bool:
 		compFunc, ok := filterFuncs[comparator]
 		if !ok {
-			return qerrors.New("filter bool", "invalid comparison operator for bool, %v", comparator)
+			return qerrors.InvalidArgument.New("filter bool", "invalid comparison operator for bool, %v", comparator)
 		}
 		compFunc(index, c.data, t, bIndex)
 	case Column:
 		compFunc, ok := filterFuncs2[comparator]
 		if !ok {
-			return qerrors.New("filter bool", "invalid comparison operator for bool, %v", comparator)
+			return qerrors.InvalidArgument.New("filter bool", "invalid comparison operator for bool, %v", comparator)
 		}
 		compFunc(index, c.data, t.data, bIndex)
 	default:
-		return qerrors.New("filter bool", "invalid comparison value type %v", reflect.TypeOf(comparatee))
+		return qerrors.TypeMismatch.New("filter bool", "invalid comparison value type %v", reflect.TypeOf(comparatee))
 	}
 	return nil
 }
@@ -96,7 +96,7 @@ func (c Column) filterCustom1(index index.Int, fn func(bool) bool, bIndex index.
 func (c Column) filterCustom2(index index.Int, fn func(bool, bool) bool, comparatee interface{}, bIndex index.Bool) error {
 	otherC, ok := comparatee.(Column)
 	if !ok {
-		return qerrors.New("filter bool", "expected comparatee to be bool column, was %v", reflect.TypeOf(comparatee))
+		return qerrors.TypeMismatch.New("filter bool", "expected comparatee to be bool column, was %v", reflect.TypeOf(comparatee))
 	}
 
 	for i, x := range bIndex {
@@ -118,7 +118,7 @@ func (c Column) Filter(index index.Int, comparator interface{}, comparatee inter
 	case func(bool, bool) bool:
 		err = c.filterCustom2(index, t, comparatee, bIndex)
 	default:
-		err = qerrors.New("filter bool", "invalid filter type %v", reflect.TypeOf(comparator))
+		err = qerrors.TypeMismatch.New("filter bool", "invalid filter type %v", reflect.TypeOf(comparator))
 	}
 	return err
 }
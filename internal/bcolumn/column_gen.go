@@ -14,6 +14,7 @@ import (
 
 	"github.com/tobgu/qframe/internal/column"
 	"github.com/tobgu/qframe/internal/index"
+	"github.com/tobgu/qframe/internal/parallel"
 	"github.com/tobgu/qframe/qerrors"
 )
 
@@ -43,54 +44,69 @@ func (c Column) fnName(name string) string {
 
 // Apply single argument function. The result may be a column
 // of a different type than the current column.
-func (c Column) Apply1(fn interface{}, ix index.Int) (interface{}, error) {
+func (c Column) Apply1(fn interface{}, ix index.Int, workers int) (interface{}, error) {
 	switch t := fn.(type) {
 	case func(bool) int:
 		result := make([]int, len(c.data))
-		for _, i := range ix {
-			result[i] = t(c.data[i])
-		}
+		_ = parallel.For(len(ix), workers, func(lo, hi int) error {
+			for _, i := range ix[lo:hi] {
+				result[i] = t(c.data[i])
+			}
+			return nil
+		})
 		return result, nil
 	case func(bool) float64:
 		result := make([]float64, len(c.data))
-		for _, i := range ix {
-			result[i] = t(c.data[i])
-		}
+		_ = parallel.For(len(ix), workers, func(lo, hi int) error {
+			for _, i := range ix[lo:hi] {
+				result[i] = t(c.data[i])
+			}
+			return nil
+		})
 		return result, nil
 	case func(bool) bool:
 		result := make([]bool, len(c.data))
-		for _, i := range ix {
-			result[i] = t(c.data[i])
-		}
+		_ = parallel.For(len(ix), workers, func(lo, hi int) error {
+			for _, i := range ix[lo:hi] {
+				result[i] = t(c.data[i])
+			}
+			return nil
+		})
 		return result, nil
 	case func(bool) *string:
 		result := make([]*string, len(c.data))
-		for _, i := range ix {
-			result[i] = t(c.data[i])
-		}
+		_ = parallel.For(len(ix), workers, func(lo, hi int) error {
+			for _, i := range ix[lo:hi] {
+				result[i] = t(c.data[i])
+			}
+			return nil
+		})
 		return result, nil
 	default:
-		return nil, qerrors.New(c.fnName("Apply1"), "cannot apply type %#v to column", fn)
+		return nil, qerrors.TypeMismatch.New(c.fnName("Apply1"), "cannot apply type %#v to column", fn)
 	}
 }
 
 // Apply double argument function to two columns. Both columns must have the
 // same type. The resulting column will have the same type as this column.
-func (c Column) Apply2(fn interface{}, s2 column.Column, ix index.Int) (column.Column, error) {
+func (c Column) Apply2(fn interface{}, s2 column.Column, ix index.Int, workers int) (column.Column, error) {
 	ss2, ok := s2.(Column)
 	if !ok {
-		return Column{}, qerrors.New(c.fnName("Apply2"), "invalid column type: %s", s2.DataType())
+		return Column{}, qerrors.TypeMismatch.New(c.fnName("Apply2"), "invalid column type: %s", s2.DataType())
 	}
 
 	t, ok := fn.(func(bool, bool) bool)
 	if !ok {
-		return Column{}, qerrors.New("Apply2", "invalid function type: %#v", fn)
+		return Column{}, qerrors.TypeMismatch.New("Apply2", "invalid function type: %#v", fn)
 	}
 
 	result := make([]bool, len(c.data))
-	for _, i := range ix {
-		result[i] = t(c.data[i], ss2.data[i])
-	}
+	_ = parallel.For(len(ix), workers, func(lo, hi int) error {
+		for _, i := range ix[lo:hi] {
+			result[i] = t(c.data[i], ss2.data[i])
+		}
+		return nil
+	})
 
 	return New(result), nil
 }
@@ -134,7 +150,7 @@ func (c Column) Len() int {
 	return len(c.data)
 }
 
-func (c Column) Aggregate(indices []index.Int, fn interface{}) (column.Column, error) {
+func (c Column) Aggregate(indices []index.Int, fn interface{}, workers int) (column.Column, error) {
 	var actualFn func([]bool) bool
 	var ok bool
 
@@ -142,20 +158,23 @@ func (c Column) Aggregate(indices []index.Int, fn interface{}) (column.Column, e
 	case string:
 		actualFn, ok = aggregations[t]
 		if !ok {
-			return nil, qerrors.New(c.fnName("Aggregate"), "aggregation function %c is not defined for column", fn)
+			return nil, qerrors.InvalidArgument.New(c.fnName("Aggregate"), "aggregation function %c is not defined for column", fn)
 		}
 	case func([]bool) bool:
 		actualFn = t
 	default:
-		return nil, qerrors.New(c.fnName("Aggregate"), "invalid aggregation function type: %v", t)
+		return nil, qerrors.TypeMismatch.New(c.fnName("Aggregate"), "invalid aggregation function type: %v", t)
 	}
 
-	data := make([]bool, 0, len(indices))
-	var buf []bool
-	for _, ix := range indices {
-		subS := c.subsetWithBuf(ix, &buf)
-		data = append(data, actualFn(subS.data))
-	}
+	data := make([]bool, len(indices))
+	_ = parallel.ForSize(len(indices), workers, 1, func(lo, hi int) error {
+		var buf []bool
+		for i, ix := range indices[lo:hi] {
+			subS := c.subsetWithBuf(ix, &buf)
+			data[lo+i] = actualFn(subS.data)
+		}
+		return nil
+	})
 
 	return Column{data: data}, nil
 }
@@ -177,6 +196,12 @@ func (c Column) View(ix index.Int) View {
 	return View{data: c.data, index: ix}
 }
 
+// Data returns the slice holding the column data. The slice is shared
+// with the column and must not be modified.
+func (c Column) Data() []bool {
+	return c.data
+}
+
 func (c Column) Rolling(fn interface{}, ix index.Int, config rolling.Config) (column.Column, error) {
 	return c, nil
 }
@@ -0,0 +1,18 @@
+// Package cancel contains helpers to abort long running loops when the
+// context of an operation is done.
+package cancel
+
+import "context"
+
+// Interval is the number of items that loops process between checks
+// of the context. Checking is cheap but not free.
+const Interval = 1 << 14
+
+// Err returns the error of ctx if it is done, otherwise nil.
+// A nil ctx is never done.
+func Err(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+	return ctx.Err()
+}
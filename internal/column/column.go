@@ -3,6 +3,7 @@ package column
 import (
 	"fmt"
 	"github.com/tobgu/qframe/config/rolling"
+	"math"
 
 	"github.com/tobgu/qframe/internal/index"
 	"github.com/tobgu/qframe/types"
@@ -15,14 +16,14 @@ type Column interface {
 	Append(cols ...Column) (Column, error)
 	Equals(index index.Int, other Column, otherIndex index.Int) bool
 	Comparable(reverse, equalNull, nullLast bool) Comparable
-	Aggregate(indices []index.Int, fn interface{}) (Column, error)
+	Aggregate(indices []index.Int, fn interface{}, workers int) (Column, error)
 	StringAt(i uint32, naRep string) string
 	AppendByteStringAt(buf []byte, i uint32) []byte
 	ByteSize() int
 	Len() int
 
-	Apply1(fn interface{}, ix index.Int) (interface{}, error)
-	Apply2(fn interface{}, s2 Column, ix index.Int) (Column, error)
+	Apply1(fn interface{}, ix index.Int, workers int) (interface{}, error)
+	Apply2(fn interface{}, s2 Column, ix index.Int, workers int) (Column, error)
 
 	Rolling(fn interface{}, ix index.Int, config rolling.Config) (Column, error)
 
@@ -45,3 +46,29 @@ type Comparable interface {
 	Compare(i, j uint32) CompareResult
 	Hash(i uint32, seed uint64) uint64
 }
+
+// SortKeyer is implemented by columns whose values can be mapped to unsigned
+// integers with the same ordering. This allows them to be sorted using radix sort.
+type SortKeyer interface {
+	// SortKeys returns the keys of the elements in ix, in ix order. The order of
+	// the keys matches that of a Comparable created with the same arguments.
+	SortKeys(ix index.Int, reverse, nullLast bool) []uint64
+}
+
+// SortKey returns key, inverted if reverse. This reverses the order of keys.
+func SortKey(key uint64, reverse bool) uint64 {
+	if reverse {
+		return ^key
+	}
+	return key
+}
+
+// NullSortKey returns the key of null values. Nulls are ordered before all
+// other values, after if nullLast. Note that nulls end up last if reversed
+// and first if reversed with nullLast, matching the Comparables.
+func NullSortKey(reverse, nullLast bool) uint64 {
+	if nullLast {
+		return SortKey(math.MaxUint64, reverse)
+	}
+	return SortKey(0, reverse)
+}
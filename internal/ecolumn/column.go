@@ -5,11 +5,13 @@ import (
 	"github.com/tobgu/qframe/config/rolling"
 	"reflect"
 	"strings"
+	"unsafe"
 
 	"github.com/tobgu/qframe/filter"
 	"github.com/tobgu/qframe/internal/column"
 	"github.com/tobgu/qframe/internal/hash"
 	"github.com/tobgu/qframe/internal/index"
+	"github.com/tobgu/qframe/internal/parallel"
 	"github.com/tobgu/qframe/internal/scolumn"
 	qfstrings "github.com/tobgu/qframe/internal/strings"
 	"github.com/tobgu/qframe/qerrors"
@@ -19,6 +21,9 @@ import (
 type enumVal uint8
 
 const maxCardinality = 255
+
+// MaxCardinality is the maximum number of distinct values in an enum column.
+const MaxCardinality = maxCardinality
 const nullValue = maxCardinality
 
 func (v enumVal) isNull() bool {
@@ -88,7 +93,7 @@ func NewConst(val *string, count int, values []string) (Column, error) {
 
 func NewFactory(values []string, sizeHint int) (*Factory, error) {
 	if len(values) > maxCardinality {
-		return nil, qerrors.New("New enum", "too many unique values, max cardinality is %d", maxCardinality)
+		return nil, qerrors.InvalidArgument.New("New enum", "too many unique values, max cardinality is %d", maxCardinality)
 	}
 
 	if values == nil {
@@ -149,11 +154,11 @@ func (f *Factory) enumVal(s *string) (enumVal, error) {
 	}
 
 	if f.column.strict {
-		return 0, qerrors.New("enum val", `unknown enum value "%s" using strict enum`, *s)
+		return 0, qerrors.InvalidArgument.New("enum val", `unknown enum value "%s" using strict enum`, *s)
 	}
 
 	if len(f.column.values) >= maxCardinality {
-		return 0, qerrors.New("enum val", `enum max cardinality (%d) exceeded`, maxCardinality)
+		return 0, qerrors.InvalidArgument.New("enum val", `enum max cardinality (%d) exceeded`, maxCardinality)
 	}
 
 	return f.newEnumVal(*s), nil
@@ -161,11 +166,11 @@ func (f *Factory) enumVal(s *string) (enumVal, error) {
 
 func (f *Factory) appendString(str string) error {
 	if f.column.strict {
-		return qerrors.New("append enum val", `unknown enum value "%s" using strict enum`, str)
+		return qerrors.InvalidArgument.New("append enum val", `unknown enum value "%s" using strict enum`, str)
 	}
 
 	if len(f.column.values) >= maxCardinality {
-		return qerrors.New("append enum val", `enum max cardinality (%d) exceeded`, maxCardinality)
+		return qerrors.InvalidArgument.New("append enum val", `enum max cardinality (%d) exceeded`, maxCardinality)
 	}
 
 	ev := f.newEnumVal(str)
@@ -173,6 +178,30 @@ func (f *Factory) appendString(str string) error {
 	return nil
 }
 
+// NewRaw creates a new column from its internal representation, one byte per
+// element identifying the value with null represented by 255. data is used
+// as is without copying.
+func NewRaw(data []byte, values []string, strict bool) (Column, error) {
+	if len(values) > maxCardinality {
+		return Column{}, qerrors.InvalidArgument.New("NewRaw", "too many unique values, max cardinality is %d", maxCardinality)
+	}
+
+	eData := unsafe.Slice((*enumVal)(unsafe.SliceData(data)), len(data))
+	for _, v := range eData {
+		if !v.isNull() && int(v) >= len(values) {
+			return Column{}, qerrors.InvalidArgument.New("NewRaw", "invalid enum value: %d", v)
+		}
+	}
+
+	return Column{data: eData, values: values, strict: strict}, nil
+}
+
+// Raw returns the internal representation of the column, see NewRaw.
+// The data slice is shared with the column and must not be modified.
+func (c Column) Raw() (data []byte, values []string, strict bool) {
+	return unsafe.Slice((*byte)(unsafe.SliceData(c.data)), len(c.data)), c.values, c.strict
+}
+
 func (f *Factory) ToColumn() Column {
 	// Using the factory after this method has been called and the column exposed
 	// is not recommended.
@@ -318,7 +347,7 @@ func (c Column) filterBuiltIn(index index.Int, comparator string, comparatee int
 			}
 
 			if c.strict {
-				return qerrors.New("filter enum", "Unknown enum value in filter argument: %s", comp)
+				return qerrors.InvalidArgument.New("filter enum", "Unknown enum value in filter argument: %s", comp)
 			}
 
 			// If no enum values have been explicitly defined we quietly accept the comparator
@@ -344,7 +373,7 @@ func (c Column) filterBuiltIn(index index.Int, comparator string, comparatee int
 			return nil
 		}
 
-		return qerrors.New("filter enum", "unknown comparison operator for single argument comparison, %v", comparator)
+		return qerrors.InvalidArgument.New("filter enum", "unknown comparison operator for single argument comparison, %v", comparator)
 	case []string:
 		if multiFunc, ok := multiInputFilterFuncs[comparator]; ok {
 			bset := multiFunc(qfstrings.NewStringSet(comp), c.values)
@@ -352,15 +381,15 @@ func (c Column) filterBuiltIn(index index.Int, comparator string, comparatee int
 			return nil
 		}
 
-		return qerrors.New("filter enum", "unknown comparison operator for multi argument comparison, %v", comparator)
+		return qerrors.InvalidArgument.New("filter enum", "unknown comparison operator for multi argument comparison, %v", comparator)
 	case Column:
 		if ok := equalTypes(c, comp); !ok {
-			return qerrors.New("filter enum", "cannot compare enums of different types")
+			return qerrors.TypeMismatch.New("filter enum", "cannot compare enums of different types")
 		}
 
 		compFunc, ok := filterFuncs2[comparator]
 		if !ok {
-			return qerrors.New("filter enum", "unknown comparison operator for column - column comparison, %v", comparator)
+			return qerrors.InvalidArgument.New("filter enum", "unknown comparison operator for column - column comparison, %v", comparator)
 		}
 
 		compFunc(index, c.data, comp.data, bIndex)
@@ -368,12 +397,12 @@ func (c Column) filterBuiltIn(index index.Int, comparator string, comparatee int
 	case nil:
 		compFunc, ok := filterFuncs0[comparator]
 		if !ok {
-			return qerrors.New("filter enum", "unknown comparison operator for zero argument comparison, %v", comparator)
+			return qerrors.InvalidArgument.New("filter enum", "unknown comparison operator for zero argument comparison, %v", comparator)
 		}
 		compFunc(index, c.data, bIndex)
 		return nil
 	default:
-		return qerrors.New("filter enum", "invalid comparison type, %v, expected string or other enum column", reflect.TypeOf(comparatee))
+		return qerrors.TypeMismatch.New("filter enum", "invalid comparison type, %v, expected string or other enum column", reflect.TypeOf(comparatee))
 	}
 }
 
@@ -388,7 +417,7 @@ func (c Column) filterCustom1(index index.Int, fn func(*string) bool, bIndex ind
 func (c Column) filterCustom2(index index.Int, fn func(*string, *string) bool, comparatee interface{}, bIndex index.Bool) error {
 	otherC, ok := comparatee.(Column)
 	if !ok {
-		return qerrors.New("filter string", "expected comparatee to be string column, was %v", reflect.TypeOf(comparatee))
+		return qerrors.TypeMismatch.New("filter string", "expected comparatee to be string column, was %v", reflect.TypeOf(comparatee))
 	}
 
 	for i, x := range bIndex {
@@ -410,7 +439,7 @@ func (c Column) Filter(index index.Int, comparator interface{}, comparatee inter
 	case func(*string, *string) bool:
 		err = c.filterCustom2(index, t, comparatee, bIndex)
 	default:
-		err = qerrors.New("filter string", "invalid filter type %v", reflect.TypeOf(comparator))
+		err = qerrors.TypeMismatch.New("filter string", "invalid filter type %v", reflect.TypeOf(comparator))
 	}
 	return err
 }
@@ -473,20 +502,23 @@ func (c Column) String() string {
 	return fmt.Sprintf("%v", strs)
 }
 
-func (c Column) Aggregate(indices []index.Int, fn interface{}) (column.Column, error) {
+func (c Column) Aggregate(indices []index.Int, fn interface{}, workers int) (column.Column, error) {
 	// NB! The result of aggregating over an enum column is a string column
 	switch t := fn.(type) {
 	case string:
 		// There are currently no build in aggregations for enums
-		return nil, qerrors.New("enum aggregate", "aggregation function %v is not defined for enum column", fn)
+		return nil, qerrors.InvalidArgument.New("enum aggregate", "aggregation function %v is not defined for enum column", fn)
 	case func([]*string) *string:
-		data := make([]*string, 0, len(indices))
-		for _, ix := range indices {
-			data = append(data, t(c.stringSlice(ix)))
-		}
+		data := make([]*string, len(indices))
+		_ = parallel.ForSize(len(indices), workers, 1, func(lo, hi int) error {
+			for i, ix := range indices[lo:hi] {
+				data[lo+i] = t(c.stringSlice(ix))
+			}
+			return nil
+		})
 		return scolumn.New(data), nil
 	default:
-		return nil, qerrors.New("enum aggregate", "invalid aggregation function type: %v", t)
+		return nil, qerrors.TypeMismatch.New("enum aggregate", "invalid aggregation function type: %v", t)
 	}
 }
 
@@ -497,7 +529,7 @@ func (c Column) stringPtrAt(i uint32) *string {
 	return &c.values[c.data[i]]
 }
 
-func (c Column) Apply1(fn interface{}, ix index.Int) (interface{}, error) {
+func (c Column) Apply1(fn interface{}, ix index.Int, workers int) (interface{}, error) {
 	/*
 		Interesting optimisations could be applied here given that:
 		- The passed in function always returns the same value given the same input
@@ -508,50 +540,65 @@ func (c Column) Apply1(fn interface{}, ix index.Int) (interface{}, error) {
 	switch t := fn.(type) {
 	case func(*string) int:
 		result := make([]int, len(c.data))
-		for _, i := range ix {
-			result[i] = t(c.stringPtrAt(i))
-		}
+		_ = parallel.For(len(ix), workers, func(lo, hi int) error {
+			for _, i := range ix[lo:hi] {
+				result[i] = t(c.stringPtrAt(i))
+			}
+			return nil
+		})
 		return result, nil
 	case func(*string) float64:
 		result := make([]float64, len(c.data))
-		for _, i := range ix {
-			result[i] = t(c.stringPtrAt(i))
-		}
+		_ = parallel.For(len(ix), workers, func(lo, hi int) error {
+			for _, i := range ix[lo:hi] {
+				result[i] = t(c.stringPtrAt(i))
+			}
+			return nil
+		})
 		return result, nil
 	case func(*string) bool:
 		result := make([]bool, len(c.data))
-		for _, i := range ix {
-			result[i] = t(c.stringPtrAt(i))
-		}
+		_ = parallel.For(len(ix), workers, func(lo, hi int) error {
+			for _, i := range ix[lo:hi] {
+				result[i] = t(c.stringPtrAt(i))
+			}
+			return nil
+		})
 		return result, nil
 	case func(*string) *string:
 		result := make([]*string, len(c.data))
-		for _, i := range ix {
-			result[i] = t(c.stringPtrAt(i))
-		}
+		_ = parallel.For(len(ix), workers, func(lo, hi int) error {
+			for _, i := range ix[lo:hi] {
+				result[i] = t(c.stringPtrAt(i))
+			}
+			return nil
+		})
 		return result, nil
 	case string:
 		if f, ok := enumApplyFuncs[t]; ok {
 			return f(ix, c), nil
 		}
-		return nil, qerrors.New("string.apply1", "unknown built in function %s", t)
+		return nil, qerrors.InvalidArgument.New("string.apply1", "unknown built in function %s", t)
 	default:
-		return nil, qerrors.New("enum.apply1", "cannot apply type %#v to column", fn)
+		return nil, qerrors.TypeMismatch.New("enum.apply1", "cannot apply type %#v to column", fn)
 	}
 }
 
-func (c Column) Apply2(fn interface{}, s2 column.Column, ix index.Int) (column.Column, error) {
+func (c Column) Apply2(fn interface{}, s2 column.Column, ix index.Int, workers int) (column.Column, error) {
 	s2S, ok := s2.(Column)
 	if !ok {
-		return nil, qerrors.New("enum.apply2", "invalid column type %s", s2.DataType())
+		return nil, qerrors.TypeMismatch.New("enum.apply2", "invalid column type %s", s2.DataType())
 	}
 
 	switch t := fn.(type) {
 	case func(*string, *string) *string:
 		result := make([]*string, len(c.data))
-		for _, i := range ix {
-			result[i] = t(c.stringPtrAt(i), s2S.stringPtrAt(i))
-		}
+		_ = parallel.For(len(ix), workers, func(lo, hi int) error {
+			for _, i := range ix[lo:hi] {
+				result[i] = t(c.stringPtrAt(i), s2S.stringPtrAt(i))
+			}
+			return nil
+		})
 
 		// NB! String column returned here, not enum. Returning enum could result
 		// in unforeseen results (eg. it would not always fit in an enum, the order
@@ -559,9 +606,9 @@ func (c Column) Apply2(fn interface{}, s2 column.Column, ix index.Int) (column.C
 		return scolumn.New(result), nil
 	case string:
 		// No built in functions for enums at this stage
-		return nil, qerrors.New("enum.apply2", "unknown built in function %s", t)
+		return nil, qerrors.InvalidArgument.New("enum.apply2", "unknown built in function %s", t)
 	default:
-		return nil, qerrors.New("enum.apply2", "cannot apply type %#v to column", fn)
+		return nil, qerrors.TypeMismatch.New("enum.apply2", "cannot apply type %#v to column", fn)
 	}
 }
 
@@ -594,3 +641,25 @@ type Comparable struct {
 	nullGtValue    column.CompareResult
 	equalNullValue column.CompareResult
 }
+
+func (c Column) SortKeys(ix index.Int, reverse, nullLast bool) []uint64 {
+	result := make([]uint64, len(ix))
+
+	// A small null key keeps the number of radix sort passes down
+	nullKey := column.SortKey(0, reverse)
+	if nullLast {
+		nullKey = column.SortKey(maxCardinality+1, reverse)
+	}
+
+	for i, x := range ix {
+		v := c.data[x]
+		if v.isNull() {
+			result[i] = nullKey
+			continue
+		}
+
+		// Offset by one to keep clear of the null key
+		result[i] = column.SortKey(uint64(v)+1, reverse)
+	}
+	return result
+}
@@ -23,3 +23,10 @@ func (v View) Slice() []*string {
 
 	return result
 }
+
+// Values returns the enum values of the column, in order.
+func (v View) Values() []string {
+	result := make([]string, len(v.column.values))
+	copy(result, v.column.values)
+	return result
+}
@@ -8,6 +8,9 @@ type bufferedReader struct {
 	r      io.Reader
 	data   []byte
 	cursor int
+	// newlines holds the number of line breaks
+	// read inside quoted fields of the current row
+	newlines int
 }
 
 func (b *bufferedReader) more() error {
@@ -43,6 +46,7 @@ func (fs *fields) reset() {
 	fs.field = nil
 	fs.fieldStart = 0
 	fs.hitEOL = false
+	fs.buffer.newlines = 0
 }
 
 func (fs *fields) nextUnquotedField() bool {
@@ -111,6 +115,7 @@ func nextQuotedField(buffer *bufferedReader, delimiter byte) ([]byte, bool, erro
 			if quoteCount%2 != 0 {
 				return buffer.data[start:writeCursor], true, nil
 			}
+			buffer.newlines++
 		case '\r':
 			// Ignore carriage returns, assume they are followed by a newline
 			continue
@@ -157,6 +162,10 @@ func (fs *fields) next() bool {
 type Reader struct {
 	fields       fields
 	fieldsBuffer [][]byte
+	// line is the line number of the first line of the last row
+	line int
+	// nextLine is the line number of the first line of the next row
+	nextLine int
 }
 
 // Scans in the next row
@@ -169,6 +178,8 @@ func (r *Reader) Next() bool {
 	for r.fields.next() {
 		r.fieldsBuffer = append(r.fieldsBuffer, r.fields.field)
 	}
+	r.line = r.nextLine
+	r.nextLine += 1 + r.fields.buffer.newlines
 
 	// CRLF support: if there are fields in this row, and the last field ends
 	// with `\r`, then it must have been part of a CRLF line ending, so drop
@@ -198,6 +209,13 @@ func (r *Reader) Fields() [][]byte {
 	return r.fieldsBuffer
 }
 
+// Line returns the line number, starting at 1, of the first line of the
+// last row encountered. A row spans several lines if quoted fields hold
+// line breaks.
+func (r *Reader) Line() int {
+	return r.line
+}
+
 // Return the last error encountered; returns nil if no error was encountered
 // or if the last error was io.EOF.
 func (r *Reader) Err() error {
@@ -249,5 +267,6 @@ func NewReader(r io.Reader, delimiter byte) Reader {
 			delimiter: delimiter,
 		},
 		fieldsBuffer: make([][]byte, 0, 16),
+		nextLine:     1,
 	}
 }
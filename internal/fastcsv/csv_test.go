@@ -217,3 +217,19 @@ func BenchmarkRead(b *testing.B) {
 		}
 	})
 }
+
+func TestReader_Line(t *testing.T) {
+	r := NewReader(strings.NewReader("a,b\n\"1\n2\",3\n\n4,\"5\r\n\n6\"\r\n7,8"), ',')
+	var lines []int
+	for r.Next() {
+		lines = append(lines, r.Line())
+	}
+	if r.Err() != nil {
+		t.Fatal(r.Err())
+	}
+
+	wanted := []int{1, 2, 4, 5, 8}
+	if fmt.Sprint(lines) != fmt.Sprint(wanted) {
+		t.Errorf("Wanted lines %v; got %v", wanted, lines)
+	}
+}
@@ -102,28 +102,28 @@ func (c Column) filterBuiltIn(index index.Int, comparator string, comparatee int
 	switch t := comparatee.(type) {
 	case float64:
 		if math.IsNaN(t) {
-			return qerrors.New("filter float", "NaN not allowed as filter argument")
+			return qerrors.InvalidArgument.New("filter float", "NaN not allowed as filter argument")
 		}
 
 		compFunc, ok := filterFuncs1[comparator]
 		if !ok {
-			return qerrors.New("filter float", "invalid comparison operator to single argument filter, %v", comparator)
+			return qerrors.InvalidArgument.New("filter float", "invalid comparison operator to single argument filter, %v", comparator)
 		}
 		compFunc(index, c.data, t, bIndex)
 	case Column:
 		compFunc, ok := filterFuncs2[comparator]
 		if !ok {
-			return qerrors.New("filter float", "invalid comparison operator to column - column filter, %v", comparator)
+			return qerrors.InvalidArgument.New("filter float", "invalid comparison operator to column - column filter, %v", comparator)
 		}
 		compFunc(index, c.data, t.data, bIndex)
 	case nil:
 		compFunc, ok := filterFuncs0[comparator]
 		if !ok {
-			return qerrors.New("filter float", "invalid comparison operator to zero argument filter, %v", comparator)
+			return qerrors.InvalidArgument.New("filter float", "invalid comparison operator to zero argument filter, %v", comparator)
 		}
 		compFunc(index, c.data, bIndex)
 	default:
-		return qerrors.New("filter float", "invalid comparison value type %v", reflect.TypeOf(comparatee))
+		return qerrors.TypeMismatch.New("filter float", "invalid comparison value type %v", reflect.TypeOf(comparatee))
 	}
 	return nil
 }
@@ -139,7 +139,7 @@ func (c Column) filterCustom1(index index.Int, fn func(float64) bool, bIndex ind
 func (c Column) filterCustom2(index index.Int, fn func(float64, float64) bool, comparatee interface{}, bIndex index.Bool) error {
 	otherC, ok := comparatee.(Column)
 	if !ok {
-		return qerrors.New("filter float", "expected comparatee to be float column, was %v", reflect.TypeOf(comparatee))
+		return qerrors.TypeMismatch.New("filter float", "expected comparatee to be float column, was %v", reflect.TypeOf(comparatee))
 	}
 
 	for i, x := range bIndex {
@@ -161,7 +161,7 @@ func (c Column) Filter(index index.Int, comparator interface{}, comparatee inter
 	case func(float64, float64) bool:
 		err = c.filterCustom2(index, t, comparatee, bIndex)
 	default:
-		err = qerrors.New("filter float", "invalid filter type %v", reflect.TypeOf(comparator))
+		err = qerrors.TypeMismatch.New("filter float", "invalid filter type %v", reflect.TypeOf(comparator))
 	}
 	return err
 }
@@ -174,3 +174,26 @@ func (c Column) Append(cols ...column.Column) (column.Column, error) {
 	// TODO Append
 	return nil, qerrors.New("Append", "Not implemented yet")
 }
+
+func (c Column) SortKeys(ix index.Int, reverse, nullLast bool) []uint64 {
+	result := make([]uint64, len(ix))
+	nullKey := column.NullSortKey(reverse, nullLast)
+	for i, x := range ix {
+		f := c.data[x]
+		if math.IsNaN(f) {
+			result[i] = nullKey
+			continue
+		}
+
+		// Negative numbers have all bits flipped to reverse their order, positive
+		// numbers only the sign bit. Only NaN could produce the keys 0 or max.
+		b := math.Float64bits(f)
+		if b&(1<<63) != 0 {
+			b = ^b
+		} else {
+			b |= 1 << 63
+		}
+		result[i] = column.SortKey(b, reverse)
+	}
+	return result
+}
@@ -1,11 +1,19 @@
 package grouper
 
 import (
+	"container/heap"
+	"context"
 	"math/bits"
+	"sort"
+	"sync/atomic"
+	"unsafe"
 
+	"github.com/tobgu/qframe/internal/cancel"
 	"github.com/tobgu/qframe/internal/column"
 	"github.com/tobgu/qframe/internal/index"
 	"github.com/tobgu/qframe/internal/math/integer"
+	"github.com/tobgu/qframe/internal/parallel"
+	"github.com/tobgu/qframe/qerrors"
 )
 
 /*
@@ -14,6 +22,10 @@ This package implements a basic hash table used for GroupBy and Distinct operati
 Hashing is done using Go runtime memhash, collisions are handled using linear probing.
 
 When the table reaches a certain load factor it will be reallocated into a new, larger table.
+
+For large inputs the rows may be hash partitioned between a number of goroutines that
+each build a table of their own. Since all rows of a group end up in the same partition
+the tables can simply be concatenated once done.
 */
 
 // An entry in the hash table. For group by operations a slice of all positions each group
@@ -22,12 +34,44 @@ type tableEntry struct {
 	ix       index.Int
 	hash     uint32
 	firstPos uint32
+	// seq orders the entries by first appearance, see insertEntry
+	seq      uint32
 	occupied bool
 }
 
+var entrySize = int64(unsafe.Sizeof(tableEntry{}))
+
+// budget keeps track of the number of bytes that may still be allocated for
+// table entries. It is shared between the tables of a partitioned grouping.
+// A nil budget is unlimited.
+type budget struct {
+	remaining int64
+}
+
+func newBudget(limit int) *budget {
+	if limit <= 0 {
+		return nil
+	}
+	return &budget{remaining: int64(limit)}
+}
+
+func (b *budget) alloc(entryCount int) error {
+	if b != nil && atomic.AddInt64(&b.remaining, -int64(entryCount)*entrySize) < 0 {
+		return qerrors.ErrMemoryLimit
+	}
+	return nil
+}
+
+func (b *budget) free(entryCount int) {
+	if b != nil {
+		atomic.AddInt64(&b.remaining, int64(entryCount)*entrySize)
+	}
+}
+
 type table struct {
 	entries     []tableEntry
 	comparables []column.Comparable
+	budget      *budget
 	stats       GroupStats
 	loadFactor  float64
 	groupCount  uint32
@@ -36,8 +80,12 @@ type table struct {
 
 const growthFactor = 2
 
-func (t *table) grow() {
+func (t *table) grow() error {
 	newLen := uint32(growthFactor * len(t.entries))
+	if err := t.budget.alloc(int(newLen)); err != nil {
+		return err
+	}
+
 	newEntries := make([]tableEntry, newLen)
 	bitMask := newLen - 1
 	for _, e := range t.entries {
@@ -51,13 +99,15 @@ func (t *table) grow() {
 	}
 
 	t.stats.RelocationCount++
+	t.budget.free(len(t.entries))
 	t.entries = newEntries
 	t.loadFactor = t.loadFactor / growthFactor
+	return nil
 }
 
-func (t *table) hash(i uint32) uint32 {
+func hash(comparables []column.Comparable, i uint32) uint32 {
 	hashVal := uint64(0)
-	for _, c := range t.comparables {
+	for _, c := range comparables {
 		hashVal = c.Hash(i, hashVal)
 	}
 
@@ -66,12 +116,15 @@ func (t *table) hash(i uint32) uint32 {
 
 const maxLoadFactor = 0.5
 
-func (t *table) insertEntry(i uint32) {
+// insertEntry inserts row i with hash hashSum into the table. seq is stored on
+// the entry if a new entry is created, it must increase between calls.
+func (t *table) insertEntry(i, hashSum, seq uint32) error {
 	if t.loadFactor > maxLoadFactor {
-		t.grow()
+		if err := t.grow(); err != nil {
+			return err
+		}
 	}
 
-	hashSum := t.hash(i)
 	bitMask := uint64(len(t.entries) - 1)
 	startPos := uint64(hashSum) & bitMask
 	var dstEntry *tableEntry
@@ -89,6 +142,7 @@ func (t *table) insertEntry(i uint32) {
 		// Eden entry
 		dstEntry.hash = hashSum
 		dstEntry.firstPos = i
+		dstEntry.seq = seq
 		dstEntry.occupied = true
 		t.groupCount++
 		t.loadFactor = float64(t.groupCount) / float64(len(t.entries))
@@ -105,13 +159,20 @@ func (t *table) insertEntry(i uint32) {
 			}
 		}
 	}
+	return nil
 }
 
-func newTable(sizeExp int, comparables []column.Comparable, collectIx bool) *table {
+func newTable(sizeExp int, comparables []column.Comparable, collectIx bool, b *budget) (*table, error) {
+	size := integer.Pow2(sizeExp)
+	if err := b.alloc(size); err != nil {
+		return nil, err
+	}
+
 	return &table{
-		entries:     make([]tableEntry, integer.Pow2(sizeExp)),
+		entries:     make([]tableEntry, size),
 		comparables: comparables,
-		collectIx:   collectIx}
+		budget:      b,
+		collectIx:   collectIx}, nil
 }
 
 func equals(comparables []column.Comparable, i, j uint32) bool {
@@ -131,6 +192,27 @@ type GroupStats struct {
 	LoadFactor           float64
 }
 
+// Config controls how rows are grouped.
+type Config struct {
+	// Workers is the number of goroutines to use. If > 1 the rows
+	// are hash partitioned between the goroutines.
+	Workers int
+
+	// Unordered relaxes the ordering of the groups. By default the groups
+	// are ordered by the position of their first row in the index, this
+	// requires the groups to be sorted after a partitioned grouping.
+	Unordered bool
+
+	// Context is checked periodically during grouping if set. Grouping
+	// is aborted with the error of the context once it is done.
+	Context context.Context
+
+	// MemoryLimit is the maximum number of bytes that the hash tables may
+	// occupy if > 0. Grouping is aborted with qerrors.ErrMemoryLimit if
+	// the tables would grow beyond the limit.
+	MemoryLimit int
+}
+
 func calculateInitialSizeExp(ixLen int) int {
 	// Size is expressed as 2^x to keep the size a multiple of two.
 	// Initial size is picked fairly arbitrarily at the moment, we don't really know the distribution of
@@ -139,43 +221,257 @@ func calculateInitialSizeExp(ixLen int) int {
 	return integer.Max(bits.Len64(fitSize), 3)
 }
 
-func groupIndex(ix index.Int, comparables []column.Comparable, collectIx bool) ([]tableEntry, GroupStats) {
+func (t *table) groupStats() GroupStats {
+	stats := t.stats
+	stats.LoadFactor = t.loadFactor
+	stats.GroupCount = int(t.groupCount)
+	return stats
+}
+
+// occupiedEntries returns the occupied entries of the table. If ordered
+// they are returned in seq order, which must be the creation order.
+func (t *table) occupiedEntries(ordered bool) []tableEntry {
+	result := make([]tableEntry, 0, t.groupCount)
+	if ordered {
+		result = result[:t.groupCount]
+	}
+
+	for _, e := range t.entries {
+		if e.occupied {
+			if ordered {
+				result[e.seq] = e
+			} else {
+				result = append(result, e)
+			}
+		}
+	}
+	return result
+}
+
+func groupIndex(ix index.Int, comparables []column.Comparable, collectIx bool, conf Config) ([]tableEntry, GroupStats, error) {
+	bounds := parallel.Chunks(len(ix), conf.Workers, parallel.MinChunkSize)
+	if len(bounds) > 2 {
+		return groupPartitioned(ix, comparables, collectIx, conf, bounds)
+	}
+
 	initialSizeExp := calculateInitialSizeExp(len(ix))
-	table := newTable(initialSizeExp, comparables, collectIx)
-	for _, i := range ix {
-		table.insertEntry(i)
+	table, err := newTable(initialSizeExp, comparables, collectIx, newBudget(conf.MemoryLimit))
+	if err != nil {
+		return nil, GroupStats{}, err
+	}
+
+	for k, i := range ix {
+		if k%cancel.Interval == 0 {
+			if err := cancel.Err(conf.Context); err != nil {
+				return nil, GroupStats{}, err
+			}
+		}
+
+		if err := table.insertEntry(i, hash(comparables, i), table.groupCount); err != nil {
+			return nil, GroupStats{}, err
+		}
 	}
 
-	stats := table.stats
-	stats.LoadFactor = table.loadFactor
-	stats.GroupCount = int(table.groupCount)
-	return table.entries, stats
+	return table.occupiedEntries(!conf.Unordered), table.groupStats(), nil
 }
 
-func GroupBy(ix index.Int, comparables []column.Comparable) ([]index.Int, GroupStats) {
-	entries, stats := groupIndex(ix, comparables, true)
-	result := make([]index.Int, 0, stats.GroupCount)
-	for _, e := range entries {
-		if e.occupied {
-			if e.ix == nil {
-				result = append(result, index.Int{e.firstPos})
-			} else {
-				result = append(result, e.ix)
+// groupPartitioned groups the rows using one table per partition of the rows. The
+// partitions are built by the chunks given by bounds in parallel. The rows are
+// placed in partitions based on the high bits of their hash while the tables use
+// the low bits.
+func groupPartitioned(ix index.Int, comparables []column.Comparable, collectIx bool, conf Config, bounds []int) ([]tableEntry, GroupStats, error) {
+	chunkCount := len(bounds) - 1
+	partBits := bits.Len(uint(chunkCount - 1))
+	partCount := 1 << partBits
+	partOf := func(h uint32) int {
+		return int(h >> (32 - partBits))
+	}
+
+	// Hash all rows and count the number of rows per chunk and partition
+	hashes := make([]uint32, len(ix))
+	offsets := make([][]int, chunkCount)
+	err := parallel.ForSize(chunkCount, chunkCount, 1, func(lo, hi int) error {
+		for c := lo; c < hi; c++ {
+			counts := make([]int, partCount)
+			for k := bounds[c]; k < bounds[c+1]; k++ {
+				if (k-bounds[c])%cancel.Interval == 0 {
+					if err := cancel.Err(conf.Context); err != nil {
+						return err
+					}
+				}
+
+				h := hash(comparables, ix[k])
+				hashes[k] = h
+				counts[partOf(h)]++
+			}
+			offsets[c] = counts
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, GroupStats{}, err
+	}
+
+	// Turn the counts into the offsets at which each chunk
+	// should write its positions into each partition.
+	partitions := make([][]uint32, partCount)
+	for p := range partitions {
+		offset := 0
+		for c := range offsets {
+			count := offsets[c][p]
+			offsets[c][p] = offset
+			offset += count
+		}
+		partitions[p] = make([]uint32, offset)
+	}
+
+	// Distribute the positions of the rows in the index between the partitions,
+	// this keeps the positions in ascending order within each partition.
+	_ = parallel.ForSize(chunkCount, chunkCount, 1, func(lo, hi int) error {
+		for c := lo; c < hi; c++ {
+			for k := bounds[c]; k < bounds[c+1]; k++ {
+				p := partOf(hashes[k])
+				partitions[p][offsets[c][p]] = uint32(k)
+				offsets[c][p]++
+			}
+		}
+		return nil
+	})
+
+	// Build one table per partition. The position of the first row
+	// of each group is used as seq to allow ordering between partitions.
+	tables := make([]*table, partCount)
+	b := newBudget(conf.MemoryLimit)
+	err = parallel.ForSize(partCount, conf.Workers, 1, func(lo, hi int) error {
+		for p := lo; p < hi; p++ {
+			part := partitions[p]
+			t, err := newTable(calculateInitialSizeExp(len(part)), comparables, collectIx, b)
+			if err != nil {
+				return err
 			}
+
+			for j, k := range part {
+				if j%cancel.Interval == 0 {
+					if err := cancel.Err(conf.Context); err != nil {
+						return err
+					}
+				}
+
+				if err := t.insertEntry(ix[k], hashes[k], k); err != nil {
+					return err
+				}
+			}
+			tables[p] = t
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, GroupStats{}, err
+	}
+
+	var stats GroupStats
+	entryCount := 0
+	for _, t := range tables {
+		stats.RelocationCount += t.stats.RelocationCount
+		stats.RelocationCollisions += t.stats.RelocationCollisions
+		stats.InsertCollisions += t.stats.InsertCollisions
+		stats.GroupCount += int(t.groupCount)
+		entryCount += len(t.entries)
+	}
+	stats.LoadFactor = float64(stats.GroupCount) / float64(entryCount)
+
+	partEntries := make([][]tableEntry, partCount)
+	_ = parallel.ForSize(partCount, conf.Workers, 1, func(lo, hi int) error {
+		for p := lo; p < hi; p++ {
+			entries := tables[p].occupiedEntries(false)
+			if !conf.Unordered {
+				sort.Slice(entries, func(i, j int) bool { return entries[i].seq < entries[j].seq })
+			}
+			partEntries[p] = entries
 		}
+		return nil
+	})
+
+	result := make([]tableEntry, 0, stats.GroupCount)
+	if conf.Unordered {
+		for _, entries := range partEntries {
+			result = append(result, entries...)
+		}
+		return result, stats, nil
 	}
 
-	return result, stats
+	// Merge the sorted entries of the partitions
+	h := make(partitionHeap, 0, partCount)
+	for _, entries := range partEntries {
+		if len(entries) > 0 {
+			h = append(h, entries)
+		}
+	}
+
+	heap.Init(&h)
+	for len(h) > 0 {
+		result = append(result, h[0][0])
+		if h[0] = h[0][1:]; len(h[0]) > 0 {
+			heap.Fix(&h, 0)
+		} else {
+			heap.Pop(&h)
+		}
+	}
+
+	return result, stats, nil
 }
 
-func Distinct(ix index.Int, comparables []column.Comparable) index.Int {
-	entries, stats := groupIndex(ix, comparables, false)
-	result := make(index.Int, 0, stats.GroupCount)
-	for _, e := range entries {
-		if e.occupied {
-			result = append(result, e.firstPos)
+// partitionHeap is a min heap of the non empty, sorted, entries of
+// partitions ordered by the seq of the first entry of each partition.
+type partitionHeap [][]tableEntry
+
+func (h partitionHeap) Len() int           { return len(h) }
+func (h partitionHeap) Less(i, j int) bool { return h[i][0].seq < h[j][0].seq }
+func (h partitionHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *partitionHeap) Push(x interface{}) {
+	*h = append(*h, x.([]tableEntry))
+}
+
+func (h *partitionHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// GroupBy groups the rows in ix that are equal with respect to comparables. The rows
+// of each group are kept in index order.
+func GroupBy(ix index.Int, comparables []column.Comparable, conf Config) ([]index.Int, GroupStats, error) {
+	entries, stats, err := groupIndex(ix, comparables, true, conf)
+	if err != nil {
+		return nil, stats, err
+	}
+
+	result := make([]index.Int, len(entries))
+	for i, e := range entries {
+		if e.ix == nil {
+			result[i] = index.Int{e.firstPos}
+		} else {
+			result[i] = e.ix
 		}
 	}
 
-	return result
+	return result, stats, nil
+}
+
+// Distinct returns the first row, in index order, of each group of rows
+// in ix that are equal with respect to comparables.
+func Distinct(ix index.Int, comparables []column.Comparable, conf Config) (index.Int, error) {
+	entries, _, err := groupIndex(ix, comparables, false, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(index.Int, len(entries))
+	for i, e := range entries {
+		result[i] = e.firstPos
+	}
+
+	return result, nil
 }
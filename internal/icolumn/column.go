@@ -134,29 +134,29 @@ func (c Column) filterBuiltIn(index index.Int, comparator string, comparatee int
 	if intC, ok := intComp(comparatee); ok {
 		filterFn, ok := filterFuncs[comparator]
 		if !ok {
-			return qerrors.New("filter int", "unknown filter operator %v", comparator)
+			return qerrors.InvalidArgument.New("filter int", "unknown filter operator %v", comparator)
 		}
 		filterFn(index, c.data, intC, bIndex)
 	} else if set, ok := newIntSet(comparatee); ok {
 		filterFn, ok := multiInputFilterFuncs[comparator]
 		if !ok {
-			return qerrors.New("filter int", "unknown filter operator %v", comparator)
+			return qerrors.InvalidArgument.New("filter int", "unknown filter operator %v", comparator)
 		}
 		filterFn(index, c.data, set, bIndex)
 	} else if columnC, ok := comparatee.(Column); ok {
 		filterFn, ok := filterFuncs2[comparator]
 		if !ok {
-			return qerrors.New("filter int", "unknown filter operator %v", comparator)
+			return qerrors.InvalidArgument.New("filter int", "unknown filter operator %v", comparator)
 		}
 		filterFn(index, c.data, columnC.data, bIndex)
 	} else if comparatee == nil {
 		compFunc, ok := filterFuncs0[comparator]
 		if !ok {
-			return qerrors.New("filter int", "invalid comparison operator to zero argument filter, %v", comparator)
+			return qerrors.InvalidArgument.New("filter int", "invalid comparison operator to zero argument filter, %v", comparator)
 		}
 		compFunc(index, c.data, bIndex)
 	} else {
-		return qerrors.New("filter int", "invalid comparison value type %v", reflect.TypeOf(comparatee))
+		return qerrors.TypeMismatch.New("filter int", "invalid comparison value type %v", reflect.TypeOf(comparatee))
 	}
 
 	return nil
@@ -173,7 +173,7 @@ func (c Column) filterCustom1(index index.Int, fn func(int) bool, bIndex index.B
 func (c Column) filterCustom2(index index.Int, fn func(int, int) bool, comparatee interface{}, bIndex index.Bool) error {
 	otherC, ok := comparatee.(Column)
 	if !ok {
-		return qerrors.New("filter int", "expected comparatee to be int column, was %v", reflect.TypeOf(comparatee))
+		return qerrors.TypeMismatch.New("filter int", "expected comparatee to be int column, was %v", reflect.TypeOf(comparatee))
 	}
 
 	for i, x := range bIndex {
@@ -195,7 +195,7 @@ func (c Column) Filter(index index.Int, comparator interface{}, comparatee inter
 	case func(int, int) bool:
 		err = c.filterCustom2(index, t, comparatee, bIndex)
 	default:
-		err = qerrors.New("filter int", "invalid filter type %v", reflect.TypeOf(comparator))
+		err = qerrors.TypeMismatch.New("filter int", "invalid filter type %v", reflect.TypeOf(comparator))
 	}
 	return err
 }
@@ -211,7 +211,7 @@ func (c Column) Append(cols ...column.Column) (column.Column, error) {
 	for _, col := range cols {
 		intCol, ok := col.(Column)
 		if !ok {
-			return nil, qerrors.New("append int", "can only append integer columns to integer column")
+			return nil, qerrors.TypeMismatch.New("append int", "can only append integer columns to integer column")
 		}
 		newLen += intCol.Len()
 		intCols = append(intCols, intCol)
@@ -225,3 +225,12 @@ func (c Column) Append(cols ...column.Column) (column.Column, error) {
 
 	return New(newData), nil
 }
+
+func (c Column) SortKeys(ix index.Int, reverse, nullLast bool) []uint64 {
+	result := make([]uint64, len(ix))
+	for i, x := range ix {
+		// Flip the sign bit to order negative numbers before positive ones
+		result[i] = column.SortKey(uint64(c.data[x])^(1<<63), reverse)
+	}
+	return result
+}
@@ -0,0 +1,424 @@
+package io
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"strconv"
+	"unsafe"
+
+	"github.com/tobgu/qframe/internal/bcolumn"
+	"github.com/tobgu/qframe/internal/column"
+	"github.com/tobgu/qframe/internal/ecolumn"
+	"github.com/tobgu/qframe/internal/fcolumn"
+	"github.com/tobgu/qframe/internal/icolumn"
+	"github.com/tobgu/qframe/internal/index"
+	"github.com/tobgu/qframe/internal/ncolumn"
+	"github.com/tobgu/qframe/internal/scolumn"
+	qfstrings "github.com/tobgu/qframe/internal/strings"
+	"github.com/tobgu/qframe/qerrors"
+	"github.com/tobgu/qframe/types"
+)
+
+// The binary format is a columnar, little endian, format where the data of each
+// column is stored the same way as it is stored in memory. Every length and data
+// section is aligned to eight bytes relative to the start of the data. This allows
+// column data to be used in place, without any conversions, when decoding.
+//
+//	magic       [4]byte "QFBN"
+//	version     uint32
+//	columnCount uint64
+//	rowCount    uint64
+//	columns     columnCount x column
+//
+// Where each column is:
+//
+//	name     string
+//	dataType string
+//	data     type specific sections, see writeColumn
+//
+// And strings and byte sections are stored as a uint64 length followed by the data.
+const (
+	binaryMagic     = "QFBN"
+	BinaryVersion   = 1
+	binaryAlignment = 8
+)
+
+var littleEndian = binary.LittleEndian
+
+func checkBinaryPlatform() error {
+	x := uint16(1)
+	if *(*byte)(unsafe.Pointer(&x)) != 1 || strconv.IntSize != 64 {
+		return qerrors.New("binary format", "only supported on 64 bit little endian platforms")
+	}
+	return nil
+}
+
+func sliceToBytes[T any](s []T) []byte {
+	var zero T
+	return unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(s))), len(s)*int(unsafe.Sizeof(zero)))
+}
+
+// bytesToSlice reinterprets b as a slice of T. b is used in place if it is
+// properly aligned, otherwise it is copied.
+func bytesToSlice[T any](b []byte) ([]T, error) {
+	var zero T
+	size := int(unsafe.Sizeof(zero))
+	if len(b)%size != 0 {
+		return nil, qerrors.Parse.New("decode binary", "invalid data length %d for element size %d", len(b), size)
+	}
+
+	if len(b) == 0 {
+		return []T{}, nil
+	}
+
+	if uintptr(unsafe.Pointer(unsafe.SliceData(b)))%unsafe.Alignof(zero) != 0 {
+		aligned := make([]T, len(b)/size)
+		copy(sliceToBytes(aligned), b)
+		return aligned, nil
+	}
+
+	return unsafe.Slice((*T)(unsafe.Pointer(unsafe.SliceData(b))), len(b)/size), nil
+}
+
+type binaryWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (bw *binaryWriter) write(b []byte) {
+	if bw.err != nil {
+		return
+	}
+
+	n, err := bw.w.Write(b)
+	bw.n += int64(n)
+	bw.err = err
+}
+
+func (bw *binaryWriter) pad() {
+	var padding [binaryAlignment]byte
+	if rem := bw.n % binaryAlignment; rem != 0 {
+		bw.write(padding[:binaryAlignment-rem])
+	}
+}
+
+func (bw *binaryWriter) uint64(v uint64) {
+	var buf [8]byte
+	littleEndian.PutUint64(buf[:], v)
+	bw.write(buf[:])
+}
+
+func (bw *binaryWriter) bytes(b []byte) {
+	bw.uint64(uint64(len(b)))
+	bw.write(b)
+	bw.pad()
+}
+
+func (bw *binaryWriter) string(s string) {
+	bw.bytes([]byte(s))
+}
+
+func isIdentity(ix index.Int, length int) bool {
+	if len(ix) != length {
+		return false
+	}
+
+	for i, x := range ix {
+		if uint32(i) != x {
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeColumn writes the type specific data sections of the rows in ix.
+func (bw *binaryWriter) writeColumn(col column.Column, ix index.Int) error {
+	identity := isIdentity(ix, col.Len())
+	switch c := col.(type) {
+	case icolumn.Column:
+		data := c.Data()
+		if !identity {
+			data = c.View(ix).Slice()
+		}
+		bw.bytes(sliceToBytes(data))
+	case fcolumn.Column:
+		data := c.Data()
+		if !identity {
+			data = c.View(ix).Slice()
+		}
+		bw.bytes(sliceToBytes(data))
+	case bcolumn.Column:
+		data := c.Data()
+		if !identity {
+			data = c.View(ix).Slice()
+		}
+		bw.bytes(sliceToBytes(data))
+	case scolumn.Column:
+		if !identity {
+			c = c.Subset(ix).(scolumn.Column)
+		}
+		pointers, data := c.Raw()
+		bw.bytes(sliceToBytes(pointers))
+		bw.bytes(data)
+	case ecolumn.Column:
+		data, values, strict := c.Raw()
+		if !identity {
+			subset := make([]byte, len(ix))
+			for i, x := range ix {
+				subset[i] = data[x]
+			}
+			data = subset
+		}
+
+		bw.uint64(uint64(len(values)))
+		for _, v := range values {
+			bw.string(v)
+		}
+
+		var s uint64
+		if strict {
+			s = 1
+		}
+		bw.uint64(s)
+		bw.bytes(data)
+	case ncolumn.Column:
+		// No data
+	default:
+		return qerrors.TypeMismatch.New("WriteBinary", "unsupported column type: %s", col.DataType())
+	}
+
+	return nil
+}
+
+// WriteBinary writes the rows identified by ix in cols to writer using the binary format.
+func WriteBinary(writer io.Writer, names []string, cols []column.Column, ix index.Int) error {
+	if err := checkBinaryPlatform(); err != nil {
+		return qerrors.Propagate("WriteBinary", err)
+	}
+
+	bw := &binaryWriter{w: bufio.NewWriter(writer)}
+	bw.write([]byte(binaryMagic))
+	var version [4]byte
+	littleEndian.PutUint32(version[:], BinaryVersion)
+	bw.write(version[:])
+	bw.uint64(uint64(len(cols)))
+	bw.uint64(uint64(len(ix)))
+	for i, col := range cols {
+		bw.string(names[i])
+		bw.string(string(col.DataType()))
+		if err := bw.writeColumn(col, ix); err != nil {
+			return err
+		}
+	}
+
+	if bw.err != nil {
+		return qerrors.IO.Propagate("WriteBinary", bw.err)
+	}
+
+	if err := bw.w.Flush(); err != nil {
+		return qerrors.IO.Propagate("WriteBinary flush", err)
+	}
+
+	return nil
+}
+
+type binaryReader struct {
+	b   []byte
+	pos int
+}
+
+func (br *binaryReader) next(n uint64) ([]byte, error) {
+	if n > uint64(len(br.b)-br.pos) {
+		return nil, qerrors.Parse.New("decode binary", "unexpected end of data at offset %d", br.pos)
+	}
+
+	result := br.b[br.pos : br.pos+int(n)]
+	br.pos += int(n)
+	return result, nil
+}
+
+func (br *binaryReader) uint64() (uint64, error) {
+	b, err := br.next(8)
+	if err != nil {
+		return 0, err
+	}
+	return littleEndian.Uint64(b), nil
+}
+
+func (br *binaryReader) bytes() ([]byte, error) {
+	n, err := br.uint64()
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := br.next(n)
+	if err != nil {
+		return nil, err
+	}
+
+	if rem := br.pos % binaryAlignment; rem != 0 {
+		if _, err := br.next(uint64(binaryAlignment - rem)); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (br *binaryReader) string() (string, error) {
+	b, err := br.bytes()
+	return string(b), err
+}
+
+func checkStringPointers(pointers []qfstrings.Pointer, dataLen int) error {
+	for _, p := range pointers {
+		if !p.IsNull() && p.Offset()+p.Len() > dataLen {
+			return qerrors.Parse.New("decode binary", "string pointer out of range: %s", p)
+		}
+	}
+	return nil
+}
+
+func checkBools(data []byte) error {
+	for _, b := range data {
+		if b > 1 {
+			return qerrors.Parse.New("decode binary", "invalid bool value: %d", b)
+		}
+	}
+	return nil
+}
+
+func (br *binaryReader) readColumn(dataType types.DataType) (column.Column, error) {
+	switch dataType {
+	case types.Int:
+		b, err := br.bytes()
+		if err != nil {
+			return nil, err
+		}
+		data, err := bytesToSlice[int](b)
+		return icolumn.New(data), err
+	case types.Float:
+		b, err := br.bytes()
+		if err != nil {
+			return nil, err
+		}
+		data, err := bytesToSlice[float64](b)
+		return fcolumn.New(data), err
+	case types.Bool:
+		b, err := br.bytes()
+		if err != nil {
+			return nil, err
+		}
+		if err := checkBools(b); err != nil {
+			return nil, err
+		}
+		data, err := bytesToSlice[bool](b)
+		return bcolumn.New(data), err
+	case types.String:
+		b, err := br.bytes()
+		if err != nil {
+			return nil, err
+		}
+		pointers, err := bytesToSlice[qfstrings.Pointer](b)
+		if err != nil {
+			return nil, err
+		}
+		data, err := br.bytes()
+		if err != nil {
+			return nil, err
+		}
+		if err := checkStringPointers(pointers, len(data)); err != nil {
+			return nil, err
+		}
+		return scolumn.NewBytes(pointers, data), nil
+	case types.Enum:
+		count, err := br.uint64()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, 0)
+		for i := uint64(0); i < count; i++ {
+			v, err := br.string()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		strict, err := br.uint64()
+		if err != nil {
+			return nil, err
+		}
+		data, err := br.bytes()
+		if err != nil {
+			return nil, err
+		}
+		return ecolumn.NewRaw(data, values, strict == 1)
+	case types.Undefined:
+		return ncolumn.Column{}, nil
+	default:
+		return nil, qerrors.Parse.New("decode binary", "unknown data type: %s", dataType)
+	}
+}
+
+// DecodeBinary decodes data in the binary format. The returned columns
+// reference b directly, b must hence not be modified after this call.
+func DecodeBinary(b []byte) ([]string, []column.Column, error) {
+	if err := checkBinaryPlatform(); err != nil {
+		return nil, nil, qerrors.Propagate("DecodeBinary", err)
+	}
+
+	br := &binaryReader{b: b}
+	header, err := br.next(8)
+	if err != nil {
+		return nil, nil, qerrors.Propagate("DecodeBinary", err)
+	}
+
+	if string(header[:4]) != binaryMagic {
+		return nil, nil, qerrors.Parse.New("DecodeBinary", "not a QFrame binary")
+	}
+
+	if version := littleEndian.Uint32(header[4:]); version != BinaryVersion {
+		return nil, nil, qerrors.Parse.New("DecodeBinary", "unsupported version: %d", version)
+	}
+
+	colCount, err := br.uint64()
+	if err != nil {
+		return nil, nil, qerrors.Propagate("DecodeBinary", err)
+	}
+
+	rowCount, err := br.uint64()
+	if err != nil {
+		return nil, nil, qerrors.Propagate("DecodeBinary", err)
+	}
+
+	names := make([]string, 0)
+	cols := make([]column.Column, 0)
+	for i := uint64(0); i < colCount; i++ {
+		name, err := br.string()
+		if err != nil {
+			return nil, nil, qerrors.Propagate("DecodeBinary", err)
+		}
+
+		dataType, err := br.string()
+		if err != nil {
+			return nil, nil, qerrors.Propagate("DecodeBinary", err)
+		}
+
+		col, err := br.readColumn(types.DataType(dataType))
+		if err != nil {
+			return nil, nil, qerrors.Propagate(`DecodeBinary column "`+name+`"`, err)
+		}
+
+		if col.DataType() != types.Undefined && uint64(col.Len()) != rowCount {
+			return nil, nil, qerrors.Parse.New("DecodeBinary", `wrong length of column "%s", expected %d, was %d`, name, rowCount, col.Len())
+		}
+
+		names = append(names, name)
+		cols = append(cols, col)
+	}
+
+	return names, cols, nil
+}
@@ -0,0 +1,88 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/tobgu/qframe/qerrors"
+	"github.com/tobgu/qframe/types"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+func detectCompression(r *bufio.Reader) types.Compression {
+	// Errors are ignored here, too short input is simply not compressed.
+	header, _ := r.Peek(len(zstdMagic))
+	if bytes.HasPrefix(header, gzipMagic) {
+		return types.CompressionGzip
+	}
+
+	if bytes.HasPrefix(header, zstdMagic) {
+		return types.CompressionZstd
+	}
+
+	return types.CompressionNone
+}
+
+// Decompress wraps reader in a decompressor for compression c. The returned
+// function must be called to release resources once reading is done.
+func Decompress(reader io.Reader, c types.Compression) (io.Reader, func(), error) {
+	if c == types.CompressionAuto {
+		bufReader := bufio.NewReader(reader)
+		reader = bufReader
+		c = detectCompression(bufReader)
+	}
+
+	switch c {
+	case types.CompressionNone:
+		return reader, func() {}, nil
+	case types.CompressionGzip:
+		r, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, nil, qerrors.IO.Propagate("Decompress gzip", err)
+		}
+		return r, func() { _ = r.Close() }, nil
+	case types.CompressionZstd:
+		r, err := zstd.NewReader(reader)
+		if err != nil {
+			return nil, nil, qerrors.IO.Propagate("Decompress zstd", err)
+		}
+		return r, r.Close, nil
+	default:
+		return nil, nil, qerrors.InvalidArgument.New("Decompress", "unknown compression: %s", c)
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// Compress wraps writer in a compressor for compression c. The returned
+// writer must be closed to flush all data to writer. Closing it does not
+// close writer.
+func Compress(writer io.Writer, c types.Compression) (io.WriteCloser, error) {
+	switch c {
+	case types.CompressionNone:
+		return nopWriteCloser{Writer: writer}, nil
+	case types.CompressionGzip:
+		return gzip.NewWriter(writer), nil
+	case types.CompressionZstd:
+		w, err := zstd.NewWriter(writer)
+		if err != nil {
+			return nil, qerrors.IO.Propagate("Compress zstd", err)
+		}
+		return w, nil
+	default:
+		return nil, qerrors.InvalidArgument.New("Compress", "unsupported compression for writing: %s", c)
+	}
+}
@@ -1,13 +1,23 @@
 package io
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"math"
+	"sort"
+	"strconv"
+	"unicode/utf8"
 
+	"github.com/tobgu/qframe/internal/bcolumn"
+	"github.com/tobgu/qframe/internal/column"
 	"github.com/tobgu/qframe/internal/ecolumn"
 	"github.com/tobgu/qframe/internal/fastcsv"
+	"github.com/tobgu/qframe/internal/fcolumn"
+	"github.com/tobgu/qframe/internal/icolumn"
+	"github.com/tobgu/qframe/internal/index"
 	"github.com/tobgu/qframe/internal/ncolumn"
+	"github.com/tobgu/qframe/internal/scolumn"
 	"github.com/tobgu/qframe/internal/strings"
 	"github.com/tobgu/qframe/qerrors"
 	"github.com/tobgu/qframe/types"
@@ -30,25 +40,137 @@ type CSVConfig struct {
 	Headers                []string
 	RenameDuplicateColumns bool
 	MissingColumnNameAlias string
+	Compression            types.Compression
+	BadRowPolicy           BadRowPolicy
+	BadRows                *[]BadRow
+	NullInvalid            bool
+
+	// Columns restricts the columns returned, if not nil. The remaining
+	// columns are still parsed but never converted. Names of columns that
+	// do not exist are ignored.
+	Columns []string
+
+	// Schema is a qframe.Schema that the result is coerced into after
+	// reading, if not nil. It is opaque to the reader.
+	Schema interface{}
+}
+
+// BadRowPolicy controls how rows that cannot be read are handled.
+type BadRowPolicy int
+
+const (
+	// BadRowFail fails reading on the first bad row.
+	BadRowFail BadRowPolicy = iota
+
+	// BadRowSkip drops bad rows.
+	BadRowSkip
+
+	// BadRowCollect drops bad rows and reports them in CSVConfig.BadRows.
+	BadRowCollect
+)
+
+// BadRow describes a row that could not be read.
+type BadRow struct {
+	// Line is the line number of the first line of the row, a row
+	// spans several lines if quoted fields hold line breaks.
+	Line int
+
+	// Encoded holds the fields of the row re-encoded as CSV with the
+	// configured delimiter, quoting fields only where needed. It is not
+	// the original input, quoting, spacing and line endings may differ.
+	Encoded string
+
+	// Reason describes why the row could not be read.
+	Reason string
 }
 
+// QuoteStyle controls which fields are quoted when writing CSV.
+type QuoteStyle int
+
+const (
+	// QuoteMinimal only quotes fields that contain special characters.
+	QuoteMinimal QuoteStyle = iota
+	// QuoteAll quotes all non null fields.
+	QuoteAll
+	// QuoteNonNumeric quotes all non null fields that are not ints or floats.
+	QuoteNonNumeric
+)
+
 // For writing CSV
 type ToCsvConfig struct {
-	Header  bool
-	Columns []string
+	Header         bool
+	Columns        []string
+	Delimiter      byte
+	NullRep        string
+	Quoting        QuoteStyle
+	LineTerminator string
+	FloatPrecision int
+	Compression    types.Compression
 }
 
 func isEmptyLine(fields [][]byte) bool {
 	return len(fields) == 1 && len(fields[0]) == 0
 }
 
-func ReadCSV(reader io.Reader, conf CSVConfig) (map[string]interface{}, []string, error) {
+// CSVFields holds the unparsed fields of CSV data, column by column.
+type CSVFields struct {
+	Headers []string
+
+	// Lines holds the line number, in the input, of each row. Always
+	// populated by ReadCSVFields, otherwise only if lines have been
+	// skipped or rows span several lines. Use Line to get the line
+	// number of a row.
+	Lines []int
+
+	colBytes    [][]byte
+	colPointers [][]bytePointer
+}
+
+// RowCount returns the number of rows read.
+func (f CSVFields) RowCount() int {
+	if len(f.colPointers) == 0 {
+		return 0
+	}
+	return len(f.colPointers[0])
+}
+
+// Line returns the line number, in the input, of row.
+func (f CSVFields) Line(row int) int {
+	if f.Lines == nil {
+		// No lines skipped, the first row follows the header
+		// and each row is on a line of its own
+		return row + 2
+	}
+	return f.Lines[row]
+}
+
+// Field returns the content of a field. The returned slice
+// must not be modified.
+func (f CSVFields) Field(col, row int) []byte {
+	p := f.colPointers[col][row]
+	return f.colBytes[col][p.start:p.end]
+}
+
+// ReadCSVFields reads CSV data without converting the fields into typed columns.
+func ReadCSVFields(reader io.Reader, conf CSVConfig) (CSVFields, error) {
+	return readCSVFields(reader, conf, true)
+}
+
+func readCSVFields(reader io.Reader, conf CSVConfig, trackLines bool) (CSVFields, error) {
+	reader, release, err := Decompress(reader, conf.Compression)
+	if err != nil {
+		return CSVFields{}, qerrors.Propagate("ReadCSV", err)
+	}
+	defer release()
+
+	trackLines = trackLines || conf.BadRowPolicy != BadRowFail || conf.NullInvalid
+
 	r := fastcsv.NewReader(reader, conf.Delimiter)
 	headers := conf.Headers
 	if len(headers) == 0 {
 		byteHeader, err := r.Read()
 		if err != nil {
-			return nil, nil, qerrors.Propagate("ReadCSV read header", err)
+			return CSVFields{}, qerrors.IO.Propagate("ReadCSV read header", err)
 		}
 
 		headers = make([]string, len(byteHeader))
@@ -65,22 +187,31 @@ func ReadCSV(reader io.Reader, conf CSVConfig) (map[string]interface{}, []string
 	// All bytes in a column
 	colBytes := make([][]byte, len(headers))
 
-	row := 1
+	var lines []int
+	var badRows []BadRow
 	nonEmptyRows := 0
 	for r.Next() {
 		if r.Err() != nil {
-			return nil, nil, qerrors.Propagate("ReadCSV read body", r.Err())
+			return CSVFields{}, qerrors.IO.Propagate("ReadCSV read body", r.Err())
 		}
 
-		row++
+		line := r.Line()
 		fields := r.Fields()
 		if len(fields) != len(headers) {
 			if isEmptyLine(fields) && conf.IgnoreEmptyLines {
 				continue
 			}
 
-			return nil, nil, qerrors.New("ReadCSV", "Wrong number of columns on line %d, expected %d, was %d",
-				row, len(headers), len(fields))
+			if conf.BadRowPolicy == BadRowFail {
+				return CSVFields{}, qerrors.Parse.New("ReadCSV", "Wrong number of columns, expected %d, was %d",
+					len(headers), len(fields)).At(line, 0)
+			}
+
+			if conf.BadRowPolicy == BadRowCollect {
+				reason := fmt.Sprintf("wrong number of columns, expected %d, was %d", len(headers), len(fields))
+				badRows = append(badRows, BadRow{Line: line, Encoded: encodeRow(fields, conf.Delimiter), Reason: reason})
+			}
+			continue
 		}
 
 		if isEmptyLine(fields) && conf.IgnoreEmptyLines {
@@ -93,6 +224,15 @@ func ReadCSV(reader io.Reader, conf CSVConfig) (map[string]interface{}, []string
 			colPointers[i] = append(colPointers[i], bytePointer{start: uint32(start), end: uint32(len(colBytes[i]))})
 		}
 
+		if !trackLines && line != nonEmptyRows+2 {
+			lines = derivedLines(nonEmptyRows)
+			trackLines = true
+		}
+
+		if trackLines {
+			lines = append(lines, line)
+		}
+
 		nonEmptyRows++
 		if nonEmptyRows == 1000 && conf.RowCountHint > 2000 {
 			// This is an optimization that can reduce allocations and copying if the number
@@ -112,9 +252,200 @@ func ReadCSV(reader io.Reader, conf CSVConfig) (map[string]interface{}, []string
 
 	}
 
+	if conf.BadRows != nil {
+		*conf.BadRows = append(*conf.BadRows, badRows...)
+	}
+
+	return CSVFields{Headers: headers, Lines: lines, colBytes: colBytes, colPointers: colPointers}, nil
+}
+
+// derivedLines returns the line numbers of rowCount rows directly following the
+// header. Called when lines have been skipped, or a row spans several lines, and
+// the line numbers can no longer be derived from the row numbers.
+func derivedLines(rowCount int) []int {
+	lines := make([]int, rowCount, rowCount+1)
+	for row := range lines {
+		lines[row] = row + 2
+	}
+	return lines
+}
+
+// encodeRow encodes fields as a CSV row.
+func encodeRow(fields [][]byte, delimiter byte) string {
+	conf := ToCsvConfig{Delimiter: delimiter}
+	var buf []byte
+	for i, f := range fields {
+		if i > 0 {
+			buf = append(buf, delimiter)
+		}
+		buf = appendField(buf, f, false, conf)
+	}
+	return string(buf)
+}
+
+func (f CSVFields) encodeRow(row int, delimiter byte) string {
+	fields := make([][]byte, len(f.colPointers))
+	for col := range fields {
+		fields[col] = f.Field(col, row)
+	}
+	return encodeRow(fields, delimiter)
+}
+
+// invalidField returns a description of why field cannot
+// be converted into the type of the column, "" if it can.
+func invalidField(field []byte, dataType types.DataType, enumVals map[string]bool, conf CSVConfig) string {
+	var err error
+	switch dataType {
+	case types.Int:
+		_, err = strings.ParseInt(field)
+	case types.Float:
+		if len(field) > 0 {
+			_, err = strings.ParseFloat(field)
+		}
+	case types.Bool:
+		_, err = strings.ParseBool(field)
+	case types.Enum:
+		if enumVals != nil && !enumVals[strings.UnsafeBytesToString(field)] && !(len(field) == 0 && conf.EmptyNull) {
+			return fmt.Sprintf("invalid enum value %q", field)
+		}
+	}
+
+	if err != nil {
+		return fmt.Sprintf("invalid %s value %q", dataType, field)
+	}
+	return ""
+}
+
+// checkFields checks that all fields of typed columns can be converted.
+// Rows containing invalid fields are removed and handled according to the
+// bad row policy, or the fields are nulled if NullInvalid is set and the
+// column type allows it. The nulled fields of each column are returned.
+func checkFields(fields *CSVFields, conf CSVConfig) ([][]bool, error) {
+	rowCount := fields.RowCount()
+	nulls := make([][]bool, len(fields.Headers))
+	badReasons := map[int]string{}
+	for col, header := range fields.Headers {
+		dataType := conf.Types[header]
+		var enumVals map[string]bool
+		if dataType == types.Enum && len(conf.EnumVals[header]) > 0 {
+			enumVals = make(map[string]bool, len(conf.EnumVals[header]))
+			for _, v := range conf.EnumVals[header] {
+				enumVals[v] = true
+			}
+		}
+
+		for row := 0; row < rowCount; row++ {
+			reason := invalidField(fields.Field(col, row), dataType, enumVals, conf)
+			if reason == "" {
+				continue
+			}
+
+			if conf.NullInvalid && dataType != types.Bool {
+				if nulls[col] == nil {
+					nulls[col] = make([]bool, rowCount)
+				}
+				nulls[col][row] = true
+				continue
+			}
+
+			if _, ok := badReasons[row]; !ok {
+				badReasons[row] = fmt.Sprintf("column %s: %s", header, reason)
+			}
+		}
+	}
+
+	if len(badReasons) == 0 {
+		return nulls, nil
+	}
+
+	keep := make([]bool, rowCount)
+	var badRows []BadRow
+	for row := 0; row < rowCount; row++ {
+		reason, bad := badReasons[row]
+		if !bad {
+			keep[row] = true
+			continue
+		}
+
+		if conf.BadRowPolicy == BadRowFail {
+			return nil, qerrors.Parse.New("ReadCSV", "%s", reason).At(fields.Lines[row], 0)
+		}
+
+		if conf.BadRowPolicy == BadRowCollect {
+			badRows = append(badRows, BadRow{Line: fields.Lines[row], Encoded: fields.encodeRow(row, conf.Delimiter), Reason: reason})
+		}
+	}
+
+	for col := range fields.colPointers {
+		pointers := fields.colPointers[col][:0]
+		var colNulls []bool
+		hasNulls := false
+		for row, p := range fields.colPointers[col] {
+			if keep[row] {
+				pointers = append(pointers, p)
+				if nulls[col] != nil {
+					colNulls = append(colNulls, nulls[col][row])
+					hasNulls = hasNulls || nulls[col][row]
+				}
+			}
+		}
+		fields.colPointers[col] = pointers
+		nulls[col] = nil
+		if hasNulls {
+			nulls[col] = colNulls
+		}
+	}
+
+	lines := fields.Lines[:0]
+	for row, line := range fields.Lines {
+		if keep[row] {
+			lines = append(lines, line)
+		}
+	}
+	fields.Lines = lines
+
+	if conf.BadRows != nil {
+		*conf.BadRows = append(*conf.BadRows, badRows...)
+		sort.SliceStable(*conf.BadRows, func(i, j int) bool { return (*conf.BadRows)[i].Line < (*conf.BadRows)[j].Line })
+	}
+
+	return nulls, nil
+}
+
+func ReadCSV(reader io.Reader, conf CSVConfig) (map[string]interface{}, []string, error) {
+	fields, err := readCSVFields(reader, conf, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nulls := make([][]bool, len(fields.Headers))
+	if conf.BadRowPolicy != BadRowFail || conf.NullInvalid {
+		if nulls, err = checkFields(&fields, conf); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	headers := fields.Headers
+	var selected map[string]bool
+	if conf.Columns != nil {
+		selected = make(map[string]bool, len(conf.Columns))
+		for _, c := range conf.Columns {
+			selected[c] = true
+		}
+	}
+
 	dataMap := make(map[string]interface{}, len(headers))
+	var selectedHeaders []string
 	for i, header := range headers {
-		data, err := columnToData(colBytes[i], colPointers[i], header, conf)
+		if selected != nil && !selected[header] {
+			delete(conf.EnumVals, header)
+			continue
+		}
+		selectedHeaders = append(selectedHeaders, header)
+
+		col := i + 1
+		position := func(row int) (int, int) { return fields.Line(row), col }
+		data, err := columnToData(fields.colBytes[i], fields.colPointers[i], nulls[i], header, conf, position)
 		if err != nil {
 			return nil, nil, qerrors.Propagate("ReadCSV convert data", err)
 		}
@@ -123,7 +454,11 @@ func ReadCSV(reader io.Reader, conf CSVConfig) (map[string]interface{}, []string
 	}
 
 	if len(conf.EnumVals) > 0 {
-		return nil, nil, qerrors.New("ReadCsv", "Enum values specified for non enum column")
+		return nil, nil, qerrors.InvalidArgument.New("ReadCsv", "Enum values specified for non enum column")
+	}
+
+	if selected != nil {
+		headers = selectedHeaders
 	}
 
 	if len(headers) > len(dataMap) {
@@ -136,7 +471,7 @@ func ReadCSV(reader io.Reader, conf CSVConfig) (map[string]interface{}, []string
 				headerSet.Add(h)
 			}
 		}
-		return nil, nil, qerrors.New("ReadCsv", "Duplicate columns detected: %v", duplicates)
+		return nil, nil, qerrors.InvalidArgument.New("ReadCsv", "Duplicate columns detected: %v", duplicates)
 	}
 	return dataMap, headers, nil
 }
@@ -205,10 +540,53 @@ func addAliasToMissingColumnNames(headers []string, alias string) []string {
 	return headers
 }
 
-// Convert bytes to data columns, try, in turn int, float, bool and last string.
-func columnToData(bytes []byte, pointers []bytePointer, colName string, conf CSVConfig) (interface{}, error) {
+// CSVDetectTypes are the types tried, in order, when detecting the type of
+// a CSV column. The first type that all fields of the column fit is used.
+var CSVDetectTypes = []types.DataType{types.Int, types.Float, types.Bool, types.String}
+
+func parseIntField(b []byte) (int, error) {
+	return strings.ParseInt(b)
+}
+
+// parseFloatField parses a float field, empty fields are read as NaN.
+func parseFloatField(b []byte) (float64, error) {
+	if len(b) == 0 {
+		return math.NaN(), nil
+	}
+	return strings.ParseFloat(b)
+}
+
+func parseBoolField(b []byte) (bool, error) {
+	return strings.ParseBool(b)
+}
+
+// CSVFieldFits reports whether field can be read as a value of type t
+// when detecting the type of a CSV column. All fields fit string.
+func CSVFieldFits(t types.DataType, field []byte) bool {
+	var err error
+	switch t {
+	case types.Int:
+		_, err = parseIntField(field)
+	case types.Float:
+		_, err = parseFloatField(field)
+	case types.Bool:
+		_, err = parseBoolField(field)
+	}
+	return err == nil
+}
+
+// Convert bytes to data columns, try, in turn, the CSVDetectTypes.
+// columnToData converts the fields of a column into column data. Fields
+// marked in nulls, if not nil, are converted into missing values. Int
+// columns with missing values are converted into float columns. position
+// returns the line and column in the input of a row, it is used to locate
+// fields that cannot be converted.
+func columnToData(bytes []byte, pointers []bytePointer, nulls []bool, colName string, conf CSVConfig, position func(row int) (int, int)) (interface{}, error) {
 	var err error
 	dataType := conf.Types[colName]
+	if dataType == types.Int && nulls != nil {
+		dataType = types.Float
+	}
 
 	if len(pointers) == 0 && dataType == types.None {
 		return ncolumn.Column{}, nil
@@ -217,7 +595,7 @@ func columnToData(bytes []byte, pointers []bytePointer, colName string, conf CSV
 	if dataType == types.Int || dataType == types.None {
 		intData := make([]int, 0, len(pointers))
 		for _, p := range pointers {
-			x, intErr := strings.ParseInt(bytes[p.start:p.end])
+			x, intErr := parseIntField(bytes[p.start:p.end])
 			if intErr != nil {
 				err = intErr
 				break
@@ -230,20 +608,20 @@ func columnToData(bytes []byte, pointers []bytePointer, colName string, conf CSV
 		}
 
 		if dataType == types.Int {
-			return nil, qerrors.Propagate("Create int column", err)
+			return nil, qerrors.Parse.Propagate("Create int column", err).At(position(len(intData)))
 		}
 	}
 
 	if dataType == types.Float || dataType == types.None {
 		err = nil
 		floatData := make([]float64, 0, len(pointers))
-		for _, p := range pointers {
-			if p.start == p.end {
+		for i, p := range pointers {
+			if nulls != nil && nulls[i] {
 				floatData = append(floatData, math.NaN())
 				continue
 			}
 
-			x, floatErr := strings.ParseFloat(bytes[p.start:p.end])
+			x, floatErr := parseFloatField(bytes[p.start:p.end])
 			if floatErr != nil {
 				err = floatErr
 				break
@@ -256,7 +634,7 @@ func columnToData(bytes []byte, pointers []bytePointer, colName string, conf CSV
 		}
 
 		if dataType == types.Float {
-			return nil, qerrors.Propagate("Create float column", err)
+			return nil, qerrors.Parse.Propagate("Create float column", err).At(position(len(floatData)))
 		}
 	}
 
@@ -264,7 +642,7 @@ func columnToData(bytes []byte, pointers []bytePointer, colName string, conf CSV
 		err = nil
 		boolData := make([]bool, 0, len(pointers))
 		for _, p := range pointers {
-			x, boolErr := strings.ParseBool(bytes[p.start:p.end])
+			x, boolErr := parseBoolField(bytes[p.start:p.end])
 			if boolErr != nil {
 				err = boolErr
 				break
@@ -277,7 +655,7 @@ func columnToData(bytes []byte, pointers []bytePointer, colName string, conf CSV
 		}
 
 		if dataType == types.Bool {
-			return nil, qerrors.Propagate("Create bool column", err)
+			return nil, qerrors.Parse.Propagate("Create bool column", err).At(position(len(boolData)))
 		}
 	}
 
@@ -302,13 +680,13 @@ func columnToData(bytes []byte, pointers []bytePointer, colName string, conf CSV
 			return nil, err
 		}
 
-		for _, p := range pointers {
-			if p.start == p.end && conf.EmptyNull {
+		for i, p := range pointers {
+			if (p.start == p.end && conf.EmptyNull) || (nulls != nil && nulls[i]) {
 				factory.AppendNil()
 			} else {
 				err := factory.AppendByteString(bytes[p.start:p.end])
 				if err != nil {
-					return nil, qerrors.Propagate("Create column", err)
+					return nil, qerrors.Parse.Propagate("Create column", err).At(position(i))
 				}
 			}
 		}
@@ -316,5 +694,169 @@ func columnToData(bytes []byte, pointers []bytePointer, colName string, conf CSV
 		return factory.ToColumn(), nil
 	}
 
-	return nil, qerrors.New("Create column", "unknown data type: %s", dataType)
+	return nil, qerrors.InvalidArgument.New("Create column", "unknown data type: %s", dataType)
+}
+
+// cellWriter appends the value at position i of a column view to buf.
+// isNull is true if the value is null, in which case nothing is appended.
+type cellWriter func(buf []byte, i int) (result []byte, isNull bool)
+
+func newCellWriter(col column.Column, ix index.Int, conf ToCsvConfig) (writer cellWriter, numeric bool) {
+	switch c := col.(type) {
+	case icolumn.Column:
+		v := c.View(ix)
+		return func(buf []byte, i int) ([]byte, bool) {
+			return strconv.AppendInt(buf, int64(v.ItemAt(i)), 10), false
+		}, true
+	case fcolumn.Column:
+		v := c.View(ix)
+		return func(buf []byte, i int) ([]byte, bool) {
+			f := v.ItemAt(i)
+			if math.IsNaN(f) {
+				return buf, true
+			}
+			return strconv.AppendFloat(buf, f, 'f', conf.FloatPrecision, 64), false
+		}, true
+	case bcolumn.Column:
+		v := c.View(ix)
+		return func(buf []byte, i int) ([]byte, bool) {
+			return strconv.AppendBool(buf, v.ItemAt(i)), false
+		}, false
+	case scolumn.Column:
+		v := c.View(ix)
+		return func(buf []byte, i int) ([]byte, bool) {
+			s := v.ItemAt(i)
+			if s == nil {
+				return buf, true
+			}
+			return append(buf, *s...), false
+		}, false
+	case ecolumn.Column:
+		v := c.View(ix)
+		return func(buf []byte, i int) ([]byte, bool) {
+			s := v.ItemAt(i)
+			if s == nil {
+				return buf, true
+			}
+			return append(buf, *s...), false
+		}, false
+	case ncolumn.Column:
+		return func(buf []byte, _ int) ([]byte, bool) {
+			return buf, true
+		}, false
+	}
+
+	return func(buf []byte, i int) ([]byte, bool) {
+		return append(buf, col.StringAt(ix[i], "")...), false
+	}, false
+}
+
+// fieldNeedsQuotes reports whether field must be quoted to be read back correctly.
+// The rules are the same as those used by the stdlib CSV writer with the addition
+// that a field equal to the null representation must also be quoted to distinguish
+// it from null.
+func fieldNeedsQuotes(field []byte, conf ToCsvConfig) bool {
+	if len(field) == 0 {
+		return false
+	}
+
+	if conf.NullRep != "" && string(field) == conf.NullRep {
+		return true
+	}
+
+	for _, b := range field {
+		if b == conf.Delimiter || b == '"' || b == '\r' || b == '\n' {
+			return true
+		}
+	}
+
+	r, _ := utf8.DecodeRune(field)
+	return r == ' ' || r == '\t'
+}
+
+// appendField appends field to buf, quoting it if required by the quoting policy.
+func appendField(buf, field []byte, numeric bool, conf ToCsvConfig) []byte {
+	var quote bool
+	switch conf.Quoting {
+	case QuoteAll:
+		quote = true
+	case QuoteNonNumeric:
+		quote = !numeric || fieldNeedsQuotes(field, conf)
+	default:
+		quote = fieldNeedsQuotes(field, conf)
+	}
+
+	if !quote {
+		return append(buf, field...)
+	}
+
+	buf = append(buf, '"')
+	for _, b := range field {
+		if b == '"' {
+			buf = append(buf, '"')
+		}
+		buf = append(buf, b)
+	}
+	return append(buf, '"')
+}
+
+// WriteCSV writes the rows identified by ix in cols to writer.
+func WriteCSV(writer io.Writer, headers []string, cols []column.Column, ix index.Int, conf ToCsvConfig) error {
+	cw, err := Compress(writer, conf.Compression)
+	if err != nil {
+		return qerrors.Propagate("WriteCSV", err)
+	}
+
+	w := bufio.NewWriter(cw)
+	var row []byte
+	if conf.Header {
+		for i, h := range headers {
+			if i > 0 {
+				row = append(row, conf.Delimiter)
+			}
+			row = appendField(row, []byte(h), false, conf)
+		}
+		row = append(row, conf.LineTerminator...)
+		if _, err := w.Write(row); err != nil {
+			return qerrors.IO.Propagate("WriteCSV header", err)
+		}
+	}
+
+	writers := make([]cellWriter, len(cols))
+	numerics := make([]bool, len(cols))
+	for i, col := range cols {
+		writers[i], numerics[i] = newCellWriter(col, ix, conf)
+	}
+
+	var field []byte
+	for i := range ix {
+		row = row[:0]
+		for j, cw := range writers {
+			if j > 0 {
+				row = append(row, conf.Delimiter)
+			}
+
+			var isNull bool
+			field, isNull = cw(field[:0], i)
+			if isNull {
+				row = append(row, conf.NullRep...)
+			} else {
+				row = appendField(row, field, numerics[j], conf)
+			}
+		}
+		row = append(row, conf.LineTerminator...)
+		if _, err := w.Write(row); err != nil {
+			return qerrors.IO.Propagate("WriteCSV body", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return qerrors.IO.Propagate("WriteCSV flush", err)
+	}
+
+	if err := cw.Close(); err != nil {
+		return qerrors.IO.Propagate("WriteCSV close", err)
+	}
+
+	return nil
 }
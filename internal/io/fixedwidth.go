@@ -0,0 +1,135 @@
+package io
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/tobgu/qframe/qerrors"
+	"github.com/tobgu/qframe/types"
+)
+
+// FixedWidthColumn describes the location and type of a column in a fixed width file.
+type FixedWidthColumn struct {
+	Name  string
+	Start int
+	Width int
+	Type  types.DataType
+}
+
+// For reading fixed width files
+type FixedWidthConfig struct {
+	Columns          []FixedWidthColumn
+	EnumVals         map[string][]string
+	EmptyNull        bool
+	IgnoreEmptyLines bool
+	SkipLines        int
+	Compression      types.Compression
+}
+
+func checkFixedWidthColumns(columns []FixedWidthColumn) error {
+	if len(columns) == 0 {
+		return qerrors.InvalidArgument.New("ReadFixedWidth", "no columns specified")
+	}
+
+	names := make(map[string]struct{}, len(columns))
+	for _, c := range columns {
+		if c.Start < 0 || c.Width <= 0 {
+			return qerrors.InvalidArgument.New("ReadFixedWidth", "invalid position for column %s, start: %d, width: %d", c.Name, c.Start, c.Width)
+		}
+
+		if _, ok := names[c.Name]; ok {
+			return qerrors.InvalidArgument.New("ReadFixedWidth", "Duplicate columns detected: %s", c.Name)
+		}
+		names[c.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+// fixedWidthField returns the, space trimmed, content of line at [start, start+width[.
+// Lines that are shorter than the field are tolerated, a missing field is empty.
+func fixedWidthField(line []byte, start, width int) []byte {
+	if start >= len(line) {
+		return nil
+	}
+
+	end := start + width
+	if end > len(line) {
+		end = len(line)
+	}
+
+	return bytes.Trim(line[start:end], " ")
+}
+
+func ReadFixedWidth(reader io.Reader, conf FixedWidthConfig) (map[string]interface{}, []string, error) {
+	if err := checkFixedWidthColumns(conf.Columns); err != nil {
+		return nil, nil, err
+	}
+
+	reader, release, err := Decompress(reader, conf.Compression)
+	if err != nil {
+		return nil, nil, qerrors.Propagate("ReadFixedWidth", err)
+	}
+	defer release()
+
+	colPointers := make([][]bytePointer, len(conf.Columns))
+	colBytes := make([][]byte, len(conf.Columns))
+	var lines []int
+
+	r := bufio.NewReader(reader)
+	for lineNo := 1; ; lineNo++ {
+		line, readErr := r.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, nil, qerrors.IO.Propagate("ReadFixedWidth read body", readErr)
+		}
+
+		if len(line) == 0 && readErr == io.EOF {
+			break
+		}
+
+		line = bytes.TrimSuffix(line, []byte{'\n'})
+		line = bytes.TrimSuffix(line, []byte{'\r'})
+		skip := lineNo <= conf.SkipLines || (len(line) == 0 && conf.IgnoreEmptyLines)
+		if !skip {
+			for i, c := range conf.Columns {
+				field := fixedWidthField(line, c.Start, c.Width)
+				start := len(colBytes[i])
+				colBytes[i] = append(colBytes[i], field...)
+				colPointers[i] = append(colPointers[i], bytePointer{start: uint32(start), end: uint32(len(colBytes[i]))})
+			}
+			lines = append(lines, lineNo)
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	// Type detection and conversion is shared with the CSV reader
+	typeConf := CSVConfig{EmptyNull: conf.EmptyNull, Types: map[string]types.DataType{}, EnumVals: map[string][]string{}}
+	for k, v := range conf.EnumVals {
+		typeConf.EnumVals[k] = v
+	}
+
+	headers := make([]string, len(conf.Columns))
+	dataMap := make(map[string]interface{}, len(conf.Columns))
+	for i, c := range conf.Columns {
+		headers[i] = c.Name
+		typeConf.Types[c.Name] = c.Type
+		col := c.Start + 1
+		position := func(row int) (int, int) { return lines[row], col }
+		data, err := columnToData(colBytes[i], colPointers[i], nil, c.Name, typeConf, position)
+		if err != nil {
+			return nil, nil, qerrors.Propagate("ReadFixedWidth convert data", err)
+		}
+
+		dataMap[c.Name] = data
+	}
+
+	if len(typeConf.EnumVals) > 0 {
+		return nil, nil, qerrors.InvalidArgument.New("ReadFixedWidth", "Enum values specified for non enum column")
+	}
+
+	return dataMap, headers, nil
+}
@@ -2,10 +2,29 @@ package io
 
 import (
 	"encoding/json"
-	"github.com/tobgu/qframe/qerrors"
+	"errors"
 	"io"
+
+	"github.com/tobgu/qframe/qerrors"
+	"github.com/tobgu/qframe/types"
 )
 
+// For reading JSON
+type JSONConfig struct {
+	Compression types.Compression
+	ColumnOrder []string
+	EnumColumns map[string][]string
+
+	// Schema is a qframe.Schema that the result is coerced into after
+	// reading, if not nil. It is opaque to the reader.
+	Schema interface{}
+}
+
+// For writing JSON
+type ToJSONConfig struct {
+	Compression types.Compression
+}
+
 type JSONRecords []map[string]interface{}
 
 type JSONColumns map[string]json.RawMessage
@@ -15,12 +34,12 @@ func fillInts(col []int, records JSONRecords, colName string) error {
 		record := records[i]
 		value, ok := record[colName]
 		if !ok {
-			return qerrors.New("fillInts", "missing value for column %s, row %d", colName, i)
+			return qerrors.Parse.New("fillInts", "missing value for column %s, row %d", colName, i)
 		}
 
 		intValue, ok := value.(int)
 		if !ok {
-			return qerrors.New("fillInts", "wrong type for column %s, row %d, expected int", colName, i)
+			return qerrors.TypeMismatch.New("fillInts", "wrong type for column %s, row %d, expected int", colName, i)
 		}
 		col[i] = intValue
 	}
@@ -33,12 +52,12 @@ func fillFloats(col []float64, records JSONRecords, colName string) error {
 		record := records[i]
 		value, ok := record[colName]
 		if !ok {
-			return qerrors.New("fillFloats", "missing value for column %s, row %d", colName, i)
+			return qerrors.Parse.New("fillFloats", "missing value for column %s, row %d", colName, i)
 		}
 
 		floatValue, ok := value.(float64)
 		if !ok {
-			return qerrors.New("fillFloats", "wrong type for column %s, row %d, expected float", colName, i)
+			return qerrors.TypeMismatch.New("fillFloats", "wrong type for column %s, row %d, expected float", colName, i)
 		}
 		col[i] = floatValue
 	}
@@ -51,12 +70,12 @@ func fillBools(col []bool, records JSONRecords, colName string) error {
 		record := records[i]
 		value, ok := record[colName]
 		if !ok {
-			return qerrors.New("fillBools", "wrong type for column %s, row %d", colName, i)
+			return qerrors.TypeMismatch.New("fillBools", "wrong type for column %s, row %d", colName, i)
 		}
 
 		boolValue, ok := value.(bool)
 		if !ok {
-			return qerrors.New("fillBools", "wrong type for column %s, row %d, expected bool", colName, i)
+			return qerrors.TypeMismatch.New("fillBools", "wrong type for column %s, row %d, expected bool", colName, i)
 		}
 		col[i] = boolValue
 	}
@@ -69,7 +88,7 @@ func fillStrings(col []*string, records JSONRecords, colName string) error {
 		record := records[i]
 		value, ok := record[colName]
 		if !ok {
-			return qerrors.New("fillStrings", "wrong type for column %s, row %d", colName, i)
+			return qerrors.TypeMismatch.New("fillStrings", "wrong type for column %s, row %d", colName, i)
 		}
 
 		switch t := value.(type) {
@@ -78,7 +97,7 @@ func fillStrings(col []*string, records JSONRecords, colName string) error {
 		case nil:
 			col[i] = nil
 		default:
-			return qerrors.New("fillStrings", "wrong type for column %s, row %d, expected int", colName, i)
+			return qerrors.TypeMismatch.New("fillStrings", "wrong type for column %s, row %d, expected int", colName, i)
 		}
 	}
 
@@ -119,21 +138,60 @@ func jsonRecordsToData(records JSONRecords) (map[string]interface{}, error) {
 			}
 			result[colName] = col
 		default:
-			return nil, qerrors.New("jsonRecordsToData", "unknown type of %s", t)
+			return nil, qerrors.TypeMismatch.New("jsonRecordsToData", "unknown type of %s", t)
 		}
 	}
 	return result, nil
 }
 
 // UnmarshalJSON transforms JSON containing data records or columns into a map of columns
-// that can be used to create a QFrame.
-func UnmarshalJSON(r io.Reader) (map[string]interface{}, error) {
+// that can be used to create a QFrame. The input is decompressed as configured by
+// conf.Compression.
+func UnmarshalJSON(r io.Reader, conf JSONConfig) (map[string]interface{}, error) {
+	r, release, err := Decompress(r, conf.Compression)
+	if err != nil {
+		return nil, qerrors.Propagate("UnmarshalJSON", err)
+	}
+	defer release()
+
+	records, err := decodeJSONRecords(r)
+	if err != nil {
+		return nil, decodeErr("UnmarshalJSON", err)
+	}
+
+	return jsonRecordsToData(records)
+}
+
+func decodeJSONRecords(r io.Reader) (JSONRecords, error) {
 	var records JSONRecords
 	decoder := json.NewDecoder(r)
 	err := decoder.Decode(&records)
+	return records, err
+}
+
+// decodeErr categorizes an error from decoding JSON as a parse error, if
+// caused by the content of the input, or an IO error.
+func decodeErr(operation string, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || err == io.EOF || err == io.ErrUnexpectedEOF {
+		return qerrors.Parse.Propagate(operation, err)
+	}
+	return qerrors.IO.Propagate(operation, err)
+}
+
+// ReadJSONRecords decodes JSON containing data records without converting
+// them into columns. Compressed input is detected and decompressed automatically.
+func ReadJSONRecords(r io.Reader) (JSONRecords, error) {
+	r, release, err := Decompress(r, types.CompressionAuto)
 	if err != nil {
-		return nil, qerrors.Propagate("UnmarshalJSON", err)
+		return nil, qerrors.Propagate("ReadJSONRecords", err)
 	}
+	defer release()
 
-	return jsonRecordsToData(records)
+	records, err := decodeJSONRecords(r)
+	if err != nil {
+		return nil, decodeErr("ReadJSONRecords", err)
+	}
+	return records, nil
 }
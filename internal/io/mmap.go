@@ -0,0 +1,59 @@
+package io
+
+import (
+	"os"
+	"sync"
+
+	"github.com/tobgu/qframe/qerrors"
+)
+
+// MappedFile holds the content of a file mapped into memory.
+type MappedFile struct {
+	Data []byte
+
+	once     sync.Once
+	closeErr error
+}
+
+// MapFile maps the file at path into memory, read only. On platforms that
+// do not support memory mapping the file content is read into memory instead.
+func MapFile(path string) (*MappedFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, qerrors.IO.Propagate("MapFile", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, qerrors.IO.Propagate("MapFile", err)
+	}
+
+	size := info.Size()
+	if size == 0 {
+		return &MappedFile{Data: []byte{}}, nil
+	}
+
+	if int64(int(size)) != size {
+		return nil, qerrors.IO.New("MapFile", "file too large: %d bytes", size)
+	}
+
+	data, err := mapFile(f, int(size))
+	if err != nil {
+		return nil, qerrors.IO.Propagate("MapFile", err)
+	}
+
+	return &MappedFile{Data: data}, nil
+}
+
+// Close releases the mapping. Data must not be accessed after Close has been
+// called. Calling Close more than once is a no-op.
+func (m *MappedFile) Close() error {
+	m.once.Do(func() {
+		if len(m.Data) > 0 {
+			m.closeErr = unmapFile(m.Data)
+		}
+		m.Data = nil
+	})
+	return m.closeErr
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package io
+
+import (
+	"io"
+	"os"
+)
+
+func mapFile(f *os.File, size int) ([]byte, error) {
+	data := make([]byte, size)
+	_, err := io.ReadFull(f, data)
+	return data, err
+}
+
+func unmapFile(_ []byte) error {
+	return nil
+}
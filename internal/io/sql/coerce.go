@@ -19,7 +19,7 @@ func Int64ToBool(c *Column) func(t interface{}) error {
 	return func(t interface{}) error {
 		v, ok := t.(int64)
 		if !ok {
-			return qerrors.New(
+			return qerrors.TypeMismatch.New(
 				"Coercion Int64ToBool", "type %s is not int64", reflect.TypeOf(t).Kind())
 		}
 		c.Bool(v != 0)
@@ -31,12 +31,12 @@ func StringToFloat(c *Column) func(t interface{}) error {
 	return func(t interface{}) error {
 		v, ok := t.(string)
 		if !ok {
-			return qerrors.New(
+			return qerrors.TypeMismatch.New(
 				"Coercion StringToFloat", "type %s is not float", reflect.TypeOf(t).Kind())
 		}
 		f, err := strconv.ParseFloat(v, 64)
 		if err != nil {
-			return qerrors.New(
+			return qerrors.Parse.New(
 				"Coercion StringToFloat", "Could not convert %s", v)
 		}
 		c.Float(f)
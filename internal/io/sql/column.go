@@ -3,6 +3,7 @@ package sql
 import (
 	"math"
 	"reflect"
+	"strconv"
 
 	"github.com/tobgu/qframe/internal/math/float"
 
@@ -26,6 +27,33 @@ type Column struct {
 	}
 	coerce    func(t interface{}) error
 	precision int
+	// typed is set when the type of the column has
+	// been decided up front, from the column type
+	// metadata, rather than inferred from the data.
+	typed bool
+	// enum is set if the column should be turned
+	// into an enum column.
+	enum bool
+	// fixed is set if the type of the column must not
+	// change, eg. by promoting ints to floats.
+	fixed bool
+}
+
+// setKind sets the type of the column before
+// any data has been scanned.
+func (c *Column) setKind(kind reflect.Kind) {
+	c.kind = kind
+	c.typed = true
+	switch kind {
+	case reflect.Int:
+		c.ptr = &c.data.Ints
+	case reflect.Float64:
+		c.ptr = &c.data.Floats
+	case reflect.Bool:
+		c.ptr = &c.data.Bools
+	case reflect.String:
+		c.ptr = &c.data.Strings
+	}
 }
 
 // Null appends a new Null value to
@@ -41,16 +69,36 @@ func (c *Column) Null() error {
 		return nil
 	}
 	switch c.kind {
+	case reflect.Int:
+		if c.fixed {
+			return qerrors.TypeMismatch.New("Column Null", "NULL value in int column of fixed type")
+		}
+		// Ints cannot represent NULL, promote the
+		// column to float and use NaN instead.
+		c.toFloat()
+		c.data.Floats = append(c.data.Floats, math.NaN())
 	case reflect.Float64:
 		c.data.Floats = append(c.data.Floats, math.NaN())
 	case reflect.String:
 		c.data.Strings = append(c.data.Strings, nil)
 	default:
-		return qerrors.New("Column Null", "non-nullable type: %s", c.kind)
+		return qerrors.TypeMismatch.New("Column Null", "non-nullable type: %s", c.kind)
 	}
 	return nil
 }
 
+// toFloat converts the int data of the column to floats.
+func (c *Column) toFloat() {
+	floats := make([]float64, len(c.data.Ints), len(c.data.Ints)+1)
+	for i, x := range c.data.Ints {
+		floats[i] = float64(x)
+	}
+	c.data.Ints = nil
+	c.data.Floats = floats
+	c.kind = reflect.Float64
+	c.ptr = &c.data.Floats
+}
+
 // Int adds a new int to the underlying data slice
 func (c *Column) Int(i int) {
 	if c.ptr == nil {
@@ -104,11 +152,93 @@ func (c *Column) Bool(b bool) {
 	c.data.Bools = append(c.data.Bools, b)
 }
 
+func (c *Column) scanErr(t interface{}) error {
+	return qerrors.TypeMismatch.New(
+		"Column Scan", "cannot scan type %s into %s column", reflect.ValueOf(t).Kind(), c.kind)
+}
+
+// scanTyped converts scanned values into the
+// type decided up front for the column.
+func (c *Column) scanTyped(t interface{}) error {
+	if t == nil {
+		return c.Null()
+	}
+
+	if b, ok := t.([]uint8); ok {
+		t = string(b)
+	}
+
+	switch c.kind {
+	case reflect.Int:
+		switch v := t.(type) {
+		case int64:
+			c.Int(int(v))
+		case string:
+			i, err := strconv.Atoi(v)
+			if err != nil {
+				return qerrors.Parse.Propagate("Column Scan", err)
+			}
+			c.Int(i)
+		default:
+			return c.scanErr(t)
+		}
+	case reflect.Float64:
+		switch v := t.(type) {
+		case float64:
+			c.Float(v)
+		case int64:
+			c.Float(float64(v))
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return qerrors.Parse.Propagate("Column Scan", err)
+			}
+			c.Float(f)
+		default:
+			return c.scanErr(t)
+		}
+	case reflect.Bool:
+		switch v := t.(type) {
+		case bool:
+			c.Bool(v)
+		case int64:
+			c.Bool(v != 0)
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return qerrors.Parse.Propagate("Column Scan", err)
+			}
+			c.Bool(b)
+		default:
+			return c.scanErr(t)
+		}
+	case reflect.String:
+		switch v := t.(type) {
+		case string:
+			c.String(v)
+		case int64:
+			c.String(strconv.FormatInt(v, 10))
+		case float64:
+			c.String(strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			c.String(strconv.FormatBool(v))
+		default:
+			return c.scanErr(t)
+		}
+	default:
+		return c.scanErr(t)
+	}
+	return nil
+}
+
 // Scan implements the sql.Scanner interface
 func (c *Column) Scan(t interface{}) error {
 	if c.coerce != nil {
 		return c.coerce(t)
 	}
+	if c.typed {
+		return c.scanTyped(t)
+	}
 	switch v := t.(type) {
 	case bool:
 		c.Bool(v)
@@ -126,7 +256,7 @@ func (c *Column) Scan(t interface{}) error {
 			return err
 		}
 	default:
-		return qerrors.New(
+		return qerrors.TypeMismatch.New(
 			"Column Scan", "unsupported scan type: %s", reflect.ValueOf(t).Kind())
 	}
 	return nil
@@ -2,6 +2,7 @@ package sql
 
 import (
 	"math"
+	"reflect"
 	"testing"
 )
 
@@ -66,6 +67,36 @@ func TestColumnCoercion(t *testing.T) {
 	assertEqual(t, false, data[3])
 }
 
+func TestColumnTyped(t *testing.T) {
+	// Int column promoted to float on NULL
+	col := &Column{}
+	col.setKind(reflect.Int)
+	panicOnErr(col.Scan(int64(1)))
+	panicOnErr(col.Scan(nil))
+	panicOnErr(col.Scan([]byte("3")))
+	data := col.Data().([]float64)
+	assertEqual(t, 3, len(data))
+	assertEqual(t, 1.0, data[0])
+	assertEqual(t, true, math.IsNaN(data[1]))
+	assertEqual(t, 3.0, data[2])
+
+	// Bool column from ints
+	col = &Column{}
+	col.setKind(reflect.Bool)
+	panicOnErr(col.Scan(int64(1)))
+	panicOnErr(col.Scan(int64(0)))
+	bools := col.Data().([]bool)
+	assertEqual(t, true, bools[0])
+	assertEqual(t, false, bools[1])
+
+	// Unparsable value
+	col = &Column{}
+	col.setKind(reflect.Float64)
+	if col.Scan("abc") == nil {
+		t.Error("expected error")
+	}
+}
+
 func BenchmarkColumn(b *testing.B) {
 	col := &Column{}
 	for n := 0; n < b.N; n++ {
@@ -0,0 +1,142 @@
+package sql
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/tobgu/qframe/internal/column"
+	"github.com/tobgu/qframe/internal/ecolumn"
+	"github.com/tobgu/qframe/types"
+)
+
+func quoteLiteral(s string, buf *bytes.Buffer) {
+	buf.WriteString("'")
+	buf.WriteString(strings.ReplaceAll(s, "'", "''"))
+	buf.WriteString("'")
+}
+
+func enumValues(col column.Column) []string {
+	if c, ok := col.(ecolumn.Column); ok {
+		_, values, _ := c.Raw()
+		return values
+	}
+	return nil
+}
+
+// sqlType writes the SQL type of col. Enums are written as
+// ENUM for MySQL, other dialects get a text column with a
+// CHECK constraint limiting the allowed values. Enums without
+// values are written as text columns in all dialects.
+func sqlType(name string, col column.Column, isKey bool, conf SQLConfig, buf *bytes.Buffer) {
+	switch col.DataType() {
+	case types.Int:
+		if conf.Dialect == SQLiteDialect {
+			buf.WriteString("INTEGER")
+		} else {
+			buf.WriteString("BIGINT")
+		}
+	case types.Float:
+		switch conf.Dialect {
+		case SQLiteDialect:
+			buf.WriteString("REAL")
+		case MySQLDialect:
+			buf.WriteString("DOUBLE")
+		default:
+			buf.WriteString("DOUBLE PRECISION")
+		}
+	case types.Bool:
+		buf.WriteString("BOOLEAN")
+	case types.Enum:
+		values := enumValues(col)
+		if len(values) == 0 {
+			// ENUM() is not valid in MySQL
+			textType(isKey, conf, buf)
+			return
+		}
+
+		if conf.Dialect == MySQLDialect {
+			buf.WriteString("ENUM(")
+			for i, v := range values {
+				if i > 0 {
+					buf.WriteString(",")
+				}
+				quoteLiteral(v, buf)
+			}
+			buf.WriteString(")")
+			return
+		}
+
+		buf.WriteString("TEXT CHECK (")
+		escape(name, conf.EscapeChar, buf)
+		buf.WriteString(" IN (")
+		for i, v := range values {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			quoteLiteral(v, buf)
+		}
+		buf.WriteString("))")
+	default:
+		textType(isKey, conf, buf)
+	}
+}
+
+func textType(isKey bool, conf SQLConfig, buf *bytes.Buffer) {
+	// MySQL cannot index TEXT columns without a prefix length
+	if conf.Dialect == MySQLDialect && isKey {
+		buf.WriteString("VARCHAR(255)")
+	} else {
+		buf.WriteString("TEXT")
+	}
+}
+
+// nullable reports if col can hold null values.
+func nullable(col column.Column) bool {
+	switch col.DataType() {
+	case types.Int, types.Bool:
+		return false
+	default:
+		return true
+	}
+}
+
+// CreateTable generates a SQL CREATE TABLE statement
+// with a column per colName using the type of the
+// corresponding column in cols. Columns of types that
+// cannot represent null are declared NOT NULL as are
+// primary key columns.
+func CreateTable(colNames []string, cols []column.Column, conf SQLConfig) string {
+	isKey := make(map[string]bool, len(conf.PrimaryKey))
+	for _, k := range conf.PrimaryKey {
+		isKey[k] = true
+	}
+
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString("CREATE TABLE ")
+	escape(conf.Table, conf.EscapeChar, buf)
+	buf.WriteString(" (")
+	for i, name := range colNames {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		escape(name, conf.EscapeChar, buf)
+		buf.WriteString(" ")
+		sqlType(name, cols[i], isKey[name], conf, buf)
+		if !nullable(cols[i]) || isKey[name] {
+			buf.WriteString(" NOT NULL")
+		}
+	}
+
+	if len(conf.PrimaryKey) > 0 {
+		buf.WriteString(",PRIMARY KEY (")
+		for i, name := range conf.PrimaryKey {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			escape(name, conf.EscapeChar, buf)
+		}
+		buf.WriteString(")")
+	}
+	buf.WriteString(");")
+	return buf.String()
+}
@@ -2,60 +2,295 @@ package sql
 
 import (
 	"database/sql"
+	"reflect"
+	"strings"
 
+	"github.com/tobgu/qframe/internal/ecolumn"
 	"github.com/tobgu/qframe/qerrors"
 	"github.com/tobgu/qframe/types"
 )
 
-// ReadSQL returns a named map of types.DataSlice for consumption
-// by the qframe.New constructor.
-func ReadSQL(rows *sql.Rows, conf SQLConfig) (map[string]types.DataSlice, []string, error) {
-	var (
-		columns  []interface{}
-		colNames []string
-	)
-	for rows.Next() {
-		// Allocate columns for the returning query
-		if columns == nil {
-			names, err := rows.Columns()
-			if err != nil {
-				return nil, colNames, qerrors.New("ReadSQL Columns", err.Error())
-			}
-			for _, name := range names {
-				col := &Column{precision: conf.Precision}
-				if conf.CoerceMap != nil {
-					fn, ok := conf.CoerceMap[name]
-					if ok {
-						col.coerce = fn(col)
-					}
-				}
-				columns = append(columns, col)
-			}
-			// ensure any column in the coercion map
-			// exists in the resulting columns or return
-			// an error explicitly.
-			if conf.CoerceMap != nil {
-			checkMap:
-				for name := range conf.CoerceMap {
-					for _, colName := range colNames {
-						if name == colName {
-							continue checkMap
-						}
-						return nil, colNames, qerrors.New("ReadSQL Columns", "column %s does not exist to coerce", name)
-					}
-				}
-			}
-			colNames = names
+var (
+	intTypeNames = map[string]bool{
+		"INT": true, "INT2": true, "INT4": true, "INT8": true, "INTEGER": true,
+		"TINYINT": true, "SMALLINT": true, "MEDIUMINT": true, "BIGINT": true,
+		"SERIAL": true, "SMALLSERIAL": true, "BIGSERIAL": true,
+	}
+	floatTypeNames = map[string]bool{
+		"FLOAT": true, "FLOAT4": true, "FLOAT8": true, "REAL": true,
+		"DOUBLE": true, "DOUBLE PRECISION": true, "NUMERIC": true, "DECIMAL": true,
+	}
+	boolTypeNames = map[string]bool{
+		"BOOL": true, "BOOLEAN": true,
+	}
+	stringTypeNames = map[string]bool{
+		"TEXT": true, "VARCHAR": true, "CHAR": true, "BPCHAR": true, "NVARCHAR": true,
+		"NCHAR": true, "CHARACTER": true, "CHARACTER VARYING": true, "CLOB": true,
+		"TINYTEXT": true, "MEDIUMTEXT": true, "LONGTEXT": true, "ENUM": true,
+		"UUID": true, "CITEXT": true,
+	}
+)
+
+// typeName normalizes a database type name, dropping
+// any size or precision arguments, eg. VARCHAR(10) -> VARCHAR.
+func typeName(name string) string {
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		name = name[:i]
+	}
+	return strings.ToUpper(strings.TrimSpace(name))
+}
+
+// scanKind returns the column kind matching the Go type
+// that the driver scans values of a column into.
+func scanKind(t reflect.Type) reflect.Kind {
+	if t == nil {
+		return reflect.Invalid
+	}
+
+	switch t {
+	case reflect.TypeOf(sql.NullInt64{}), reflect.TypeOf(sql.NullInt32{}), reflect.TypeOf(sql.NullInt16{}):
+		return reflect.Int
+	case reflect.TypeOf(sql.NullFloat64{}):
+		return reflect.Float64
+	case reflect.TypeOf(sql.NullBool{}):
+		return reflect.Bool
+	case reflect.TypeOf(sql.NullString{}):
+		return reflect.String
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return reflect.Int
+	case reflect.Float32, reflect.Float64:
+		return reflect.Float64
+	case reflect.Bool:
+		return reflect.Bool
+	case reflect.String:
+		return reflect.String
+	}
+	return reflect.Invalid
+}
+
+// columnKind returns the kind of column to allocate based on the
+// column type metadata reported by the driver. reflect.Invalid is
+// returned if the type is not known in which case the type is
+// inferred from the scanned data. If chunked the type of the column
+// cannot be changed once decided.
+func columnKind(ct *sql.ColumnType, chunked bool) reflect.Kind {
+	var kind reflect.Kind
+	name := typeName(ct.DatabaseTypeName())
+	switch {
+	case intTypeNames[name]:
+		kind = reflect.Int
+	case floatTypeNames[name]:
+		kind = reflect.Float64
+	case boolTypeNames[name]:
+		kind = reflect.Bool
+	case stringTypeNames[name]:
+		kind = reflect.String
+	default:
+		kind = scanKind(ct.ScanType())
+	}
+
+	switch kind {
+	case reflect.Int:
+		// Ints cannot represent NULL. Int columns are promoted to float
+		// when the first NULL is read, which is not possible when reading
+		// in chunks since earlier chunks have already been returned as ints.
+		// Only columns known not to be nullable are then read as ints.
+		if nullable, ok := ct.Nullable(); chunked && (nullable || !ok) {
+			return reflect.Float64
+		}
+	case reflect.Bool:
+		// Bools cannot represent NULL, let the type be inferred
+		// from the data for nullable columns
+		if nullable, ok := ct.Nullable(); !ok || nullable {
+			return reflect.Invalid
+		}
+	}
+	return kind
+}
+
+// Reader reads the rows of a query result into column data.
+// The rows may be read all at once or in chunks. The column types are
+// decided by the first chunk read and kept for all following chunks.
+// When reading in chunks ints are only used for columns known not to
+// hold NULL, other int columns are read as floats. Columns that only
+// hold NULL in the first chunk, and whose type is unknown, are string
+// columns and values in later chunks are read as strings.
+type Reader struct {
+	rows     *sql.Rows
+	conf     SQLConfig
+	colNames []string
+	colTypes []*sql.ColumnType
+	// kinds of the columns, set after the first chunk has been read
+	kinds []reflect.Kind
+	// values of enum columns in the chunks read so far
+	enumValues [][]string
+	done       bool
+}
+
+// NewReader creates a new reader of rows.
+func NewReader(rows *sql.Rows, conf SQLConfig) (*Reader, error) {
+	colNames, err := rows.Columns()
+	if err != nil {
+		return nil, qerrors.IO.Propagate("ReadSQL Columns", err)
+	}
+
+	// ensure any column in the coercion map
+	// exists in the resulting columns or return
+	// an error explicitly.
+	for name := range conf.CoerceMap {
+		if !contains(colNames, name) {
+			return nil, qerrors.UnknownColumn.New("ReadSQL Columns", "column %s does not exist to coerce", name)
+		}
+	}
+
+	colTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, qerrors.IO.Propagate("ReadSQL ColumnTypes", err)
+	}
+
+	return &Reader{rows: rows, conf: conf, colNames: colNames, colTypes: colTypes}, nil
+}
+
+// Columns returns the names of the columns read.
+func (r *Reader) Columns() []string {
+	return r.colNames
+}
+
+// Done returns true when all rows have been read.
+func (r *Reader) Done() bool {
+	return r.done
+}
+
+func (r *Reader) newColumns(chunked bool) []interface{} {
+	columns := make([]interface{}, len(r.colNames))
+	for i, name := range r.colNames {
+		col := &Column{precision: r.conf.Precision}
+		if fn, ok := r.conf.CoerceMap[name]; ok {
+			col.coerce = fn(col)
+		} else if r.conf.EnumTypes[typeName(r.colTypes[i].DatabaseTypeName())] {
+			col.setKind(reflect.String)
+			col.enum = true
+		} else if r.kinds != nil {
+			col.setKind(r.kinds[i])
+			col.fixed = true
+		} else if kind := columnKind(r.colTypes[i], chunked); kind != reflect.Invalid {
+			col.setKind(kind)
 		}
+		columns[i] = col
+	}
+	return columns
+}
+
+// Read reads up to n rows, all remaining rows if n < 1, and returns
+// the column data together with the number of rows read.
+func (r *Reader) Read(n int) (map[string]types.DataSlice, int, error) {
+	chunked := n > 0
+	columns := r.newColumns(chunked)
+	count := 0
+	for !chunked || count < n {
+		if !r.rows.Next() {
+			r.done = true
+			break
+		}
+
 		// Scan the result into our columns
-		err := rows.Scan(columns...)
+		err := r.rows.Scan(columns...)
 		if err != nil {
-			return nil, colNames, qerrors.New("ReadSQL Scan", err.Error())
+			return nil, count, qerrors.Propagate("ReadSQL Scan", err)
 		}
+		count++
+	}
+
+	if err := r.rows.Err(); err != nil {
+		return nil, count, qerrors.IO.Propagate("ReadSQL Rows", err)
 	}
+
+	if r.kinds == nil {
+		r.kinds = make([]reflect.Kind, len(columns))
+		r.enumValues = make([][]string, len(columns))
+	}
+
 	result := map[string]types.DataSlice{}
 	for i, column := range columns {
-		result[colNames[i]] = column.(*Column).Data()
+		col := column.(*Column)
+		if col.kind == reflect.Invalid {
+			// No type could be decided from the data,
+			// only NULLs or no rows at all.
+			col.setKind(reflect.String)
+			col.data.Strings = make([]*string, col.nulls)
+		} else if chunked && col.kind == reflect.Int && !col.typed {
+			// Inferred from the data, a later chunk may hold NULLs
+			col.toFloat()
+		}
+		r.kinds[i] = col.kind
+
+		if col.enum {
+			ec, values, err := newEnum(col.data.Strings, r.enumValues[i])
+			if err != nil {
+				return nil, count, qerrors.Propagate("ReadSQL Enum", err)
+			}
+			r.enumValues[i] = values
+			result[r.colNames[i]] = ec
+			continue
+		}
+		result[r.colNames[i]] = col.Data()
+	}
+	return result, count, nil
+}
+
+// newEnum creates an enum column from data. The values of the column are
+// the known values, in order, followed by any new values in data in order
+// of appearance. The values are returned together with the column.
+func newEnum(data []*string, known []string) (ecolumn.Column, []string, error) {
+	values := known
+	seen := make(map[string]struct{}, len(known))
+	for _, v := range known {
+		seen[v] = struct{}{}
+	}
+
+	for _, s := range data {
+		if s == nil {
+			continue
+		}
+
+		if _, ok := seen[*s]; !ok {
+			seen[*s] = struct{}{}
+			values = append(values, *s)
+		}
+	}
+
+	ec, err := ecolumn.New(data, values)
+	if err != nil {
+		return ecolumn.Column{}, nil, err
+	}
+
+	// The values are derived from the data, not defined up front
+	raw, _, _ := ec.Raw()
+	ec, err = ecolumn.NewRaw(raw, values, false)
+	return ec, values, err
+}
+
+// ReadSQL returns a named map of types.DataSlice for consumption
+// by the qframe.New constructor.
+func ReadSQL(rows *sql.Rows, conf SQLConfig) (map[string]types.DataSlice, []string, error) {
+	reader, err := NewReader(rows, conf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, _, err := reader.Read(0)
+	return result, reader.Columns(), err
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
 	}
-	return result, colNames, nil
+	return false
 }
@@ -28,6 +28,13 @@ func escape(s string, char rune, buf *bytes.Buffer) {
 // PostgreSQL accepts "incrementing" markers e.g. $1..$2
 // While MySQL/MariaDB and SQLite accept ?..?.
 func Insert(colNames []string, conf SQLConfig) string {
+	return InsertBatch(colNames, 1, conf)
+}
+
+// InsertBatch generates a SQL insert statement like
+// Insert but with parameter markers for rowCount rows
+// in a single multi-row VALUES clause.
+func InsertBatch(colNames []string, rowCount int, conf SQLConfig) string {
 	buf := bytes.NewBuffer(nil)
 	buf.WriteString("INSERT INTO ")
 	escape(conf.Table, conf.EscapeChar, buf)
@@ -38,17 +45,127 @@ func Insert(colNames []string, conf SQLConfig) string {
 			buf.WriteString(",")
 		}
 	}
-	buf.WriteString(") VALUES (")
-	for i := range colNames {
-		if conf.Incrementing {
-			buf.WriteString(fmt.Sprintf("$%d", i+1))
-		} else {
-			buf.WriteString("?")
-		}
-		if i+1 < len(colNames) {
+	buf.WriteString(") VALUES ")
+	for row := 0; row < rowCount; row++ {
+		if row > 0 {
 			buf.WriteString(",")
 		}
+		buf.WriteString("(")
+		for i := range colNames {
+			if conf.Incrementing {
+				buf.WriteString(fmt.Sprintf("$%d", row*len(colNames)+i+1))
+			} else {
+				buf.WriteString("?")
+			}
+			if i+1 < len(colNames) {
+				buf.WriteString(",")
+			}
+		}
+		buf.WriteString(")")
 	}
-	buf.WriteString(");")
+	upsert(colNames, conf, buf)
+	buf.WriteString(";")
 	return buf.String()
 }
+
+// updateColumns returns the columns to update on conflict.
+func updateColumns(colNames []string, conf SQLConfig) []string {
+	if conf.UpdateColumns != nil {
+		return conf.UpdateColumns
+	}
+
+	result := make([]string, 0, len(colNames))
+	for _, name := range colNames {
+		isConflictCol := false
+		for _, c := range conf.ConflictColumns {
+			if c == name {
+				isConflictCol = true
+				break
+			}
+		}
+
+		if !isConflictCol {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// upsert writes the clause that turns an INSERT into
+// an "insert or update" statement if conflict columns
+// have been configured:
+// PostgreSQL/SQLite - ON CONFLICT (...) DO UPDATE SET ...
+// MySQL/MariaDB - ON DUPLICATE KEY UPDATE ...
+//
+// MySQL does not take the conflict columns into account
+// but updates on conflicts with any unique index.
+func upsert(colNames []string, conf SQLConfig, buf *bytes.Buffer) {
+	if len(conf.ConflictColumns) == 0 {
+		return
+	}
+
+	updates := updateColumns(colNames, conf)
+	if conf.Dialect == MySQLDialect {
+		buf.WriteString(" ON DUPLICATE KEY UPDATE ")
+		if len(updates) == 0 {
+			// MySQL has no DO NOTHING, assign a key column to itself instead.
+			escape(conf.ConflictColumns[0], conf.EscapeChar, buf)
+			buf.WriteString("=")
+			escape(conf.ConflictColumns[0], conf.EscapeChar, buf)
+			return
+		}
+
+		for i, name := range updates {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			escape(name, conf.EscapeChar, buf)
+			buf.WriteString("=VALUES(")
+			escape(name, conf.EscapeChar, buf)
+			buf.WriteString(")")
+		}
+		return
+	}
+
+	buf.WriteString(" ON CONFLICT (")
+	for i, name := range conf.ConflictColumns {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		escape(name, conf.EscapeChar, buf)
+	}
+	buf.WriteString(")")
+	if len(updates) == 0 {
+		buf.WriteString(" DO NOTHING")
+		return
+	}
+
+	buf.WriteString(" DO UPDATE SET ")
+	for i, name := range updates {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		escape(name, conf.EscapeChar, buf)
+		buf.WriteString("=excluded.")
+		escape(name, conf.EscapeChar, buf)
+	}
+}
+
+// RowsPerInsert returns the number of rows to insert
+// per statement given the configured batch size and
+// the parameter limit of the database.
+func RowsPerInsert(colCount int, conf SQLConfig) int {
+	rows := conf.BatchSize
+	if rows < 1 {
+		rows = 1
+	}
+
+	if conf.ParamLimit > 0 && colCount > 0 && rows*colCount > conf.ParamLimit {
+		rows = conf.ParamLimit / colCount
+		if rows < 1 {
+			rows = 1
+		}
+	}
+
+	return rows
+}
@@ -1,8 +1,6 @@
 package sql
 
-import (
-	"testing"
-)
+import "testing"
 
 func TestInsert(t *testing.T) {
 	// Unescaped
@@ -22,3 +20,70 @@ func TestInsert(t *testing.T) {
 	expected = "INSERT INTO `test` (`COL1`,`COL2`) VALUES (?,?);"
 	assertEqual(t, expected, query)
 }
+
+func TestInsertBatch(t *testing.T) {
+	cols := []string{"ID", "NAME", "VALUE"}
+	table := []struct {
+		name     string
+		rowCount int
+		conf     SQLConfig
+		expected string
+	}{
+		{
+			name:     "single row",
+			rowCount: 1,
+			conf:     SQLConfig{Table: "t"},
+			expected: "INSERT INTO t (ID,NAME,VALUE) VALUES (?,?,?);",
+		},
+		{
+			name:     "incrementing multi row",
+			rowCount: 2,
+			conf:     SQLConfig{Table: "t", EscapeChar: '"', Incrementing: true},
+			expected: `INSERT INTO "t" ("ID","NAME","VALUE") VALUES ($1,$2,$3),($4,$5,$6);`,
+		},
+		{
+			name:     "on conflict update all",
+			rowCount: 1,
+			conf:     SQLConfig{Table: "t", EscapeChar: '"', Dialect: SQLiteDialect, ConflictColumns: []string{"ID"}},
+			expected: `INSERT INTO "t" ("ID","NAME","VALUE") VALUES (?,?,?) ON CONFLICT ("ID") DO UPDATE SET "NAME"=excluded."NAME","VALUE"=excluded."VALUE";`,
+		},
+		{
+			name:     "on conflict update some",
+			rowCount: 1,
+			conf: SQLConfig{Table: "t", Dialect: PostgresDialect, Incrementing: true,
+				ConflictColumns: []string{"ID", "NAME"}, UpdateColumns: []string{"VALUE"}},
+			expected: `INSERT INTO t (ID,NAME,VALUE) VALUES ($1,$2,$3) ON CONFLICT (ID,NAME) DO UPDATE SET VALUE=excluded.VALUE;`,
+		},
+		{
+			name:     "on conflict do nothing",
+			rowCount: 1,
+			conf:     SQLConfig{Table: "t", Dialect: PostgresDialect, ConflictColumns: []string{"ID"}, UpdateColumns: []string{}},
+			expected: `INSERT INTO t (ID,NAME,VALUE) VALUES (?,?,?) ON CONFLICT (ID) DO NOTHING;`,
+		},
+		{
+			name:     "on duplicate key update",
+			rowCount: 2,
+			conf:     SQLConfig{Table: "t", EscapeChar: '`', Dialect: MySQLDialect, ConflictColumns: []string{"ID"}},
+			expected: "INSERT INTO `t` (`ID`,`NAME`,`VALUE`) VALUES (?,?,?),(?,?,?) ON DUPLICATE KEY UPDATE `NAME`=VALUES(`NAME`),`VALUE`=VALUES(`VALUE`);",
+		},
+		{
+			name:     "on duplicate key no update",
+			rowCount: 1,
+			conf:     SQLConfig{Table: "t", Dialect: MySQLDialect, ConflictColumns: []string{"ID"}, UpdateColumns: []string{}},
+			expected: "INSERT INTO t (ID,NAME,VALUE) VALUES (?,?,?) ON DUPLICATE KEY UPDATE ID=ID;",
+		},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			assertEqual(t, tc.expected, InsertBatch(cols, tc.rowCount, tc.conf))
+		})
+	}
+}
+
+func TestRowsPerInsert(t *testing.T) {
+	assertEqual(t, 1, RowsPerInsert(3, SQLConfig{}))
+	assertEqual(t, 100, RowsPerInsert(3, SQLConfig{BatchSize: 100}))
+	assertEqual(t, 333, RowsPerInsert(3, SQLConfig{BatchSize: 1000, ParamLimit: 999}))
+	assertEqual(t, 1, RowsPerInsert(3, SQLConfig{BatchSize: 1000, ParamLimit: 2}))
+}
@@ -1,7 +1,6 @@
 package sql
 
 import (
-	"fmt"
 	"reflect"
 
 	"github.com/tobgu/qframe/internal/bcolumn"
@@ -14,6 +13,17 @@ import (
 	"github.com/tobgu/qframe/qerrors"
 )
 
+// Dialect identifies the SQL variant to generate
+// for statements where databases differ.
+type Dialect int
+
+const (
+	DefaultDialect Dialect = iota
+	PostgresDialect
+	SQLiteDialect
+	MySQLDialect
+)
+
 type SQLConfig struct {
 	// Query is a Raw SQL statement which must return
 	// appropriate types which can be inferred
@@ -37,6 +47,37 @@ type SQLConfig struct {
 	// Precision specifies how much precision float values
 	// should have. 0 has no effect.
 	Precision int
+	// BatchSize is the maximum number of rows inserted
+	// by each INSERT statement. Values < 2 insert one row
+	// per statement.
+	BatchSize int
+	// ParamLimit is the maximum number of parameter markers
+	// allowed in a single statement by the database. 0 means
+	// no limit.
+	ParamLimit int
+	// Dialect is the SQL variant of the database.
+	Dialect Dialect
+	// ConflictColumns are the columns that identify
+	// a row when inserting with "insert or update"
+	// semantics. Upserts are disabled if empty.
+	ConflictColumns []string
+	// UpdateColumns are the columns updated when an
+	// inserted row conflicts with an existing row.
+	// All non conflict columns are updated if nil.
+	UpdateColumns []string
+	// CreateTable indicates that the table should be
+	// created before writing to it.
+	CreateTable bool
+	// PrimaryKey are the columns making up the primary
+	// key of created tables.
+	PrimaryKey []string
+	// EnumTypes are the upper case database type names,
+	// as reported by the driver, of columns that should
+	// be read into enum columns.
+	EnumTypes map[string]bool
+	// ChunkSize is the maximum number of rows in each
+	// QFrame when reading query results in chunks.
+	ChunkSize int
 }
 
 type ArgBuilder func(ix index.Int, i int) interface{}
@@ -64,5 +105,5 @@ func NewArgBuilder(col column.Column) (ArgBuilder, error) {
 			return c.View(ix).ItemAt(i)
 		}, nil
 	}
-	return nil, qerrors.New("NewArgBuilder", fmt.Sprintf("bad column type: %s", reflect.TypeOf(col).Name()))
+	return nil, qerrors.TypeMismatch.New("NewArgBuilder", "bad column type: %s", reflect.TypeOf(col).Name())
 }
@@ -36,7 +36,7 @@ func (c Column) Comparable(reverse, equalNull, nullLast bool) column.Comparable
 	return Comparable{}
 }
 
-func (c Column) Aggregate(indices []index.Int, fn interface{}) (column.Column, error) {
+func (c Column) Aggregate(indices []index.Int, fn interface{}, workers int) (column.Column, error) {
 	return c, nil
 }
 
@@ -56,11 +56,11 @@ func (c Column) Len() int {
 	return 0
 }
 
-func (c Column) Apply1(fn interface{}, ix index.Int) (interface{}, error) {
+func (c Column) Apply1(fn interface{}, ix index.Int, workers int) (interface{}, error) {
 	return c, nil
 }
 
-func (c Column) Apply2(fn interface{}, s2 column.Column, ix index.Int) (column.Column, error) {
+func (c Column) Apply2(fn interface{}, s2 column.Column, ix index.Int, workers int) (column.Column, error) {
 	return c, nil
 }
 
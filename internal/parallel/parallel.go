@@ -0,0 +1,65 @@
+package parallel
+
+import "sync"
+
+// MinChunkSize is the smallest number of items that is handed to a
+// separate goroutine. Smaller inputs are processed in fewer chunks
+// since the overhead of starting a goroutine would dominate.
+const MinChunkSize = 4096
+
+// Chunks splits [0, n) into at most workers consecutive ranges of
+// roughly equal size, none smaller than minSize unless n is. The
+// returned slice holds the start of each range followed by n.
+func Chunks(n, workers, minSize int) []int {
+	if minSize < 1 {
+		minSize = 1
+	}
+
+	if max := n / minSize; workers > max {
+		workers = max
+	}
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	bounds := make([]int, workers+1)
+	for i := range bounds {
+		bounds[i] = i * n / workers
+	}
+	return bounds
+}
+
+// For calls fn once for each chunk [lo, hi) of [0, n), as split by Chunks
+// using MinChunkSize. The chunks are processed concurrently if there is
+// more than one. For blocks until all calls have returned and returns the
+// error of the first chunk, in chunk order, that failed.
+func For(n, workers int, fn func(lo, hi int) error) error {
+	return ForSize(n, workers, MinChunkSize, fn)
+}
+
+// ForSize is like For but with a custom minimum chunk size.
+func ForSize(n, workers, minSize int, fn func(lo, hi int) error) error {
+	bounds := Chunks(n, workers, minSize)
+	if len(bounds) == 2 {
+		return fn(0, n)
+	}
+
+	errs := make([]error, len(bounds)-1)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = fn(bounds[i], bounds[i+1])
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
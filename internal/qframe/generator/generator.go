@@ -24,12 +24,12 @@ type {{.type}}View struct {
 func (qf QFrame) {{.type}}View(colName string) ({{.type}}View, error) {
 	namedColumn, ok := qf.columnsByName[colName]
 	if !ok {
-		return {{.type}}View{}, qerrors.New("{{.type}}View", "unknown column: %s", colName)
+		return {{.type}}View{}, qerrors.UnknownColumn.New("{{.type}}View", "unknown column: %s", colName)
 	}
 
 	col, ok := namedColumn.Column.({{.package}}.Column)
 	if !ok {
-		return {{.type}}View{}, qerrors.New(
+		return {{.type}}View{}, qerrors.TypeMismatch.New(
 			"{{.type}}View",
 			"invalid column type, expected: %s, was: %s", "{{.lowerType}}", namedColumn.DataType())
 	}
@@ -7,6 +7,7 @@ import (
 	"github.com/tobgu/qframe/internal/column"
 	"github.com/tobgu/qframe/internal/hash"
 	"github.com/tobgu/qframe/internal/index"
+	"github.com/tobgu/qframe/internal/parallel"
 	qfstrings "github.com/tobgu/qframe/internal/strings"
 	"github.com/tobgu/qframe/qerrors"
 	"github.com/tobgu/qframe/types"
@@ -156,30 +157,30 @@ func (c Column) filterBuiltIn(index index.Int, comparator string, comparatee int
 	case string:
 		filterFn, ok := filterFuncs1[comparator]
 		if !ok {
-			return qerrors.New("filter string", "unknown filter operator %v for single value argument", comparator)
+			return qerrors.InvalidArgument.New("filter string", "unknown filter operator %v for single value argument", comparator)
 		}
 		return filterFn(index, c, t, bIndex)
 	case []string:
 		filterFn, ok := multiInputFilterFuncs[comparator]
 		if !ok {
-			return qerrors.New("filter string", "unknown filter operator %v for multi value argument", comparator)
+			return qerrors.InvalidArgument.New("filter string", "unknown filter operator %v for multi value argument", comparator)
 		}
 
 		return filterFn(index, c, qfstrings.NewStringSet(t), bIndex)
 	case Column:
 		filterFn, ok := filterFuncs2[comparator]
 		if !ok {
-			return qerrors.New("filter string", "unknown filter operator %v for column - column comparison", comparator)
+			return qerrors.InvalidArgument.New("filter string", "unknown filter operator %v for column - column comparison", comparator)
 		}
 		return filterFn(index, c, t, bIndex)
 	case nil:
 		filterFn, ok := filterFuncs0[comparator]
 		if !ok {
-			return qerrors.New("filter string", "unknown filter operator %v for zero argument", comparator)
+			return qerrors.InvalidArgument.New("filter string", "unknown filter operator %v for zero argument", comparator)
 		}
 		return filterFn(index, c, bIndex)
 	default:
-		return qerrors.New("filter string", "invalid comparison value type %v", reflect.TypeOf(comparatee))
+		return qerrors.TypeMismatch.New("filter string", "invalid comparison value type %v", reflect.TypeOf(comparatee))
 	}
 }
 
@@ -194,7 +195,7 @@ func (c Column) filterCustom1(index index.Int, fn func(*string) bool, bIndex ind
 func (c Column) filterCustom2(index index.Int, fn func(*string, *string) bool, comparatee interface{}, bIndex index.Bool) error {
 	otherC, ok := comparatee.(Column)
 	if !ok {
-		return qerrors.New("filter string", "expected comparatee to be string column, was %v", reflect.TypeOf(comparatee))
+		return qerrors.TypeMismatch.New("filter string", "expected comparatee to be string column, was %v", reflect.TypeOf(comparatee))
 	}
 
 	for i, x := range bIndex {
@@ -216,7 +217,7 @@ func (c Column) Filter(index index.Int, comparator interface{}, comparatee inter
 	case func(*string, *string) bool:
 		err = c.filterCustom2(index, t, comparatee, bIndex)
 	default:
-		err = qerrors.New("filter string", "invalid filter type %v", reflect.TypeOf(comparator))
+		err = qerrors.TypeMismatch.New("filter string", "invalid filter type %v", reflect.TypeOf(comparator))
 	}
 	return err
 }
@@ -230,6 +231,12 @@ func NewBytes(pointers []qfstrings.Pointer, bytes []byte) Column {
 	return Column{pointers: pointers, data: bytes}
 }
 
+// Raw returns the pointers and byte blob holding the column data. Both
+// are shared with the column and must not be modified.
+func (c Column) Raw() ([]qfstrings.Pointer, []byte) {
+	return c.pointers, c.data
+}
+
 func NewStrings(strings []string) Column {
 	data := make([]byte, 0, len(strings))
 	pointers := make([]qfstrings.Pointer, len(strings))
@@ -348,19 +355,22 @@ func (c Column) String() string {
 	return fmt.Sprintf("%v", c.data)
 }
 
-func (c Column) Aggregate(indices []index.Int, fn interface{}) (column.Column, error) {
+func (c Column) Aggregate(indices []index.Int, fn interface{}, workers int) (column.Column, error) {
 	switch t := fn.(type) {
 	case string:
 		// There are currently no built in aggregations for strings
-		return nil, qerrors.New("string aggregate", "aggregation function %c is not defined for string column", fn)
+		return nil, qerrors.InvalidArgument.New("string aggregate", "aggregation function %c is not defined for string column", fn)
 	case func([]*string) *string:
-		data := make([]*string, 0, len(indices))
-		for _, ix := range indices {
-			data = append(data, t(c.stringSlice(ix)))
-		}
+		data := make([]*string, len(indices))
+		_ = parallel.ForSize(len(indices), workers, 1, func(lo, hi int) error {
+			for i, ix := range indices[lo:hi] {
+				data[lo+i] = t(c.stringSlice(ix))
+			}
+			return nil
+		})
 		return New(data), nil
 	default:
-		return nil, qerrors.New("string aggregate", "invalid aggregation function type: %v", t)
+		return nil, qerrors.TypeMismatch.New("string aggregate", "invalid aggregation function type: %v", t)
 	}
 }
 
@@ -371,60 +381,75 @@ func stringToPtr(s string, isNull bool) *string {
 	return &s
 }
 
-func (c Column) Apply1(fn interface{}, ix index.Int) (interface{}, error) {
+func (c Column) Apply1(fn interface{}, ix index.Int, workers int) (interface{}, error) {
 	switch t := fn.(type) {
 	case func(*string) int:
 		result := make([]int, len(c.pointers))
-		for _, i := range ix {
-			result[i] = t(stringToPtr(c.stringAt(i)))
-		}
+		_ = parallel.For(len(ix), workers, func(lo, hi int) error {
+			for _, i := range ix[lo:hi] {
+				result[i] = t(stringToPtr(c.stringAt(i)))
+			}
+			return nil
+		})
 		return result, nil
 	case func(*string) float64:
 		result := make([]float64, len(c.pointers))
-		for _, i := range ix {
-			result[i] = t(stringToPtr(c.stringAt(i)))
-		}
+		_ = parallel.For(len(ix), workers, func(lo, hi int) error {
+			for _, i := range ix[lo:hi] {
+				result[i] = t(stringToPtr(c.stringAt(i)))
+			}
+			return nil
+		})
 		return result, nil
 	case func(*string) bool:
 		result := make([]bool, len(c.pointers))
-		for _, i := range ix {
-			result[i] = t(stringToPtr(c.stringAt(i)))
-		}
+		_ = parallel.For(len(ix), workers, func(lo, hi int) error {
+			for _, i := range ix[lo:hi] {
+				result[i] = t(stringToPtr(c.stringAt(i)))
+			}
+			return nil
+		})
 		return result, nil
 	case func(*string) *string:
 		result := make([]*string, len(c.pointers))
-		for _, i := range ix {
-			result[i] = t(stringToPtr(c.stringAt(i)))
-		}
+		_ = parallel.For(len(ix), workers, func(lo, hi int) error {
+			for _, i := range ix[lo:hi] {
+				result[i] = t(stringToPtr(c.stringAt(i)))
+			}
+			return nil
+		})
 		return result, nil
 	case string:
 		if f, ok := stringApplyFuncs[t]; ok {
 			return f(ix, c), nil
 		}
-		return nil, qerrors.New("string.apply1", "unknown built in function %v", t)
+		return nil, qerrors.InvalidArgument.New("string.apply1", "unknown built in function %v", t)
 	default:
-		return nil, qerrors.New("string.apply1", "cannot apply type %#v to column", fn)
+		return nil, qerrors.TypeMismatch.New("string.apply1", "cannot apply type %#v to column", fn)
 	}
 }
 
-func (c Column) Apply2(fn interface{}, s2 column.Column, ix index.Int) (column.Column, error) {
+func (c Column) Apply2(fn interface{}, s2 column.Column, ix index.Int, workers int) (column.Column, error) {
 	s2S, ok := s2.(Column)
 	if !ok {
-		return nil, qerrors.New("string.apply2", "invalid column type %v", reflect.TypeOf(s2))
+		return nil, qerrors.TypeMismatch.New("string.apply2", "invalid column type %v", reflect.TypeOf(s2))
 	}
 
 	switch t := fn.(type) {
 	case func(*string, *string) *string:
 		result := make([]*string, len(c.pointers))
-		for _, i := range ix {
-			result[i] = t(stringToPtr(c.stringAt(i)), stringToPtr(s2S.stringAt(i)))
-		}
+		_ = parallel.For(len(ix), workers, func(lo, hi int) error {
+			for _, i := range ix[lo:hi] {
+				result[i] = t(stringToPtr(c.stringAt(i)), stringToPtr(s2S.stringAt(i)))
+			}
+			return nil
+		})
 		return New(result), nil
 	case string:
 		// No built in functions for strings at this stage
-		return nil, qerrors.New("string.apply2", "unknown built in function %s", t)
+		return nil, qerrors.InvalidArgument.New("string.apply2", "unknown built in function %s", t)
 	default:
-		return nil, qerrors.New("string.apply2", "cannot apply type %#v to column", fn)
+		return nil, qerrors.TypeMismatch.New("string.apply2", "cannot apply type %#v to column", fn)
 	}
 }
 
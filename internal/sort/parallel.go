@@ -0,0 +1,89 @@
+package sort
+
+import (
+	"github.com/tobgu/qframe/internal/index"
+	"github.com/tobgu/qframe/internal/parallel"
+)
+
+// ParallelSort sorts the index using up to workers goroutines. The index is split
+// into chunks that are sorted concurrently, the sorted chunks are then merged
+// pairwise, also concurrently, until one chunk remains. Indexes too small to be
+// split are sorted using Sort.
+//
+// The merges are stable but the sorting of the chunks is not, the order of equal
+// elements is undefined as for Sort.
+//
+// The error of the context of the sorter is returned if it is done
+// before the sort has completed.
+func (s Sorter) ParallelSort(workers int) error {
+	bounds := parallel.Chunks(len(s.index), workers, parallel.MinChunkSize)
+	if len(bounds) <= 2 {
+		s.Sort()
+		return s.Err()
+	}
+
+	_ = parallel.ForSize(len(bounds)-1, workers, 1, func(lo, hi int) error {
+		for c := lo; c < hi; c++ {
+			n := bounds[c+1] - bounds[c]
+			quickSort(s, bounds[c], bounds[c+1], maxDepth(n))
+		}
+		return nil
+	})
+
+	src, dst := s.index, make(index.Int, len(s.index))
+	for len(bounds) > 2 {
+		if err := s.Err(); err != nil {
+			return err
+		}
+
+		runCount := len(bounds) - 1
+		pairCount := (runCount + 1) / 2
+		_ = parallel.ForSize(pairCount, workers, 1, func(lo, hi int) error {
+			for p := lo; p < hi; p++ {
+				start, mid := bounds[2*p], bounds[2*p+1]
+				if 2*p+2 >= len(bounds) {
+					// Odd run out, nothing to merge with
+					copy(dst[start:mid], src[start:mid])
+					continue
+				}
+				end := bounds[2*p+2]
+				s.merge(src[start:mid], src[mid:end], dst[start:end])
+			}
+			return nil
+		})
+
+		newBounds := make([]int, 0, pairCount+1)
+		for i := 0; i < len(bounds); i += 2 {
+			newBounds = append(newBounds, bounds[i])
+		}
+		if newBounds[len(newBounds)-1] != len(src) {
+			newBounds = append(newBounds, len(src))
+		}
+		bounds = newBounds
+		src, dst = dst, src
+	}
+
+	if &src[0] != &s.index[0] {
+		copy(s.index, src)
+	}
+	return nil
+}
+
+// merge merges the sorted indexes a and b into dst. Elements from a
+// are picked before equal elements from b.
+func (s Sorter) merge(a, b, dst index.Int) {
+	i, j, k := 0, 0, 0
+	for i < len(a) && j < len(b) {
+		if s.lessRows(b[j], a[i]) {
+			dst[k] = b[j]
+			j++
+		} else {
+			dst[k] = a[i]
+			i++
+		}
+		k++
+	}
+
+	k += copy(dst[k:], a[i:])
+	copy(dst[k:], b[j:])
+}
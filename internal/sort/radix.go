@@ -0,0 +1,69 @@
+package sort
+
+import (
+	"context"
+
+	"github.com/tobgu/qframe/internal/cancel"
+	"github.com/tobgu/qframe/internal/index"
+)
+
+// RadixThreshold is the minimum number of elements for which radix sort
+// is expected to outperform a comparison based sort.
+const RadixThreshold = 256
+
+// RadixSort sorts ix in ascending order of keys where keys[i] is the key
+// of ix[i]. The sort is stable. keys is modified.
+//
+// The keys are sorted one byte at a time, starting with the least significant
+// byte. Bytes that are the same for all keys are skipped which makes keys
+// with a small range cheap to sort.
+//
+// ctx is checked between the passes, the error of ctx is returned if
+// it is done before the sort has completed.
+func RadixSort(ctx context.Context, ix index.Int, keys []uint64) error {
+	n := len(ix)
+	if n < 2 {
+		return nil
+	}
+
+	srcIx, dstIx := ix, make(index.Int, n)
+	srcKeys, dstKeys := keys, make([]uint64, n)
+	var offsets [256]int
+	for shift := uint(0); shift < 64; shift += 8 {
+		if err := cancel.Err(ctx); err != nil {
+			return err
+		}
+
+		offsets = [256]int{}
+		for _, k := range srcKeys {
+			offsets[byte(k>>shift)]++
+		}
+
+		if offsets[byte(srcKeys[0]>>shift)] == n {
+			// All keys have the same value for this byte
+			continue
+		}
+
+		offset := 0
+		for i, count := range offsets {
+			offsets[i] = offset
+			offset += count
+		}
+
+		for i, k := range srcKeys {
+			b := byte(k >> shift)
+			pos := offsets[b]
+			offsets[b]++
+			dstIx[pos] = srcIx[i]
+			dstKeys[pos] = k
+		}
+
+		srcIx, dstIx = dstIx, srcIx
+		srcKeys, dstKeys = dstKeys, srcKeys
+	}
+
+	if &srcIx[0] != &ix[0] {
+		copy(ix, srcIx)
+	}
+	return nil
+}
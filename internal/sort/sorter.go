@@ -5,6 +5,9 @@
 package sort
 
 import (
+	"context"
+
+	"github.com/tobgu/qframe/internal/cancel"
 	"github.com/tobgu/qframe/internal/column"
 	"github.com/tobgu/qframe/internal/index"
 )
@@ -12,17 +15,37 @@ import (
 type Sorter struct {
 	index   index.Int
 	columns []column.Comparable
+	ctx     context.Context
 }
 
 func New(ix index.Int, columns []column.Comparable) Sorter {
 	return Sorter{index: ix, columns: columns}
 }
 
+// WithContext returns a sorter that stops sorting once ctx is done,
+// leaving the index partially sorted. Use Err to check if the
+// sort was aborted.
+func (s Sorter) WithContext(ctx context.Context) Sorter {
+	s.ctx = ctx
+	return s
+}
+
+// Err returns the error of the context of the sorter if it is done.
+func (s Sorter) Err() error {
+	return cancel.Err(s.ctx)
+}
+
 func (s Sorter) Sort() {
 	n := s.Len()
 	quickSort(s, 0, n, maxDepth(n))
 }
 
+// StableSort sorts the index keeping the order of equal elements. It uses
+// insertion sort and is only intended for small indexes.
+func (s Sorter) StableSort() {
+	insertionSort(s, 0, s.Len())
+}
+
 func (s Sorter) Len() int {
 	return len(s.index)
 }
@@ -32,7 +55,10 @@ func (s Sorter) Swap(i, j int) {
 }
 
 func (s Sorter) Less(i, j int) bool {
-	di, dj := s.index[i], s.index[j]
+	return s.lessRows(s.index[i], s.index[j])
+}
+
+func (s Sorter) lessRows(di, dj uint32) bool {
 	for _, s := range s.columns {
 		r := s.Compare(di, dj)
 		if r == column.LessThan {
@@ -206,6 +232,10 @@ func doPivot(data Sorter, lo, hi int) (midlo, midhi int) {
 
 func quickSort(data Sorter, a, b, maxDepth int) {
 	for b-a > 12 { // Use ShellSort for slices <= 12 elements
+		if b-a > cancel.Interval && data.Err() != nil {
+			// Not part of the stdlib, abort if the context is done
+			return
+		}
 		if maxDepth == 0 {
 			heapSort(data, a, b)
 			return
@@ -0,0 +1,97 @@
+package sort
+
+import (
+	"github.com/tobgu/qframe/internal/column"
+	"github.com/tobgu/qframe/internal/index"
+)
+
+// topNHeap is a max heap holding positions in index. The root is the
+// position of the greatest element, the next one to be evicted.
+type topNHeap struct {
+	index   index.Int
+	columns []column.Comparable
+	heap    []int
+}
+
+// less compares the elements at positions i and j in the index. Equal
+// elements are ordered by position to keep the result stable.
+func (h topNHeap) less(i, j int) bool {
+	di, dj := h.index[i], h.index[j]
+	for _, c := range h.columns {
+		r := c.Compare(di, dj)
+		if r == column.LessThan {
+			return true
+		}
+
+		if r == column.GreaterThan {
+			return false
+		}
+	}
+
+	return i < j
+}
+
+func (h topNHeap) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(h.heap[parent], h.heap[i]) {
+			return
+		}
+		h.heap[parent], h.heap[i] = h.heap[i], h.heap[parent]
+		i = parent
+	}
+}
+
+func (h topNHeap) down(i, n int) {
+	for {
+		child := 2*i + 1
+		if child >= n {
+			return
+		}
+		if child+1 < n && h.less(h.heap[child], h.heap[child+1]) {
+			child++
+		}
+		if !h.less(h.heap[i], h.heap[child]) {
+			return
+		}
+		h.heap[i], h.heap[child] = h.heap[child], h.heap[i]
+		i = child
+	}
+}
+
+// TopN returns the n first elements of ix, in sorted order, if ix was sorted
+// by columns. Equal elements keep their order in ix. ix is not modified.
+//
+// Time complexity O(m * log(n)) where m = length of ix.
+func TopN(ix index.Int, columns []column.Comparable, n int) index.Int {
+	if n > len(ix) {
+		n = len(ix)
+	}
+
+	if n <= 0 {
+		return index.Int{}
+	}
+
+	h := topNHeap{index: ix, columns: columns, heap: make([]int, 0, n)}
+	for i := range ix {
+		if len(h.heap) < n {
+			h.heap = append(h.heap, i)
+			h.up(len(h.heap) - 1)
+		} else if h.less(i, h.heap[0]) {
+			h.heap[0] = i
+			h.down(0, n)
+		}
+	}
+
+	// Pop the greatest element into the end until the heap is sorted
+	for last := n - 1; last > 0; last-- {
+		h.heap[0], h.heap[last] = h.heap[last], h.heap[0]
+		h.down(0, last)
+	}
+
+	result := make(index.Int, n)
+	for i, pos := range h.heap {
+		result[i] = ix[pos]
+	}
+	return result
+}
@@ -0,0 +1,600 @@
+package qframe
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/tobgu/qframe/config/csv"
+	"github.com/tobgu/qframe/config/eval"
+	"github.com/tobgu/qframe/config/groupby"
+	"github.com/tobgu/qframe/config/newqf"
+	qfio "github.com/tobgu/qframe/internal/io"
+	"github.com/tobgu/qframe/types"
+)
+
+// LazyFrame records operations into a logical plan instead of executing them
+// directly. The plan is optimized and executed into a QFrame by Collect.
+//
+// The following optimizations are applied:
+//   - Filters are moved as close to the source as possible and consecutive
+//     filters are fused into one. Filters that reach the source are applied
+//     directly after reading. Note that the source is still read in full,
+//     for CSV input all rows are parsed, only the following operations
+//     benefit from the rows removed.
+//   - Only the columns needed by later operations are read from the source,
+//     this saves parsing and allocation for CSV input. Evals are executed
+//     also if the column produced is never used since they may fail.
+//   - Slices are moved before Select and Eval, and a Slice directly after a
+//     Sort limits the sort to the top rows.
+//
+// The result of Collect is the same as if the operations had been executed
+// directly on a QFrame with the exception that rows that compare equal in
+// a sort may end up in a different order.
+//
+// A LazyFrame is immutable, every operation returns a new LazyFrame.
+type LazyFrame struct {
+	source lazySource
+	ops    []lazyOp
+}
+
+// Lazy returns a LazyFrame with qf as source.
+func Lazy(qf QFrame) LazyFrame {
+	return LazyFrame{source: frameSource{qf: qf}}
+}
+
+// LazyCSV returns a LazyFrame with CSV data taken from reader as source.
+// The configuration is the same as for ReadCSV. Since the data is read from
+// reader when collected the LazyFrame can only be collected once.
+func LazyCSV(reader io.Reader, confFuncs ...csv.ConfigFunc) LazyFrame {
+	return LazyFrame{source: csvSource{reader: reader, confFuncs: confFuncs}}
+}
+
+func (lf LazyFrame) with(op lazyOp) LazyFrame {
+	ops := make([]lazyOp, len(lf.ops), len(lf.ops)+1)
+	copy(ops, lf.ops)
+	return LazyFrame{source: lf.source, ops: append(ops, op)}
+}
+
+// Filter records a QFrame.Filter operation.
+func (lf LazyFrame) Filter(clause FilterClause) LazyFrame {
+	return lf.with(filterOp{clause: clause})
+}
+
+// Select records a QFrame.Select operation.
+func (lf LazyFrame) Select(columns ...string) LazyFrame {
+	return lf.with(selectOp{columns: columns})
+}
+
+// Eval records a QFrame.Eval operation.
+func (lf LazyFrame) Eval(dstCol string, expr Expression, ff ...eval.ConfigFunc) LazyFrame {
+	return lf.with(evalOp{dstCol: dstCol, expr: expr, confFuncs: ff})
+}
+
+// Sort records a QFrame.Sort operation.
+func (lf LazyFrame) Sort(orders ...Order) LazyFrame {
+	return lf.with(sortOp{orders: orders})
+}
+
+// Slice records a QFrame.Slice operation.
+func (lf LazyFrame) Slice(start, end int) LazyFrame {
+	return lf.with(sliceOp{start: start, end: end})
+}
+
+// LazyGrouper is the lazy counterpart of Grouper.
+type LazyGrouper struct {
+	frame     LazyFrame
+	configFns []groupby.ConfigFunc
+}
+
+// GroupBy records a QFrame.GroupBy operation. It must be followed by Aggregate.
+func (lf LazyFrame) GroupBy(configFns ...groupby.ConfigFunc) LazyGrouper {
+	return LazyGrouper{frame: lf, configFns: configFns}
+}
+
+// Aggregate records a Grouper.Aggregate operation.
+func (lg LazyGrouper) Aggregate(aggs ...Aggregation) LazyFrame {
+	return lg.frame.with(aggregateOp{configFns: lg.configFns, config: groupby.NewConfig(lg.configFns), aggs: aggs})
+}
+
+// Collect optimizes the plan and executes it.
+func (lf LazyFrame) Collect() QFrame {
+	scan, ops := lf.optimize()
+	qf := scan.execute()
+	for _, op := range ops {
+		qf = op.execute(qf)
+	}
+	return qf
+}
+
+// Explain returns a description of the optimized plan, one operation per
+// line starting with the last operation. Each operation is indented one
+// level deeper than the operation using its result.
+func (lf LazyFrame) Explain() string {
+	scan, ops := lf.optimize()
+	buf := new(bytes.Buffer)
+	for i := len(ops) - 1; i >= 0; i-- {
+		fmt.Fprintf(buf, "%s%s\n", strings.Repeat("  ", len(ops)-1-i), ops[i])
+	}
+	fmt.Fprintf(buf, "%s%s\n", strings.Repeat("  ", len(ops)), scan)
+	return buf.String()
+}
+
+// optimize returns the scan of the source and the operations to execute on the
+// result of the scan.
+func (lf LazyFrame) optimize() (scanOp, []lazyOp) {
+	ops := make([]lazyOp, len(lf.ops))
+	copy(ops, lf.ops)
+
+	scan := scanOp{source: lf.source}
+	ops = pushDownFilters(&scan, ops)
+	ops = pushDownSlices(ops)
+	ops = limitSorts(ops)
+	ops = pushDownProjections(&scan, ops)
+	return scan, ops
+}
+
+// pushDownFilters moves filters towards the source, fusing consecutive filters.
+func pushDownFilters(scan *scanOp, ops []lazyOp) []lazyOp {
+	result := make([]lazyOp, 0, len(ops))
+	for _, op := range ops {
+		f, ok := op.(filterOp)
+		if !ok {
+			result = append(result, op)
+			continue
+		}
+
+		columns := clauseColumns(f.clause)
+		pos := len(result)
+		for pos > 0 && canFilterBefore(result[pos-1], columns) {
+			pos--
+		}
+
+		switch {
+		case pos == 0:
+			scan.filter = andClauses(scan.filter, f.clause)
+		case isFilter(result[pos-1]):
+			result[pos-1] = filterOp{clause: andClauses(result[pos-1].(filterOp).clause, f.clause)}
+		default:
+			result = append(result[:pos], append([]lazyOp{f}, result[pos:]...)...)
+		}
+	}
+	return result
+}
+
+func isFilter(op lazyOp) bool {
+	_, ok := op.(filterOp)
+	return ok
+}
+
+// canFilterBefore returns true if a filter referencing columns can be
+// executed before op without changing the result.
+func canFilterBefore(op lazyOp, columns []string) bool {
+	switch o := op.(type) {
+	case selectOp:
+		// Keep the unknown column error if filtering on a column not selected
+		return containsAll(o.columns, columns)
+	case evalOp:
+		return !contains(columns, o.dstCol)
+	case sortOp:
+		return true
+	case aggregateOp:
+		return len(columns) > 0 && containsAll(o.config.Columns, columns)
+	default:
+		return false
+	}
+}
+
+func andClauses(clause1, clause2 FilterClause) FilterClause {
+	if clause1 == nil {
+		return clause2
+	}
+
+	if and, ok := clause1.(AndClause); ok && and.err == nil {
+		clauses := make([]FilterClause, len(and.subClauses), len(and.subClauses)+1)
+		copy(clauses, and.subClauses)
+		return And(append(clauses, clause2)...)
+	}
+
+	return And(clause1, clause2)
+}
+
+// pushDownSlices moves slices before row wise operations.
+func pushDownSlices(ops []lazyOp) []lazyOp {
+	for i := 1; i < len(ops); i++ {
+		if _, ok := ops[i].(sliceOp); !ok {
+			continue
+		}
+
+		for pos := i; pos > 0 && isRowWise(ops[pos-1]); pos-- {
+			ops[pos-1], ops[pos] = ops[pos], ops[pos-1]
+		}
+	}
+	return ops
+}
+
+func isRowWise(op lazyOp) bool {
+	switch op.(type) {
+	case selectOp, evalOp:
+		return true
+	default:
+		return false
+	}
+}
+
+// limitSorts limits sorts followed by a slice to the rows within the slice.
+func limitSorts(ops []lazyOp) []lazyOp {
+	for i := 1; i < len(ops); i++ {
+		slice, ok := ops[i].(sliceOp)
+		if !ok {
+			continue
+		}
+
+		if s, ok := ops[i-1].(sortOp); ok && slice.end >= 0 && (s.limit == 0 || slice.end < s.limit) {
+			// The slice is kept since it may drop rows at the
+			// start and reports errors for invalid bounds.
+			s.limit = slice.end
+			ops[i-1] = s
+		}
+	}
+	return ops
+}
+
+// pushDownProjections restricts the columns read by the scan to those needed
+// by the operations.
+func pushDownProjections(scan *scanOp, ops []lazyOp) []lazyOp {
+	// nil means that all columns are needed
+	var required map[string]bool
+	result := make([]lazyOp, 0, len(ops))
+	for i := len(ops) - 1; i >= 0; i-- {
+		switch o := ops[i].(type) {
+		case selectOp:
+			required = stringSet(o.columns)
+		case filterOp:
+			addColumns(required, clauseColumns(o.clause))
+		case evalOp:
+			// Unused evals are kept, dropping them would hide their errors
+			if required != nil {
+				delete(required, o.dstCol)
+				addColumns(required, exprColumns(o.expr))
+			}
+		case sortOp:
+			for _, order := range o.orders {
+				addColumns(required, []string{order.Column})
+			}
+		case aggregateOp:
+			required = stringSet(o.config.Columns)
+			for _, agg := range o.aggs {
+				required[agg.Column] = true
+			}
+		}
+		result = append(result, ops[i])
+	}
+
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+
+	if required != nil {
+		if scan.filter != nil {
+			addColumns(required, clauseColumns(scan.filter))
+		}
+
+		scan.columns = make([]string, 0, len(required))
+		for name := range required {
+			scan.columns = append(scan.columns, name)
+		}
+		sort.Strings(scan.columns)
+	}
+
+	return result
+}
+
+func stringSet(strs []string) map[string]bool {
+	result := make(map[string]bool, len(strs))
+	addColumns(result, strs)
+	return result
+}
+
+func addColumns(set map[string]bool, columns []string) {
+	if set == nil {
+		return
+	}
+
+	for _, c := range columns {
+		set[c] = true
+	}
+}
+
+func contains(strs []string, s string) bool {
+	for _, x := range strs {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAll(strs []string, subset []string) bool {
+	for _, s := range subset {
+		if !contains(strs, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// clauseColumns returns the names of all columns referenced by clause.
+func clauseColumns(clause FilterClause) []string {
+	var result []string
+	switch c := clause.(type) {
+	case Filter:
+		result = append(result, c.Column)
+		if name, ok := c.Arg.(types.ColumnName); ok {
+			result = append(result, string(name))
+		}
+	case AndClause:
+		for _, sc := range c.subClauses {
+			result = append(result, clauseColumns(sc)...)
+		}
+	case OrClause:
+		for _, sc := range c.subClauses {
+			result = append(result, clauseColumns(sc)...)
+		}
+	case NotClause:
+		result = clauseColumns(c.subClause)
+	}
+	return result
+}
+
+// exprColumns returns the names of all columns referenced by expr.
+func exprColumns(expr Expression) []string {
+	switch e := expr.(type) {
+	case colExpr:
+		return []string{string(e.srcCol)}
+	case unaryExpr:
+		return []string{string(e.srcCol)}
+	case colConstExpr:
+		return []string{string(e.srcCol)}
+	case colColExpr:
+		return []string{string(e.srcCol1), string(e.srcCol2)}
+	case exprExpr1:
+		return exprColumns(e.expr)
+	case exprExpr2:
+		return append(exprColumns(e.lhs), exprColumns(e.rhs)...)
+	default:
+		return nil
+	}
+}
+
+func exprString(expr Expression) string {
+	value := func(v interface{}) string {
+		switch t := v.(type) {
+		case string:
+			return fmt.Sprintf("%q", t)
+		case *string:
+			if t == nil {
+				return "null"
+			}
+			return fmt.Sprintf("%q", *t)
+		default:
+			return fmt.Sprint(t)
+		}
+	}
+
+	switch e := expr.(type) {
+	case colExpr:
+		return string(e.srcCol)
+	case constExpr:
+		return value(e.value)
+	case unaryExpr:
+		return fmt.Sprintf("[%q, %s]", e.operation, e.srcCol)
+	case colConstExpr:
+		return fmt.Sprintf("[%q, %s, %s]", e.operation, e.srcCol, value(e.value))
+	case colColExpr:
+		return fmt.Sprintf("[%q, %s, %s]", e.operation, e.srcCol1, e.srcCol2)
+	case exprExpr1:
+		return fmt.Sprintf("[%q, %s]", e.operation, exprString(e.expr))
+	case exprExpr2:
+		return fmt.Sprintf("[%q, %s, %s]", e.operation, exprString(e.lhs), exprString(e.rhs))
+	default:
+		if expr.Err() != nil {
+			return expr.Err().Error()
+		}
+		return fmt.Sprint(expr)
+	}
+}
+
+// lazySource is the source of the data of a LazyFrame.
+type lazySource interface {
+	fmt.Stringer
+
+	// read returns the data of the source. Only columns are
+	// returned, if not nil. Unknown columns are ignored.
+	read(columns []string) QFrame
+}
+
+type frameSource struct {
+	qf QFrame
+}
+
+func (s frameSource) read(columns []string) QFrame {
+	if columns == nil || s.qf.Err != nil {
+		return s.qf
+	}
+
+	selected := make([]string, 0, len(columns))
+	for _, name := range s.qf.ColumnNames() {
+		if contains(columns, name) {
+			selected = append(selected, name)
+		}
+	}
+	return s.qf.Select(selected...)
+}
+
+func (s frameSource) String() string {
+	return "QFrame"
+}
+
+type csvSource struct {
+	reader    io.Reader
+	confFuncs []csv.ConfigFunc
+}
+
+func (s csvSource) read(columns []string) QFrame {
+	conf := qfio.CSVConfig(csv.NewConfig(s.confFuncs))
+	conf.Columns = columns
+	data, names, err := qfio.ReadCSV(s.reader, conf)
+	if err != nil {
+		return QFrame{Err: err}
+	}
+
+	return New(data, newqf.ColumnOrder(names...))
+}
+
+func (s csvSource) String() string {
+	return "CSV"
+}
+
+// scanOp reads the source and applies any filter pushed down to it.
+type scanOp struct {
+	source  lazySource
+	columns []string
+	filter  FilterClause
+}
+
+func (o scanOp) execute() QFrame {
+	qf := o.source.read(o.columns)
+	if o.filter != nil {
+		qf = qf.Filter(o.filter)
+	}
+	return qf
+}
+
+func (o scanOp) String() string {
+	s := "Scan " + o.source.String()
+	if o.columns != nil {
+		s += fmt.Sprintf(" columns=%v", o.columns)
+	}
+	if o.filter != nil {
+		s += " filter=" + o.filter.String()
+	}
+	return s
+}
+
+// lazyOp is an operation in the plan of a LazyFrame.
+type lazyOp interface {
+	fmt.Stringer
+	execute(qf QFrame) QFrame
+}
+
+type filterOp struct {
+	clause FilterClause
+}
+
+func (o filterOp) execute(qf QFrame) QFrame {
+	return qf.Filter(o.clause)
+}
+
+func (o filterOp) String() string {
+	return "Filter " + o.clause.String()
+}
+
+type selectOp struct {
+	columns []string
+}
+
+func (o selectOp) execute(qf QFrame) QFrame {
+	return qf.Select(o.columns...)
+}
+
+func (o selectOp) String() string {
+	return fmt.Sprintf("Select %v", o.columns)
+}
+
+type evalOp struct {
+	dstCol    string
+	expr      Expression
+	confFuncs []eval.ConfigFunc
+}
+
+func (o evalOp) execute(qf QFrame) QFrame {
+	return qf.Eval(o.dstCol, o.expr, o.confFuncs...)
+}
+
+func (o evalOp) String() string {
+	return fmt.Sprintf("Eval %s = %s", o.dstCol, exprString(o.expr))
+}
+
+type sortOp struct {
+	orders []Order
+
+	// limit is the number of rows needed from the
+	// start of the sorted QFrame, 0 if all are needed.
+	limit int
+}
+
+func (o sortOp) execute(qf QFrame) QFrame {
+	if o.limit > 0 {
+		return qf.TopN(o.limit, o.orders...)
+	}
+	return qf.Sort(o.orders...)
+}
+
+func (o sortOp) String() string {
+	orders := make([]string, len(o.orders))
+	for i, order := range o.orders {
+		orders[i] = order.Column
+		if order.Reverse {
+			orders[i] += " desc"
+		}
+		if order.NullLast {
+			orders[i] += " nulls last"
+		}
+	}
+
+	if o.limit > 0 {
+		return fmt.Sprintf("TopN %d [%s]", o.limit, strings.Join(orders, ", "))
+	}
+	return fmt.Sprintf("Sort [%s]", strings.Join(orders, ", "))
+}
+
+type sliceOp struct {
+	start, end int
+}
+
+func (o sliceOp) execute(qf QFrame) QFrame {
+	return qf.Slice(o.start, o.end)
+}
+
+func (o sliceOp) String() string {
+	return fmt.Sprintf("Slice [%d, %d)", o.start, o.end)
+}
+
+type aggregateOp struct {
+	configFns []groupby.ConfigFunc
+	config    groupby.Config
+	aggs      []Aggregation
+}
+
+func (o aggregateOp) execute(qf QFrame) QFrame {
+	return qf.GroupBy(o.configFns...).Aggregate(o.aggs...)
+}
+
+func (o aggregateOp) String() string {
+	aggs := make([]string, len(o.aggs))
+	for i, agg := range o.aggs {
+		fn := "func"
+		if s, ok := agg.Fn.(string); ok {
+			fn = s
+		}
+
+		aggs[i] = fmt.Sprintf("%s(%s)", fn, agg.Column)
+		if agg.As != "" {
+			aggs[i] += " as " + agg.As
+		}
+	}
+	return fmt.Sprintf("Aggregate by %v [%s]", o.config.Columns, strings.Join(aggs, ", "))
+}
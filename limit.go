@@ -0,0 +1,67 @@
+package qframe
+
+import (
+	"context"
+
+	"github.com/tobgu/qframe/internal/cancel"
+	"github.com/tobgu/qframe/qerrors"
+)
+
+// WithContext returns a QFrame whose long running operations, Filter, GroupBy, Distinct
+// and Sort, check ctx periodically. An operation that is running, or started, when ctx
+// is done is aborted. The returned QFrame then has Err set to an error for which
+// qerrors.IsCanceled returns true. The context is inherited by QFrames derived from
+// the returned QFrame.
+func (qf QFrame) WithContext(ctx context.Context) QFrame {
+	result := qf.withIndex(qf.index)
+	result.ctx = ctx
+	return result
+}
+
+// Context returns the context set by WithContext, context.Background() if none has been set.
+func (qf QFrame) Context() context.Context {
+	if qf.ctx == nil {
+		return context.Background()
+	}
+	return qf.ctx
+}
+
+// WithMemoryLimit returns a QFrame that limits the memory used by Filter, GroupBy,
+// Distinct and Sort. Before running, and for GroupBy also while running, the size of
+// the QFrame as given by ByteSize plus an estimate of the memory that the operation
+// will allocate is compared to limit. If the limit would be exceeded the operation
+// is aborted. The returned QFrame then has Err set to an error for which
+// qerrors.IsMemoryLimit returns true. The limit is inherited by QFrames derived from
+// the returned QFrame. limit <= 0 removes any limit.
+//
+// The estimates do not include memory allocated by functions passed to the operations
+// or memory that is only allocated temporarily, the limit is hence approximate.
+func (qf QFrame) WithMemoryLimit(limit int) QFrame {
+	if limit < 0 {
+		limit = 0
+	}
+	result := qf.withIndex(qf.index)
+	result.memoryLimit = limit
+	return result
+}
+
+func (qf QFrame) checkCanceled(operation string) error {
+	if err := cancel.Err(qf.ctx); err != nil {
+		return qerrors.Propagate(operation, err)
+	}
+	return nil
+}
+
+// checkMemory checks that the QFrame together with extra bytes fits within the memory
+// limit. The remaining number of bytes is returned, 0 if there is no limit.
+func (qf QFrame) checkMemory(operation string, extra int) (int, error) {
+	if qf.memoryLimit == 0 {
+		return 0, nil
+	}
+
+	required := qf.ByteSize() + extra
+	if required > qf.memoryLimit {
+		return 0, qerrors.MemoryLimit(operation, required, qf.memoryLimit)
+	}
+	return qf.memoryLimit - required, nil
+}
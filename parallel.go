@@ -0,0 +1,126 @@
+package qframe
+
+import (
+	"sync/atomic"
+
+	"github.com/tobgu/qframe/config/groupby"
+	"github.com/tobgu/qframe/internal/cancel"
+	"github.com/tobgu/qframe/internal/column"
+	"github.com/tobgu/qframe/internal/grouper"
+	"github.com/tobgu/qframe/internal/index"
+	"github.com/tobgu/qframe/internal/math/integer"
+	"github.com/tobgu/qframe/internal/parallel"
+)
+
+var defaultParallelism int64 = 1
+
+// SetParallelism sets the number of goroutines used by filters, applies and
+// aggregations on QFrames that have no parallelism of their own set through
+// WithParallelism. n < 1 is treated as 1 which means that all operations run
+// serially in the calling goroutine, this is the default.
+//
+// Functions passed to filters, applies and aggregations must be safe for
+// concurrent use when n > 1.
+func SetParallelism(n int) {
+	if n < 1 {
+		n = 1
+	}
+	atomic.StoreInt64(&defaultParallelism, int64(n))
+}
+
+// Parallelism returns the number of goroutines set by SetParallelism.
+func Parallelism() int {
+	return int(atomic.LoadInt64(&defaultParallelism))
+}
+
+// WithParallelism returns a QFrame that uses n goroutines for filters, applies and
+// aggregations. The setting is inherited by QFrames derived from the returned
+// QFrame. n = 0 reverts to the setting of SetParallelism.
+//
+// The rows are split into consecutive chunks that are processed concurrently.
+// The result is the same as if the operation had been executed serially. Small
+// QFrames are processed in fewer chunks, or serially, since the cost of
+// coordinating the goroutines would outweigh the gains.
+//
+// Functions passed to filters, applies and aggregations must be safe for
+// concurrent use when n > 1.
+func (qf QFrame) WithParallelism(n int) QFrame {
+	if n < 0 {
+		n = 0
+	}
+	result := qf.withIndex(qf.index)
+	result.parallelism = n
+	return result
+}
+
+func workers(parallelism int) int {
+	if parallelism > 0 {
+		return parallelism
+	}
+	return Parallelism()
+}
+
+func (qf QFrame) workers() int {
+	return workers(qf.parallelism)
+}
+
+func (g Grouper) workers() int {
+	n := workers(g.parallelism)
+	if n == 1 {
+		return n
+	}
+
+	// Groups may be small, make sure that there is enough
+	// data in total to be worth splitting.
+	rowCount := 0
+	for _, ix := range g.indices {
+		rowCount += len(ix)
+	}
+
+	if max := rowCount / parallel.MinChunkSize; n > max {
+		n = max
+	}
+
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// filterColumn applies the filter to c for all rows in the index, the result
+// is stored in bIndex which is aligned with the index.
+func (qf QFrame) filterColumn(c column.Column, comparator interface{}, arg interface{}, bIndex index.Bool) error {
+	return parallel.For(len(qf.index), qf.workers(), func(lo, hi int) error {
+		if qf.ctx == nil {
+			return c.Filter(qf.index[lo:hi], comparator, arg, bIndex[lo:hi])
+		}
+
+		// Filter in blocks to be able to abort when the context is done
+		for {
+			if err := cancel.Err(qf.ctx); err != nil {
+				return err
+			}
+
+			end := integer.Min(lo+cancel.Interval, hi)
+			if err := c.Filter(qf.index[lo:end], comparator, arg, bIndex[lo:end]); err != nil {
+				return err
+			}
+
+			if end == hi {
+				return nil
+			}
+			lo = end
+		}
+	})
+}
+
+// grouperConfig creates the configuration for grouping the QFrame. memoryLeft
+// is the memory left for the hash tables when the QFrame has a memory limit.
+func (qf QFrame) grouperConfig(config groupby.Config, memoryLeft int) grouper.Config {
+	conf := grouper.Config{Workers: qf.workers(), Unordered: config.Unordered, Context: qf.ctx}
+	if qf.memoryLimit > 0 {
+		// Nothing left must still be a limit
+		conf.MemoryLimit = integer.Max(memoryLeft, 1)
+	}
+	return conf
+}
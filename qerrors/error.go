@@ -1,6 +1,70 @@
 package qerrors
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Kind categorizes errors by their cause. A Kind is an error in itself which
+// makes it possible to test for kinds using errors.Is:
+//
+//	if errors.Is(err, qerrors.UnknownColumn) {
+//	    ...
+//	}
+type Kind int
+
+const (
+	// Other is the kind of errors that have not been categorized.
+	Other Kind = iota
+
+	// UnknownColumn is the kind of errors caused by references to columns
+	// that do not exist.
+	UnknownColumn
+
+	// TypeMismatch is the kind of errors caused by values, functions or
+	// columns of types that are not valid for the operation.
+	TypeMismatch
+
+	// InvalidArgument is the kind of errors caused by arguments or
+	// configuration with invalid values.
+	InvalidArgument
+
+	// IO is the kind of errors caused by failures to read or write data.
+	IO
+
+	// Parse is the kind of errors caused by input data that could not be
+	// parsed. The position of the offending data is available through
+	// Line and Column when known.
+	Parse
+)
+
+var kindNames = map[Kind]string{
+	Other:           "other",
+	UnknownColumn:   "unknown column",
+	TypeMismatch:    "type mismatch",
+	InvalidArgument: "invalid argument",
+	IO:              "io",
+	Parse:           "parse",
+}
+
+// Error returns the name of the kind.
+func (k Kind) Error() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("kind %d", int(k))
+}
+
+// New creates a new error of kind k.
+func (k Kind) New(operation, reason string, params ...interface{}) Error {
+	return Error{kind: k, operation: operation, reason: fmt.Sprintf(reason, params...)}
+}
+
+// Propagate propagates an existing error with added context as an error of kind k.
+func (k Kind) Propagate(operation string, err error) Error {
+	return Error{kind: k, operation: operation, source: err}
+}
 
 // Error holds data identifying an error that occurred
 // while executing a qframe operation.
@@ -8,6 +72,9 @@ type Error struct {
 	source    error
 	operation string
 	reason    string
+	kind      Kind
+	line      int
+	column    int
 }
 
 // Error returns a string representation of the error.
@@ -17,6 +84,13 @@ func (e Error) Error() string {
 		result += ": " + e.reason
 	}
 
+	if e.line > 0 {
+		result += fmt.Sprintf(", line %d", e.line)
+		if e.column > 0 {
+			result += fmt.Sprintf(", column %d", e.column)
+		}
+	}
+
 	if e.source != nil {
 		result += fmt.Sprintf(" (%s)", e.source)
 	}
@@ -24,16 +98,114 @@ func (e Error) Error() string {
 	return result
 }
 
+// Unwrap returns the error that e was propagated from, if any.
+func (e Error) Unwrap() error {
+	return e.source
+}
+
+// Is reports whether e is of the Kind target. Errors propagated without a
+// kind of their own have the kind of the error they were propagated from.
+func (e Error) Is(target error) bool {
+	k, ok := target.(Kind)
+	return ok && e.kind != Other && e.kind == k
+}
+
+// Kind returns the kind of the error. If e has not been categorized the kind
+// of the error that it was propagated from is returned.
+func (e Error) Kind() Kind {
+	if e.kind != Other {
+		return e.kind
+	}
+	return KindOf(e.source)
+}
+
+// Operation returns the name of the operation that failed.
+func (e Error) Operation() string {
+	return e.operation
+}
+
+// Line returns the line, starting at 1, of the input that caused a Parse
+// error. If e has no position the line of the error that it was propagated
+// from is returned. 0 is returned if the line is unknown.
+func (e Error) Line() int {
+	line, _ := e.position()
+	return line
+}
+
+// Column returns the column, starting at 1, of the input that caused a
+// Parse error. If e has no position the column of the error that it was
+// propagated from is returned. 0 is returned if the column is unknown.
+func (e Error) Column() int {
+	_, column := e.position()
+	return column
+}
+
+func (e Error) position() (line, column int) {
+	if e.line == 0 {
+		var src Error
+		if errors.As(e.source, &src) {
+			return src.position()
+		}
+	}
+	return e.line, e.column
+}
+
+// At returns a copy of e with the position of the offending input set.
+// 0 is used for unknown positions.
+func (e Error) At(line, column int) Error {
+	e.line, e.column = line, column
+	return e
+}
+
 // New creates a new error instance.
 func New(operation, reason string, params ...interface{}) Error {
-	return Error{operation: operation, reason: fmt.Sprintf(reason, params...)}
+	return Other.New(operation, reason, params...)
 }
 
 // Propagate propagates an existing error with added context.
+// The kind of err is retained.
 func Propagate(operation string, err error) Error {
-	return Error{operation: operation, source: err}
+	return Other.Propagate(operation, err)
+}
+
+// KindOf returns the kind of err, or of the first error in its chain
+// that has been categorized. Other is returned for all other errors.
+func KindOf(err error) Kind {
+	for err != nil {
+		var e Error
+		if !errors.As(err, &e) {
+			return Other
+		}
+
+		if e.kind != Other {
+			return e.kind
+		}
+		err = e.source
+	}
+	return Other
 }
 
-// Error types:
-//   - Type error
-//   - Input error (which would basically always be the case...)
+// ErrMemoryLimit is the source of errors caused by operations that would
+// exceed the memory limit of a QFrame.
+var ErrMemoryLimit = errors.New("memory limit exceeded")
+
+// MemoryLimit creates a new error for an operation that is estimated to
+// require more than limit bytes.
+func MemoryLimit(operation string, required, limit int) Error {
+	return Error{
+		operation: operation,
+		reason:    fmt.Sprintf("estimated to require %d bytes, limit is %d bytes", required, limit),
+		source:    ErrMemoryLimit}
+}
+
+// IsCanceled returns true if err was caused by the cancellation, or expired
+// deadline, of the context of an operation.
+func IsCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// IsMemoryLimit returns true if err was caused by an operation that would
+// exceed the memory limit of a QFrame.
+func IsMemoryLimit(err error) bool {
+	return errors.Is(err, ErrMemoryLimit)
+}
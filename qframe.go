@@ -1,8 +1,9 @@
 package qframe
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
-	stdcsv "encoding/csv"
 	"fmt"
 	"io"
 	"reflect"
@@ -13,7 +14,9 @@ import (
 
 	"github.com/tobgu/qframe/config/csv"
 	"github.com/tobgu/qframe/config/eval"
+	"github.com/tobgu/qframe/config/fixedwidth"
 	"github.com/tobgu/qframe/config/groupby"
+	"github.com/tobgu/qframe/config/json"
 	"github.com/tobgu/qframe/config/newqf"
 	qsql "github.com/tobgu/qframe/config/sql"
 	"github.com/tobgu/qframe/filter"
@@ -37,6 +40,22 @@ import (
 	_ "github.com/mauricelam/genny/generic"
 )
 
+// execution holds settings that control how operations are executed.
+// They are inherited by QFrames derived from the QFrame.
+type execution struct {
+	// parallelism is the number of goroutines used by operations on
+	// the QFrame, 0 means that the global setting is used.
+	parallelism int
+
+	// ctx is checked periodically by long running operations, nil
+	// means that the operations cannot be canceled.
+	ctx context.Context
+
+	// memoryLimit is the number of bytes that the QFrame and the memory
+	// allocated by an operation may occupy, 0 means no limit.
+	memoryLimit int
+}
+
 type namedColumn struct {
 	column.Column
 	name string
@@ -54,6 +73,8 @@ type QFrame struct {
 	columnsByName map[string]namedColumn
 	index         index.Int
 
+	execution
+
 	// Err indicates that an error has occurred while running an operation.
 	// If Err is set it will prevent any further operations from being executed
 	// on the QFrame.
@@ -61,11 +82,11 @@ type QFrame struct {
 }
 
 func (qf QFrame) withErr(err error) QFrame {
-	return QFrame{Err: err, columns: qf.columns, columnsByName: qf.columnsByName, index: qf.index}
+	return QFrame{Err: err, columns: qf.columns, columnsByName: qf.columnsByName, index: qf.index, execution: qf.execution}
 }
 
 func (qf QFrame) withIndex(ix index.Int) QFrame {
-	return QFrame{Err: qf.Err, columns: qf.columns, columnsByName: qf.columnsByName, index: ix}
+	return QFrame{Err: qf.Err, columns: qf.columns, columnsByName: qf.columnsByName, index: ix, execution: qf.execution}
 }
 
 // ConstString describes a string column with only one value. It can be used
@@ -153,7 +174,7 @@ func createColumn(name string, data interface{}, config *newqf.Config) (column.C
 	case column.Column:
 		localS = t
 	default:
-		return nil, qerrors.New("createColumn", `unknown column data type "%s" for column "%s"`, reflect.TypeOf(t), name)
+		return nil, qerrors.TypeMismatch.New("createColumn", `unknown column data type "%s" for column "%s"`, reflect.TypeOf(t), name)
 	}
 	return localS, nil
 }
@@ -179,12 +200,12 @@ func New(data map[string]types.DataSlice, fns ...newqf.ConfigFunc) QFrame {
 	}
 
 	if len(config.ColumnOrder) != len(data) {
-		return QFrame{Err: qerrors.New("New", "number of columns and columns order length do not match, %d, %d", len(config.ColumnOrder), len(data))}
+		return QFrame{Err: qerrors.InvalidArgument.New("New", "number of columns and columns order length do not match, %d, %d", len(config.ColumnOrder), len(data))}
 	}
 
 	for _, name := range config.ColumnOrder {
 		if _, ok := data[name]; !ok {
-			return QFrame{Err: qerrors.New("New", `column "%s" in column order does not exist`, name)}
+			return QFrame{Err: qerrors.UnknownColumn.New("New", `column "%s" in column order does not exist`, name)}
 		}
 	}
 
@@ -206,7 +227,7 @@ func New(data map[string]types.DataSlice, fns ...newqf.ConfigFunc) QFrame {
 		}
 
 		if firstLen != currentLen {
-			return QFrame{Err: qerrors.New("New", "different lengths on columns not allowed")}
+			return QFrame{Err: qerrors.InvalidArgument.New("New", "different lengths on columns not allowed")}
 		}
 	}
 
@@ -216,7 +237,7 @@ func New(data map[string]types.DataSlice, fns ...newqf.ConfigFunc) QFrame {
 			colNames = append(colNames, k)
 		}
 
-		return QFrame{Err: qerrors.New("New", "unknown enum columns: %v", colNames)}
+		return QFrame{Err: qerrors.UnknownColumn.New("New", "unknown enum columns: %v", colNames)}
 	}
 
 	return QFrame{columns: columns, columnsByName: colByName, index: index.NewAscending(uint32(currentLen)), Err: nil}
@@ -249,8 +270,8 @@ func (qf QFrame) Filter(clause FilterClause) QFrame {
 	return clause.filter(qf)
 }
 
-func unknownCol(c string) string {
-	return fmt.Sprintf(`unknown column: "%s"`, c)
+func unknownCol(operation, c string) qerrors.Error {
+	return qerrors.UnknownColumn.New(operation, `unknown column: "%s"`, c)
 }
 
 func (qf QFrame) filter(filters ...filter.Filter) QFrame {
@@ -258,17 +279,22 @@ func (qf QFrame) filter(filters ...filter.Filter) QFrame {
 		return qf
 	}
 
+	// Boolean index and the resulting index
+	if _, err := qf.checkMemory("Filter", 5*qf.Len()); err != nil {
+		return qf.withErr(err)
+	}
+
 	bIndex := index.NewBool(qf.index.Len())
 	for _, f := range filters {
 		s, ok := qf.columnsByName[f.Column]
 		if !ok {
-			return qf.withErr(qerrors.New("Filter", unknownCol(f.Column)))
+			return qf.withErr(unknownCol("Filter", f.Column))
 		}
 
 		if name, ok := f.Arg.(types.ColumnName); ok {
 			argC, ok := qf.columnsByName[string(name)]
 			if !ok {
-				return qf.withErr(qerrors.New("Filter", `unknown argument column: "%s"`, name))
+				return qf.withErr(qerrors.UnknownColumn.New("Filter", `unknown argument column: "%s"`, name))
 			}
 
 			// Allow comparison of int and float columns by temporarily promoting int column to float.
@@ -295,7 +321,7 @@ func (qf QFrame) filter(filters ...filter.Filter) QFrame {
 			done := false
 			if sComp, ok := f.Comparator.(string); ok {
 				if inverse, ok := filter.Inverse[sComp]; ok {
-					err = s.Filter(qf.index, inverse, f.Arg, bIndex)
+					err = qf.filterColumn(s.Column, inverse, f.Arg, bIndex)
 
 					// Assume inverse not implemented in case of error here
 					if err == nil {
@@ -307,7 +333,7 @@ func (qf QFrame) filter(filters ...filter.Filter) QFrame {
 			if !done {
 				// TODO: This branch needs proper testing
 				invBIndex := index.NewBool(bIndex.Len())
-				err = s.Filter(qf.index, f.Comparator, f.Arg, invBIndex)
+				err = qf.filterColumn(s.Column, f.Comparator, f.Arg, invBIndex)
 				if err == nil {
 					for i, x := range bIndex {
 						if !x {
@@ -317,7 +343,7 @@ func (qf QFrame) filter(filters ...filter.Filter) QFrame {
 				}
 			}
 		} else {
-			err = s.Filter(qf.index, f.Comparator, f.Arg, bIndex)
+			err = qf.filterColumn(s.Column, f.Comparator, f.Arg, bIndex)
 		}
 
 		if err != nil {
@@ -380,7 +406,14 @@ type Order struct {
 
 // Sort returns a new QFrame sorted according to the orders specified.
 //
+// The sort algorithm is picked based on the input. Sorts by a single int, float or
+// enum column are stable, they use radix sort or, for QFrames of less than 256 rows,
+// insertion sort. Other sorts use quicksort or, for large QFrames configured to run
+// in parallel using WithParallelism or SetParallelism, a parallel merge sort. The
+// order of equal rows is undefined for sorts by other columns or by several columns.
+//
 // Time complexity O(m * n * log(n)) where m = number of columns to sort by, n = number of rows in QFrame.
+// Time complexity O(n) for radix sort.
 func (qf QFrame) Sort(orders ...Order) QFrame {
 	if qf.Err != nil {
 		return qf
@@ -390,20 +423,91 @@ func (qf QFrame) Sort(orders ...Order) QFrame {
 		return qf
 	}
 
+	comparables, err := qf.orderComparables("Sort", orders)
+	if err != nil {
+		return qf.withErr(err)
+	}
+
+	var keyer column.SortKeyer
+	if len(orders) == 1 {
+		keyer, _ = qf.columnsByName[orders[0].Column].Column.(column.SortKeyer)
+	}
+	radix := keyer != nil && qf.Len() >= qfsort.RadixThreshold
+
+	// The new index and a buffer used for merging or, for radix
+	// sorts, the keys, the key buffer and an index buffer.
+	extra := 8 * qf.Len()
+	if radix {
+		extra = 24 * qf.Len()
+	}
+
+	if _, err := qf.checkMemory("Sort", extra); err != nil {
+		return qf.withErr(err)
+	}
+
+	if err := qf.checkCanceled("Sort"); err != nil {
+		return qf.withErr(err)
+	}
+
+	newDf := qf.withIndex(qf.index.Copy())
+	if radix {
+		o := orders[0]
+		if err := qfsort.RadixSort(qf.ctx, newDf.index, keyer.SortKeys(newDf.index, o.Reverse, o.NullLast)); err != nil {
+			return qf.withErr(qerrors.Propagate("Sort", err))
+		}
+		return newDf
+	}
+
+	sorter := qfsort.New(newDf.index, comparables).WithContext(qf.ctx)
+	if keyer != nil {
+		// Too few rows for radix sort to pay off, keep the sort stable
+		sorter.StableSort()
+		return newDf
+	}
+
+	if err := sorter.ParallelSort(qf.workers()); err != nil {
+		return qf.withErr(qerrors.Propagate("Sort", err))
+	}
+	return newDf
+}
+
+// TopN returns a new QFrame with the first n rows of the QFrame if it was sorted according
+// to the orders specified. Only the selected rows are sorted which makes TopN much cheaper
+// than a Sort followed by a Slice when n is small compared to the number of rows. Rows
+// that compare equal keep their relative order. All rows are returned, sorted, if n is
+// larger than the number of rows.
+//
+// Time complexity O(m * n * log(k)) where m = number of columns to sort by, n = number of rows in QFrame,
+// k = number of rows to return.
+func (qf QFrame) TopN(n int, orders ...Order) QFrame {
+	if qf.Err != nil {
+		return qf
+	}
+
+	if n < 0 {
+		return qf.withErr(qerrors.InvalidArgument.New("TopN", "n must be non negative, was %d", n))
+	}
+
+	comparables, err := qf.orderComparables("TopN", orders)
+	if err != nil {
+		return qf.withErr(err)
+	}
+
+	return qf.withIndex(qfsort.TopN(qf.index, comparables, n))
+}
+
+func (qf QFrame) orderComparables(operation string, orders []Order) ([]column.Comparable, error) {
 	comparables := make([]column.Comparable, 0, len(orders))
 	for _, o := range orders {
 		s, ok := qf.columnsByName[o.Column]
 		if !ok {
-			return qf.withErr(qerrors.New("Sort", unknownCol(o.Column)))
+			return nil, unknownCol(operation, o.Column)
 		}
 
 		comparables = append(comparables, s.Comparable(o.Reverse, false, o.NullLast))
 	}
 
-	newDf := qf.withIndex(qf.index.Copy())
-	sorter := qfsort.New(newDf.index, comparables)
-	sorter.Sort()
-	return newDf
+	return comparables, nil
 }
 
 // ColumnNames returns the names of all columns in the QFrame.
@@ -470,7 +574,8 @@ func (qf QFrame) comparables(columns []string, orders []Order, groupByNull bool)
 // Distinct returns a new QFrame that only contains unique rows with respect to the specified columns.
 // If no columns are given Distinct will return rows where allow columns are unique.
 //
-// The order of the returned rows in undefined.
+// The returned rows are in the order in which they first appear in the QFrame
+// unless the ordering has been relaxed using groupby.Unordered.
 //
 // Time complexity O(m * n) where m = number of columns to compare for distinctness, n = number of rows.
 func (qf QFrame) Distinct(configFns ...groupby.ConfigFunc) QFrame {
@@ -486,21 +591,30 @@ func (qf QFrame) Distinct(configFns ...groupby.ConfigFunc) QFrame {
 
 	for _, col := range config.Columns {
 		if _, ok := qf.columnsByName[col]; !ok {
-			return qf.withErr(qerrors.New("Distinct", unknownCol(col)))
+			return qf.withErr(unknownCol("Distinct", col))
 		}
 	}
 
+	// The resulting index and, if partitioned, hashes and partitions of the rows
+	memoryLeft, err := qf.checkMemory("Distinct", 12*qf.Len())
+	if err != nil {
+		return qf.withErr(err)
+	}
+
 	columns := qf.columnsOrAll(config.Columns)
 	orders := qf.orders(columns)
 	comparables := qf.comparables(columns, orders, config.GroupByNull)
-	newIx := grouper.Distinct(qf.index, comparables)
+	newIx, err := grouper.Distinct(qf.index, comparables, qf.grouperConfig(config, memoryLeft))
+	if err != nil {
+		return qf.withErr(qerrors.Propagate("Distinct", err))
+	}
 	return qf.withIndex(newIx)
 }
 
 func (qf QFrame) checkColumns(operation string, columns []string) error {
 	for _, col := range columns {
 		if _, ok := qf.columnsByName[col]; !ok {
-			return qerrors.New(operation, unknownCol(col))
+			return unknownCol(operation, col)
 		}
 	}
 
@@ -551,14 +665,19 @@ func (qf QFrame) Select(columns ...string) QFrame {
 		newColumns[i] = s
 	}
 
-	return QFrame{columns: newColumns, columnsByName: newColumnsByName, index: qf.index}
+	return QFrame{columns: newColumns, columnsByName: newColumnsByName, index: qf.index, execution: qf.execution}
 }
 
 // GroupBy groups rows together for which the values of specified columns are the same.
 // Aggregations on the groups can be executed on the returned Grouper object.
 // Leaving out columns to group by will make one large group over which aggregations can be done.
 //
-// The order of the rows in the Grouper is undefined.
+// The groups are ordered by the position of their first row in the QFrame unless the
+// ordering has been relaxed using groupby.Unordered. The rows within each group keep
+// the order of the QFrame.
+//
+// Large QFrames are grouped in parallel, by hash partitioning of the rows, when
+// configured using WithParallelism or SetParallelism.
 //
 // Time complexity O(m * n) where m = number of columns to group by, n = number of rows.
 func (qf QFrame) GroupBy(configFns ...groupby.ConfigFunc) Grouper {
@@ -572,7 +691,7 @@ func (qf QFrame) GroupBy(configFns ...groupby.ConfigFunc) Grouper {
 		return Grouper{Err: err}
 	}
 
-	g := Grouper{columns: qf.columns, columnsByName: qf.columnsByName, groupedColumns: config.Columns}
+	g := Grouper{columns: qf.columns, columnsByName: qf.columnsByName, groupedColumns: config.Columns, execution: qf.execution}
 	if qf.Len() == 0 {
 		return g
 	}
@@ -582,9 +701,18 @@ func (qf QFrame) GroupBy(configFns ...groupby.ConfigFunc) Grouper {
 		return g
 	}
 
+	// The group indices and, if partitioned, hashes and partitions of the rows
+	memoryLeft, err := qf.checkMemory("GroupBy", 12*qf.Len())
+	if err != nil {
+		return Grouper{Err: err}
+	}
+
 	orders := qf.orders(config.Columns)
 	comparables := qf.comparables(config.Columns, orders, config.GroupByNull)
-	indices, stats := grouper.GroupBy(qf.index, comparables)
+	indices, stats, err := grouper.GroupBy(qf.index, comparables, qf.grouperConfig(config, memoryLeft))
+	if err != nil {
+		return Grouper{Err: qerrors.Propagate("GroupBy", err)}
+	}
 	g.indices = indices
 	g.Stats = GroupStats(stats)
 	return g
@@ -602,7 +730,7 @@ func (qf QFrame) Rolling(fn types.SliceFuncOrBuiltInId, dstCol, srcCol string, c
 
 	namedColumn, ok := qf.columnsByName[srcCol]
 	if !ok {
-		return qf.withErr(qerrors.New("Rolling", unknownCol(srcCol)))
+		return qf.withErr(unknownCol("Rolling", srcCol))
 	}
 
 	srcColumn := namedColumn.Column
@@ -685,15 +813,15 @@ func (qf QFrame) Slice(start, end int) QFrame {
 	}
 
 	if start < 0 {
-		return qf.withErr(qerrors.New("Slice", "start must be non negative"))
+		return qf.withErr(qerrors.InvalidArgument.New("Slice", "start must be non negative"))
 	}
 
 	if start > end {
-		return qf.withErr(qerrors.New("Slice", "start must not be greater than end"))
+		return qf.withErr(qerrors.InvalidArgument.New("Slice", "start must not be greater than end"))
 	}
 
 	if end > qf.Len() {
-		return qf.withErr(qerrors.New("Slice", "end must not be greater than qframe length"))
+		return qf.withErr(qerrors.InvalidArgument.New("Slice", "end must not be greater than qframe length"))
 	}
 
 	return qf.withIndex(qf.index[start:end])
@@ -741,7 +869,7 @@ func (qf QFrame) Copy(dstCol, srcCol string) QFrame {
 
 	namedColumn, ok := qf.columnsByName[srcCol]
 	if !ok {
-		return qf.withErr(qerrors.New("Copy", unknownCol(srcCol)))
+		return qf.withErr(unknownCol("Copy", srcCol))
 	}
 
 	if dstCol == srcCol {
@@ -802,7 +930,7 @@ func (qf QFrame) apply0(fn types.DataFuncOrBuiltInId, dstCol string) QFrame {
 	case types.ColumnName:
 		return qf.Copy(dstCol, string(t))
 	default:
-		return qf.withErr(qerrors.New("apply0", "unknown apply type: %v", reflect.TypeOf(fn)))
+		return qf.withErr(qerrors.TypeMismatch.New("apply0", "unknown apply type: %v", reflect.TypeOf(fn)))
 	}
 
 	c, err := createColumn(dstCol, data, newqf.NewConfig(nil))
@@ -821,12 +949,12 @@ func (qf QFrame) apply1(fn types.DataFuncOrBuiltInId, dstCol, srcCol string) QFr
 
 	namedColumn, ok := qf.columnsByName[srcCol]
 	if !ok {
-		return qf.withErr(qerrors.New("apply1", unknownCol(srcCol)))
+		return qf.withErr(unknownCol("apply1", srcCol))
 	}
 
 	srcColumn := namedColumn.Column
 
-	sliceResult, err := srcColumn.Apply1(fn, qf.index)
+	sliceResult, err := srcColumn.Apply1(fn, qf.index, qf.workers())
 	if err != nil {
 		return qf.withErr(qerrors.Propagate("apply1", err))
 	}
@@ -844,7 +972,7 @@ func (qf QFrame) apply1(fn types.DataFuncOrBuiltInId, dstCol, srcCol string) QFr
 	case column.Column:
 		resultColumn = t
 	default:
-		return qf.withErr(qerrors.New("apply1", "unexpected type of new columns %#v", t))
+		return qf.withErr(qerrors.TypeMismatch.New("apply1", "unexpected type of new columns %#v", t))
 	}
 
 	return qf.setColumn(dstCol, resultColumn)
@@ -858,17 +986,17 @@ func (qf QFrame) apply2(fn types.DataFuncOrBuiltInId, dstCol, srcCol1, srcCol2 s
 
 	namedSrcColumn1, ok := qf.columnsByName[srcCol1]
 	if !ok {
-		return qf.withErr(qerrors.New("apply2", unknownCol(srcCol1)))
+		return qf.withErr(unknownCol("apply2", srcCol1))
 	}
 	srcColumn1 := namedSrcColumn1.Column
 
 	namedSrcColumn2, ok := qf.columnsByName[srcCol2]
 	if !ok {
-		return qf.withErr(qerrors.New("apply2", unknownCol(srcCol2)))
+		return qf.withErr(unknownCol("apply2", srcCol2))
 	}
 	srcColumn2 := namedSrcColumn2.Column
 
-	resultColumn, err := srcColumn1.Apply2(fn, srcColumn2, qf.index)
+	resultColumn, err := srcColumn1.Apply2(fn, srcColumn2, qf.index, qf.workers())
 	if err != nil {
 		return qf.withErr(qerrors.Propagate("apply2", err))
 	}
@@ -980,7 +1108,7 @@ func (qf QFrame) Eval(dstCol string, expr Expression, ff ...eval.ConfigFunc) QFr
 func (qf QFrame) functionType(name string) (types.FunctionType, error) {
 	namedColumn, ok := qf.columnsByName[name]
 	if !ok {
-		return types.FunctionTypeUndefined, qerrors.New("functionType", unknownCol(name))
+		return types.FunctionTypeUndefined, unknownCol("functionType", name)
 	}
 
 	return namedColumn.FunctionType(), nil
@@ -1045,193 +1173,333 @@ func (qf QFrame) Append(qff ...QFrame) QFrame {
 //
 // Time complexity O(m * n) where m = number of columns, n = number of rows.
 func ReadCSV(reader io.Reader, confFuncs ...csv.ConfigFunc) QFrame {
-	conf := csv.NewConfig(confFuncs)
-	data, columns, err := qfio.ReadCSV(reader, qfio.CSVConfig(conf))
-	if err != nil {
-		return QFrame{Err: err}
-	}
-
-	return New(data, newqf.ColumnOrder(columns...))
+	return readCSV(reader, csv.NewConfig(confFuncs))
 }
 
 // ReadJSON returns a QFrame with data, in JSON format, taken from reader.
+// Gzip and zstd compressed input is detected and decompressed automatically.
+// confFuncs are passed to New.
 //
 // Time complexity O(m * n) where m = number of columns, n = number of rows.
 func ReadJSON(reader io.Reader, confFuncs ...newqf.ConfigFunc) QFrame {
-	data, err := qfio.UnmarshalJSON(reader)
+	return ReadJSONWithConfig(reader, json.QFrame(confFuncs...))
+}
+
+// ReadJSONWithConfig returns a QFrame with data, in JSON format, taken from reader,
+// configured by the functions in package json.
+//
+// Time complexity O(m * n) where m = number of columns, n = number of rows.
+func ReadJSONWithConfig(reader io.Reader, confFuncs ...json.ConfigFunc) QFrame {
+	return readJSON(reader, json.NewConfig(confFuncs))
+}
+
+// ReadFixedWidth returns a QFrame with data, in fixed width text format, taken from reader.
+// The position of each column must be specified using fixedwidth.Columns. Column data types
+// are auto detected if not explicitly specified.
+//
+// Time complexity O(m * n) where m = number of columns, n = number of rows.
+func ReadFixedWidth(reader io.Reader, confFuncs ...fixedwidth.ConfigFunc) QFrame {
+	return readFixedWidth(reader, fixedwidth.NewConfig(confFuncs))
+}
+
+// ReadBinary returns a QFrame from data, in the binary format written by ToBinary, taken from reader.
+//
+// Time complexity O(m * n) where m = number of columns, n = number of rows. The column
+// data is used as read from reader without any parsing.
+func ReadBinary(reader io.Reader) QFrame {
+	return readBinary(reader)
+}
+
+func fromBinary(b []byte) QFrame {
+	names, columns, err := qfio.DecodeBinary(b)
 	if err != nil {
 		return QFrame{Err: err}
 	}
 
-	return New(data, confFuncs...)
+	data := make(map[string]types.DataSlice, len(names))
+	for i, name := range names {
+		data[name] = columns[i]
+	}
+
+	return New(data, newqf.ColumnOrder(names...))
+}
+
+// MappedQFrame is a QFrame whose column data is backed directly by a memory mapped file.
+// It is created using OpenBinary.
+type MappedQFrame struct {
+	QFrame
+	file *qfio.MappedFile
+}
+
+// Close releases the memory mapping. Neither the MappedQFrame nor any QFrame derived from it
+// may be used after Close has been called, doing so will result in undefined behaviour
+// (typically a crash). Copy the data, eg. using ReadBinary or by applying a function to each
+// column, to retain it past Close.
+func (mqf MappedQFrame) Close() error {
+	if mqf.file == nil {
+		return nil
+	}
+
+	if err := mqf.file.Close(); err != nil {
+		return qerrors.IO.Propagate("Close", err)
+	}
+
+	return nil
+}
+
+// OpenBinary returns a MappedQFrame by memory mapping a file in the binary format
+// written by ToBinary. The column data is not copied, int, float, bool and string
+// columns reference the mapped memory directly. The file is mapped read only and may
+// be shared between processes.
+//
+// The returned frame must be closed using Close when no longer needed.
+//
+// Time complexity O(m) where m = number of columns for int and float columns. Other
+// column types are validated which is O(n) where n = number of rows.
+func OpenBinary(path string) MappedQFrame {
+	file, err := qfio.MapFile(path)
+	if err != nil {
+		return MappedQFrame{QFrame: QFrame{Err: qerrors.Propagate("OpenBinary", err)}}
+	}
+
+	qf := fromBinary(file.Data)
+	if qf.Err != nil {
+		_ = file.Close()
+		return MappedQFrame{QFrame: qf}
+	}
+
+	return MappedQFrame{QFrame: qf, file: file}
 }
 
 // ReadSQL returns a QFrame by reading the results of a SQL query.
+// Columns holding only NULLs, with no type reported by the driver,
+// are returned as string columns.
 func ReadSQL(tx *sql.Tx, confFuncs ...qsql.ConfigFunc) QFrame {
 	return ReadSQLWithArgs(tx, []interface{}{}, confFuncs...)
 }
 
 // ReadSQLWithArgs returns a QFrame by reading the results of a SQL query with arguments
 func ReadSQLWithArgs(tx *sql.Tx, queryArgs []interface{}, confFuncs ...qsql.ConfigFunc) QFrame {
+	return readSQL(tx, queryArgs, qsql.NewConfig(confFuncs))
+}
+
+const defaultSQLChunkSize = 10000
+
+// SQLIterator iterates over the results of a SQL query, one QFrame
+// of at most the configured chunk size at a time. All QFrames have
+// the same columns with the same types, decided by the first chunk.
+//
+// Since the type of a column cannot change between chunks int columns
+// are read as float columns, with NaN for NULL, unless the driver reports
+// the column as NOT NULL. A column holding only NULLs in the first chunk, with
+// no type reported by the driver, is a string column and values in later
+// chunks are read as strings.
+// Enum columns keep the values of earlier chunks, new values are added
+// last in order of appearance.
+//
+// Usage:
+//
+//	it := qframe.ReadSQLChunks(ctx, tx, nil, qsql.Query("SELECT * FROM test"))
+//	defer it.Close()
+//	for it.Next() {
+//		f := it.QFrame()
+//		...
+//	}
+//	if it.Err() != nil {
+//		...
+//	}
+type SQLIterator struct {
+	ctx       context.Context
+	stmt      *sql.Stmt
+	rows      *sql.Rows
+	reader    *qfsqlio.Reader
+	chunkSize int
+	current   QFrame
+	err       error
+}
+
+// ReadSQLChunks executes a SQL query with arguments and returns an iterator
+// over the results, read in chunks of qsql.ChunkSize rows (default 10000).
+// Only one chunk is held in memory by the iterator at any time.
+//
+// Reading stops with an error if ctx is cancelled.
+func ReadSQLChunks(ctx context.Context, tx *sql.Tx, queryArgs []interface{}, confFuncs ...qsql.ConfigFunc) *SQLIterator {
 	conf := qsql.NewConfig(confFuncs)
-	// The MySQL can only use prepared
-	// statements to return "native" types, otherwise
-	// everything is returned as text.
-	// see https://github.com/go-sql-driver/mysql/issues/407
-	stmt, err := tx.Prepare(conf.Query)
-	if err != nil {
-		return QFrame{Err: err}
+	it := &SQLIterator{ctx: ctx, chunkSize: conf.ChunkSize}
+	if it.chunkSize < 1 {
+		it.chunkSize = defaultSQLChunkSize
 	}
-	defer stmt.Close()
-	rows, err := stmt.Query(queryArgs...)
+
+	stmt, err := tx.PrepareContext(ctx, conf.Query)
 	if err != nil {
-		return QFrame{Err: err}
+		it.err = qerrors.IO.Propagate("ReadSQLChunks", err)
+		return it
 	}
-	data, columns, err := qfsqlio.ReadSQL(rows, qfsqlio.SQLConfig(conf))
+	it.stmt = stmt
+
+	rows, err := stmt.QueryContext(ctx, queryArgs...)
 	if err != nil {
-		return QFrame{Err: err}
+		it.err = qerrors.IO.Propagate("ReadSQLChunks", err)
+		return it
 	}
-	return New(data, newqf.ColumnOrder(columns...))
+	it.rows = rows
+
+	it.reader, it.err = qfsqlio.NewReader(rows, qfsqlio.SQLConfig(conf))
+	return it
 }
 
-// ToCSV writes the data in the QFrame, in CSV format, to writer.
-//
-// Time complexity O(m * n) where m = number of rows, n = number of columns.
-//
-// This is function is currently unoptimized. It could probably be a lot speedier with
-// a custom written CSV writer that handles quoting etc. differently.
-func (qf QFrame) ToCSV(writer io.Writer, confFuncs ...csv.ToConfigFunc) error {
-	conf := csv.NewToConfig(confFuncs)
-	if qf.Err != nil {
-		return qerrors.Propagate("ToCSV", qf.Err)
+// Next reads the next chunk of rows. It returns false when there are
+// no more rows or an error has occurred, see Err.
+func (it *SQLIterator) Next() bool {
+	it.current = QFrame{}
+	if it.err != nil || it.reader.Done() {
+		return false
 	}
 
-	var iterCols []namedColumn
-	if conf.Columns != nil {
-		if len(conf.Columns) != len(qf.columns) {
-			return qerrors.New("ToCSV", fmt.Sprintf("wrong number of columns: expected: %d", len(qf.columns)))
-		}
-		iterCols = make([]namedColumn, len(qf.columns))
-		for i := range conf.Columns {
-			cName := conf.Columns[i]
-			if col, ok := qf.columnsByName[cName]; !ok {
-				return qerrors.New("ToCSV", fmt.Sprintf("%s: column does not exist in QFrame", cName))
-			} else {
-				iterCols[i] = col
-			}
-		}
-	} else {
-		iterCols = qf.columns
+	if err := it.ctx.Err(); err != nil {
+		it.err = qerrors.Propagate("ReadSQLChunks", err)
+		return false
 	}
 
-	row := make([]string, 0, len(iterCols))
-	for _, s := range iterCols {
-		row = append(row, s.name)
+	data, count, err := it.reader.Read(it.chunkSize)
+	if err != nil {
+		it.err = qerrors.Propagate("ReadSQLChunks", err)
+		return false
 	}
-	columns := make([]column.Column, 0, len(qf.columns))
-	for _, name := range row {
-		columns = append(columns, qf.columnsByName[name])
+
+	if count == 0 {
+		return false
 	}
 
-	w := stdcsv.NewWriter(writer)
+	it.current = New(data, newqf.ColumnOrder(it.reader.Columns()...))
+	it.err = it.current.Err
+	return it.err == nil
+}
 
-	if conf.Header {
-		err := w.Write(row)
-		if err != nil {
-			return err
-		}
+// QFrame returns the chunk read by the latest call to Next.
+func (it *SQLIterator) QFrame() QFrame {
+	return it.current
+}
+
+// Err returns the error, if any, that occurred during iteration.
+func (it *SQLIterator) Err() error {
+	return it.err
+}
+
+// Close releases the resources held by the iterator. It is safe
+// to call Close multiple times.
+func (it *SQLIterator) Close() error {
+	var err error
+	if it.rows != nil {
+		err = it.rows.Close()
 	}
 
-	for i := 0; i < qf.Len(); i++ {
-		row = row[:0]
-		for _, col := range columns {
-			row = append(row, col.StringAt(qf.index[i], ""))
-		}
-		err := w.Write(row)
-		if err != nil {
-			return err
+	if it.stmt != nil {
+		if stmtErr := it.stmt.Close(); err == nil {
+			err = stmtErr
 		}
 	}
 
-	w.Flush()
-	return nil
+	return err
 }
 
-// ToJSON writes the data in the QFrame, in JSON format one record per row, to writer.
+// ToCSV writes the data in the QFrame, in CSV format, to writer.
 //
 // Time complexity O(m * n) where m = number of rows, n = number of columns.
-func (qf QFrame) ToJSON(writer io.Writer) error {
-	if qf.Err != nil {
-		return qerrors.Propagate("ToJSON", qf.Err)
-	}
-
-	colByteNames := make([][]byte, len(qf.columns))
-	for i, col := range qf.columns {
-		colByteNames[i] = qfstrings.QuotedBytes(col.name)
-	}
+func (qf QFrame) ToCSV(writer io.Writer, confFuncs ...csv.ToConfigFunc) error {
+	return writeCSV(qf, writer, csv.NewToConfig(confFuncs))
+}
 
-	// Custom JSON generator for records due to performance reasons
-	jsonBuf := []byte{'['}
-	_, err := writer.Write(jsonBuf)
-	if err != nil {
-		return err
-	}
+// ToJSON writes the data in the QFrame, in JSON format one record per row, to writer.
+//
+// Time complexity O(m * n) where m = number of rows, n = number of columns.
+func (qf QFrame) ToJSON(writer io.Writer, confFuncs ...json.ToConfigFunc) (err error) {
+	return writeJSON(qf, writer, json.NewToConfig(confFuncs))
+}
 
-	for i, ix := range qf.index {
-		jsonBuf = jsonBuf[:0]
-		if i > 0 {
-			jsonBuf = append(jsonBuf, byte(','))
-		}
+// ToBinary writes the data in the QFrame, in a versioned, columnar, binary format, to writer.
+// The format stores the column data the same way as it is held in memory which makes
+// reading it back using ReadBinary very fast. It is intended for caching and exchanging
+// frames between processes, not for long term storage.
+//
+// Time complexity O(m * n) where m = number of rows, n = number of columns.
+func (qf QFrame) ToBinary(writer io.Writer) error {
+	return writeBinary(qf, writer)
+}
 
-		jsonBuf = append(jsonBuf, byte('{'))
+// ToSQL writes a QFrame into a SQL database.
+//
+// Rows are inserted using a prepared statement, use BatchSize in
+// config/sql to insert multiple rows per statement and ConflictColumns
+// to update existing rows. Rows with the same values in the conflict
+// columns are never inserted by the same statement, a batch is ended
+// early if needed. PostgreSQL would otherwise fail the statement
+// since a row cannot be updated twice by the same statement.
+func (qf QFrame) ToSQL(tx *sql.Tx, confFuncs ...qsql.ConfigFunc) error {
+	return writeSQL(qf, tx, qfsqlio.SQLConfig(qsql.NewConfig(confFuncs)))
+}
 
-		for j, col := range qf.columns {
-			jsonBuf = append(jsonBuf, colByteNames[j]...)
-			jsonBuf = append(jsonBuf, byte(':'))
-			jsonBuf = col.AppendByteStringAt(jsonBuf, ix)
-			jsonBuf = append(jsonBuf, byte(','))
+// conflictFreeEnd returns the end, at most end, of the batch starting at start such
+// that no two rows in the batch have the same key as given by keyBuilders.
+func (qf QFrame) conflictFreeEnd(keyBuilders []qfsqlio.ArgBuilder, start, end int) int {
+	seen := make(map[string]struct{}, end-start)
+	buf := new(bytes.Buffer)
+	for i := start; i < end; i++ {
+		buf.Reset()
+		for _, b := range keyBuilders {
+			// Strings are compared by value, not by pointer
+			switch v := b(qf.index, i).(type) {
+			case *string:
+				if v == nil {
+					buf.WriteString("n|")
+				} else {
+					fmt.Fprintf(buf, "s%d:%s|", len(*v), *v)
+				}
+			default:
+				fmt.Fprintf(buf, "v%#v|", v)
+			}
 		}
 
-		if jsonBuf[len(jsonBuf)-1] == ',' {
-			jsonBuf = jsonBuf[:len(jsonBuf)-1]
+		if _, ok := seen[buf.String()]; ok {
+			return i
 		}
+		seen[buf.String()] = struct{}{}
+	}
+	return end
+}
 
-		jsonBuf = append(jsonBuf, byte('}'))
-
-		_, err = writer.Write(jsonBuf)
-		if err != nil {
-			return err
-		}
+// CreateTableSQL returns a CREATE TABLE statement for a table matching the
+// columns of the QFrame. Table name, dialect and primary key are given
+// using the corresponding options in config/sql.
+//
+// Column types are mapped to the closest SQL type of the dialect. Int and bool
+// columns, which cannot hold null, and primary key columns are declared NOT NULL.
+// Enum columns become ENUM columns in MySQL and text columns with a CHECK
+// constraint on the allowed values in other dialects. Enum columns without
+// values become plain text columns.
+func (qf QFrame) CreateTableSQL(confFuncs ...qsql.ConfigFunc) (string, error) {
+	if qf.Err != nil {
+		return "", qerrors.Propagate("CreateTableSQL", qf.Err)
 	}
 
-	_, err = writer.Write([]byte{']'})
-	return err
+	return qf.createTableSQL(qfsqlio.SQLConfig(qsql.NewConfig(confFuncs)))
 }
 
-// ToSQL writes a QFrame into a SQL database.
-func (qf QFrame) ToSQL(tx *sql.Tx, confFuncs ...qsql.ConfigFunc) error {
-	if qf.Err != nil {
-		return qerrors.Propagate("ToSQL", qf.Err)
+func (qf QFrame) createTableSQL(conf qfsqlio.SQLConfig) (string, error) {
+	if err := qf.checkColumns("CreateTableSQL", conf.PrimaryKey); err != nil {
+		return "", err
 	}
-	builders := make([]qfsqlio.ArgBuilder, len(qf.columns))
-	var err error
-	for i, column := range qf.columns {
-		builders[i], err = qfsqlio.NewArgBuilder(column.Column)
-		if err != nil {
-			return qerrors.New("ToSQL", err.Error())
-		}
+
+	if conf.Table == "" {
+		return "", qerrors.InvalidArgument.New("CreateTableSQL", "table name must be specified")
 	}
-	for i := range qf.index {
-		args := make([]interface{}, len(qf.columns))
-		for j, b := range builders {
-			args[j] = b(qf.index, i)
-		}
-		_, err = tx.Exec(qfsqlio.Insert(qf.ColumnNames(), qfsqlio.SQLConfig(qsql.NewConfig(confFuncs))), args...)
-		if err != nil {
-			return qerrors.New("ToSQL", err.Error())
-		}
+
+	columns := make([]column.Column, len(qf.columns))
+	for i, col := range qf.columns {
+		columns[i] = col.Column
 	}
-	return nil
+
+	return qfsqlio.CreateTable(qf.ColumnNames(), columns, conf), nil
 }
 
 // ByteSize returns a best effort estimate of the current size occupied by the QFrame.
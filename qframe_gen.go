@@ -25,12 +25,12 @@ type IntView struct {
 func (qf QFrame) IntView(colName string) (IntView, error) {
 	namedColumn, ok := qf.columnsByName[colName]
 	if !ok {
-		return IntView{}, qerrors.New("IntView", "unknown column: %s", colName)
+		return IntView{}, qerrors.UnknownColumn.New("IntView", "unknown column: %s", colName)
 	}
 
 	col, ok := namedColumn.Column.(icolumn.Column)
 	if !ok {
-		return IntView{}, qerrors.New(
+		return IntView{}, qerrors.TypeMismatch.New(
 			"IntView",
 			"invalid column type, expected: %s, was: %s", "int", namedColumn.DataType())
 	}
@@ -66,12 +66,12 @@ type FloatView struct {
 func (qf QFrame) FloatView(colName string) (FloatView, error) {
 	namedColumn, ok := qf.columnsByName[colName]
 	if !ok {
-		return FloatView{}, qerrors.New("FloatView", "unknown column: %s", colName)
+		return FloatView{}, qerrors.UnknownColumn.New("FloatView", "unknown column: %s", colName)
 	}
 
 	col, ok := namedColumn.Column.(fcolumn.Column)
 	if !ok {
-		return FloatView{}, qerrors.New(
+		return FloatView{}, qerrors.TypeMismatch.New(
 			"FloatView",
 			"invalid column type, expected: %s, was: %s", "float", namedColumn.DataType())
 	}
@@ -107,12 +107,12 @@ type BoolView struct {
 func (qf QFrame) BoolView(colName string) (BoolView, error) {
 	namedColumn, ok := qf.columnsByName[colName]
 	if !ok {
-		return BoolView{}, qerrors.New("BoolView", "unknown column: %s", colName)
+		return BoolView{}, qerrors.UnknownColumn.New("BoolView", "unknown column: %s", colName)
 	}
 
 	col, ok := namedColumn.Column.(bcolumn.Column)
 	if !ok {
-		return BoolView{}, qerrors.New(
+		return BoolView{}, qerrors.TypeMismatch.New(
 			"BoolView",
 			"invalid column type, expected: %s, was: %s", "bool", namedColumn.DataType())
 	}
@@ -148,12 +148,12 @@ type StringView struct {
 func (qf QFrame) StringView(colName string) (StringView, error) {
 	namedColumn, ok := qf.columnsByName[colName]
 	if !ok {
-		return StringView{}, qerrors.New("StringView", "unknown column: %s", colName)
+		return StringView{}, qerrors.UnknownColumn.New("StringView", "unknown column: %s", colName)
 	}
 
 	col, ok := namedColumn.Column.(scolumn.Column)
 	if !ok {
-		return StringView{}, qerrors.New(
+		return StringView{}, qerrors.TypeMismatch.New(
 			"StringView",
 			"invalid column type, expected: %s, was: %s", "string", namedColumn.DataType())
 	}
@@ -189,12 +189,12 @@ type EnumView struct {
 func (qf QFrame) EnumView(colName string) (EnumView, error) {
 	namedColumn, ok := qf.columnsByName[colName]
 	if !ok {
-		return EnumView{}, qerrors.New("EnumView", "unknown column: %s", colName)
+		return EnumView{}, qerrors.UnknownColumn.New("EnumView", "unknown column: %s", colName)
 	}
 
 	col, ok := namedColumn.Column.(ecolumn.Column)
 	if !ok {
-		return EnumView{}, qerrors.New(
+		return EnumView{}, qerrors.TypeMismatch.New(
 			"EnumView",
 			"invalid column type, expected: %s, was: %s", "enum", namedColumn.DataType())
 	}
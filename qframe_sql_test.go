@@ -1,12 +1,18 @@
 package qframe_test
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
+	"fmt"
 	"io"
+	"math"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/tobgu/qframe"
+	"github.com/tobgu/qframe/config/newqf"
 	qsql "github.com/tobgu/qframe/config/sql"
 )
 
@@ -15,6 +21,10 @@ type MockDriver struct {
 	t *testing.T
 	// expected SQL query
 	query string
+	// optional list of expected SQL queries, used
+	// instead of query when more than one query is
+	// expected
+	queries []string
 	// results holds values that are
 	// returned from a database query
 	results struct {
@@ -46,9 +56,10 @@ func (m MockDriver) Open(name string) (driver.Conn, error) {
 		mockQuery: m.mockQuery,
 	}
 	return &MockConn{
-		t:     m.t,
-		stmt:  stmt,
-		query: m.query,
+		t:       m.t,
+		stmt:    stmt,
+		query:   m.query,
+		queries: m.queries,
 	}, nil
 }
 
@@ -74,6 +85,22 @@ func (m MockRows) Close() error { return nil }
 
 func (m MockRows) Columns() []string { return m.columns }
 
+// TypedMockRows reports column type metadata
+// in addition to the column names.
+type TypedMockRows struct {
+	MockRows
+	typeNames []string
+	nullable  []bool
+}
+
+func (m TypedMockRows) ColumnTypeDatabaseTypeName(index int) string {
+	return m.typeNames[index]
+}
+
+func (m TypedMockRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	return m.nullable[index], true
+}
+
 type MockTx struct{}
 
 func (m MockTx) Commit() error { return nil }
@@ -98,6 +125,9 @@ func (s MockStmt) NumInput() int {
 }
 
 func (s *MockStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if len(args) != len(s.values[s.idx]) {
+		s.t.Errorf("arg count %d != %d", len(args), len(s.values[s.idx]))
+	}
 	for i, arg := range args {
 		if s.values[s.idx][i] != arg {
 			s.t.Errorf("arg %t != %t", arg, s.values[s.idx][i])
@@ -115,16 +145,35 @@ func (s MockStmt) Query(args []driver.Value) (driver.Rows, error) {
 	return s.rows, nil
 }
 
+// MultiQueryStmt is used in place of MockStmt when
+// several different queries are expected. The number
+// of inputs is the number of placeholders in the
+// prepared query.
+type MultiQueryStmt struct {
+	*MockStmt
+	numInput int
+}
+
+func (s MultiQueryStmt) NumInput() int { return s.numInput }
+
 type MockQuery func(args []driver.Value) (driver.Rows, error)
 
 type MockConn struct {
-	t     *testing.T
-	query string
-	stmt  *MockStmt
+	t       *testing.T
+	query   string
+	queries []string
+	stmt    *MockStmt
 }
 
 func (m MockConn) Prepare(query string) (driver.Stmt, error) {
-	if query != m.query {
+	if len(m.queries) > 0 {
+		for _, q := range m.queries {
+			if q == query {
+				return MultiQueryStmt{MockStmt: m.stmt, numInput: strings.Count(query, "?") + strings.Count(query, "$")}, nil
+			}
+		}
+		m.t.Errorf("invalid query: %s not in %v", query, m.queries)
+	} else if query != m.query {
 		m.t.Errorf("invalid query: %s != %s", query, m.query)
 	}
 	return m.stmt, nil
@@ -135,6 +184,25 @@ func (c MockConn) Begin() (driver.Tx, error) {
 	return &MockTx{}, nil
 }
 
+// NopDriver implements a SQL driver that accepts
+// and discards all statements.
+type NopDriver struct{}
+
+func (NopDriver) Open(name string) (driver.Conn, error) { return NopConn{}, nil }
+
+type NopConn struct{}
+
+func (NopConn) Prepare(query string) (driver.Stmt, error) { return NopStmt{}, nil }
+func (NopConn) Close() error                              { return nil }
+func (NopConn) Begin() (driver.Tx, error)                 { return MockTx{}, nil }
+
+type NopStmt struct{}
+
+func (NopStmt) Close() error                                    { return nil }
+func (NopStmt) NumInput() int                                   { return -1 }
+func (NopStmt) Exec(args []driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (NopStmt) Query(args []driver.Value) (driver.Rows, error)  { return nil, io.EOF }
+
 var (
 	_ driver.Conn = (*MockConn)(nil)
 	_ driver.Rows = (*MockRows)(nil)
@@ -163,6 +231,236 @@ func TestQFrame_ToSQL(t *testing.T) {
 	assertNotErr(t, qf.ToSQL(tx, qsql.Table("test")))
 }
 
+func TestQFrame_ToSQLBatch(t *testing.T) {
+	table := []struct {
+		name    string
+		config  []qsql.ConfigFunc
+		queries []string
+		values  [][]driver.Value
+	}{
+		{
+			name:   "batch with partial last batch",
+			config: []qsql.ConfigFunc{qsql.BatchSize(2), qsql.Postgres()},
+			queries: []string{
+				`INSERT INTO "test" ("COL1","COL2") VALUES ($1,$2),($3,$4);`,
+				`INSERT INTO "test" ("COL1","COL2") VALUES ($1,$2);`,
+			},
+			values: [][]driver.Value{
+				{int64(1), "one", int64(2), "two"},
+				{int64(3), "three"},
+			},
+		},
+		{
+			name:   "batch limited by param limit",
+			config: []qsql.ConfigFunc{qsql.BatchSize(100), qsql.MySQL(), qsql.ParamLimit(5)},
+			queries: []string{
+				"INSERT INTO `test` (`COL1`,`COL2`) VALUES (?,?),(?,?);",
+				"INSERT INTO `test` (`COL1`,`COL2`) VALUES (?,?);",
+			},
+			values: [][]driver.Value{
+				{int64(1), "one", int64(2), "two"},
+				{int64(3), "three"},
+			},
+		},
+		{
+			name:    "batch larger than frame",
+			config:  []qsql.ConfigFunc{qsql.BatchSize(10), qsql.SQLite()},
+			queries: []string{`INSERT INTO "test" ("COL1","COL2") VALUES (?,?),(?,?),(?,?);`},
+			values: [][]driver.Value{
+				{int64(1), "one", int64(2), "two", int64(3), "three"},
+			},
+		},
+	}
+
+	for i, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			dvr := MockDriver{t: t, queries: tc.queries}
+			dvr.args.values = tc.values
+			driverName := fmt.Sprintf("TestToSQLBatch%d", i)
+			sql.Register(driverName, dvr)
+			db, _ := sql.Open(driverName, "")
+			tx, _ := db.Begin()
+			qf := qframe.New(map[string]interface{}{
+				"COL1": []int{1, 2, 3},
+				"COL2": []string{"one", "two", "three"},
+			})
+			assertNotErr(t, qf.ToSQL(tx, append(tc.config, qsql.Table("test"))...))
+		})
+	}
+}
+
+func TestQFrame_ToSQLUpsertBatch(t *testing.T) {
+	// Rows with the same conflict key must not be part of the same statement
+	update := ` ON CONFLICT ("COL1") DO UPDATE SET "COL2"=excluded."COL2";`
+	dvr := MockDriver{t: t}
+	dvr.queries = []string{
+		`INSERT INTO "test" ("COL1","COL2") VALUES ($1,$2),($3,$4),($5,$6)` + update,
+		`INSERT INTO "test" ("COL1","COL2") VALUES ($1,$2),($3,$4)` + update,
+		`INSERT INTO "test" ("COL1","COL2") VALUES ($1,$2)` + update,
+	}
+	dvr.args.values = [][]driver.Value{
+		{int64(1), "one", int64(2), "two"},
+		{int64(1), "uno", int64(3), "three", int64(2), "dos"},
+		{int64(3), "tres"},
+	}
+	sql.Register("TestToSQLUpsertBatch", dvr)
+	db, _ := sql.Open("TestToSQLUpsertBatch", "")
+	tx, _ := db.Begin()
+	qf := qframe.New(map[string]interface{}{
+		"COL1": []int{1, 2, 1, 3, 2, 3},
+		"COL2": []string{"one", "two", "uno", "three", "dos", "tres"},
+	})
+	assertNotErr(t, qf.ToSQL(tx, qsql.Table("test"), qsql.Postgres(), qsql.ConflictColumns("COL1"), qsql.BatchSize(3)))
+}
+
+func TestQFrame_ToSQLUpsertBatchStringKey(t *testing.T) {
+	update := ` ON CONFLICT ("COL2") DO UPDATE SET "COL1"=excluded."COL1";`
+	dvr := MockDriver{t: t}
+	dvr.queries = []string{
+		`INSERT INTO "test" ("COL1","COL2") VALUES ($1,$2),($3,$4),($5,$6)` + update,
+		`INSERT INTO "test" ("COL1","COL2") VALUES ($1,$2),($3,$4)` + update,
+	}
+	dvr.args.values = [][]driver.Value{
+		{int64(1), "a", int64(2), "b"},
+		{int64(3), "a", int64(4), "c"},
+	}
+	sql.Register("TestToSQLUpsertBatchStringKey", dvr)
+	db, _ := sql.Open("TestToSQLUpsertBatchStringKey", "")
+	tx, _ := db.Begin()
+	qf := qframe.New(map[string]interface{}{
+		"COL1": []int{1, 2, 3, 4},
+		"COL2": []string{"a", "b", "a", "c"},
+	})
+	assertNotErr(t, qf.ToSQL(tx, qsql.Table("test"), qsql.Postgres(), qsql.ConflictColumns("COL2"), qsql.BatchSize(3)))
+}
+
+func TestQFrame_ToSQLUpsert(t *testing.T) {
+	dvr := MockDriver{t: t}
+	dvr.query = `INSERT INTO "test" ("COL1","COL2") VALUES ($1,$2) ON CONFLICT ("COL1") DO UPDATE SET "COL2"=excluded."COL2";`
+	dvr.args.values = [][]driver.Value{
+		{int64(1), "one"},
+		{int64(2), "two"},
+	}
+	sql.Register("TestToSQLUpsert", dvr)
+	db, _ := sql.Open("TestToSQLUpsert", "")
+	tx, _ := db.Begin()
+	qf := qframe.New(map[string]interface{}{
+		"COL1": []int{1, 2},
+		"COL2": []string{"one", "two"},
+	})
+	assertNotErr(t, qf.ToSQL(tx, qsql.Table("test"), qsql.Postgres(), qsql.ConflictColumns("COL1")))
+
+	err := qf.ToSQL(tx, qsql.Table("test"), qsql.Postgres(), qsql.ConflictColumns("COL3"))
+	assertErr(t, err, `unknown column: "COL3"`)
+
+	err = qf.ToSQL(tx, qsql.Table("test"), qsql.Postgres(), qsql.ConflictColumns("COL1"), qsql.UpdateColumns("COL4"))
+	assertErr(t, err, `unknown column: "COL4"`)
+}
+
+func TestQFrame_CreateTableSQL(t *testing.T) {
+	qf := qframe.New(map[string]interface{}{
+		"ID":    []int{1, 2},
+		"NAME":  []string{"one", "two"},
+		"VALUE": []float64{1.5, 2.5},
+		"FLAG":  []bool{true, false},
+		"KIND":  []string{"a", "b'c"},
+	}, newqf.ColumnOrder("ID", "NAME", "VALUE", "FLAG", "KIND"),
+		newqf.Enums(map[string][]string{"KIND": {"a", "b'c"}}))
+	assertNotErr(t, qf.Err)
+
+	table := []struct {
+		name     string
+		config   []qsql.ConfigFunc
+		expected string
+	}{
+		{
+			name:   "postgres",
+			config: []qsql.ConfigFunc{qsql.Postgres(), qsql.PrimaryKey("ID")},
+			expected: `CREATE TABLE "test" ("ID" BIGINT NOT NULL,"NAME" TEXT,"VALUE" DOUBLE PRECISION,"FLAG" BOOLEAN NOT NULL,` +
+				`"KIND" TEXT CHECK ("KIND" IN ('a','b''c')),PRIMARY KEY ("ID"));`,
+		},
+		{
+			name:   "sqlite",
+			config: []qsql.ConfigFunc{qsql.SQLite()},
+			expected: `CREATE TABLE "test" ("ID" INTEGER NOT NULL,"NAME" TEXT,"VALUE" REAL,"FLAG" BOOLEAN NOT NULL,` +
+				`"KIND" TEXT CHECK ("KIND" IN ('a','b''c')));`,
+		},
+		{
+			name:   "mysql",
+			config: []qsql.ConfigFunc{qsql.MySQL(), qsql.PrimaryKey("ID", "NAME")},
+			expected: "CREATE TABLE `test` (`ID` BIGINT NOT NULL,`NAME` VARCHAR(255) NOT NULL,`VALUE` DOUBLE,`FLAG` BOOLEAN NOT NULL," +
+				"`KIND` ENUM('a','b''c'),PRIMARY KEY (`ID`,`NAME`));",
+		},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			ddl, err := qf.CreateTableSQL(append(tc.config, qsql.Table("test"))...)
+			assertNotErr(t, err)
+			if ddl != tc.expected {
+				t.Errorf("Unexpected DDL:\n%s\nExpected:\n%s", ddl, tc.expected)
+			}
+		})
+	}
+
+	_, err := qf.CreateTableSQL(qsql.Table("test"), qsql.PrimaryKey("FOO"))
+	assertErr(t, err, `unknown column: "FOO"`)
+
+	_, err = qf.CreateTableSQL()
+	assertErr(t, err, "table name must be specified")
+
+	// Enums without values are text columns, ENUM() is invalid
+	empty := qframe.New(map[string]interface{}{"KIND": []string{}}, newqf.Enums(map[string][]string{"KIND": nil}))
+	ddl, err := empty.CreateTableSQL(qsql.MySQL(), qsql.Table("test"))
+	assertNotErr(t, err)
+	assertTrue(t, ddl == "CREATE TABLE `test` (`KIND` TEXT);")
+}
+
+func TestQFrame_ToSQLCreateTable(t *testing.T) {
+	dvr := MockDriver{t: t}
+	dvr.queries = []string{
+		`CREATE TABLE "test" ("COL1" INTEGER NOT NULL,"COL2" TEXT);`,
+		`INSERT INTO "test" ("COL1","COL2") VALUES (?,?),(?,?);`,
+	}
+	dvr.args.values = [][]driver.Value{
+		{},
+		{int64(1), "one", int64(2), "two"},
+	}
+	sql.Register("TestToSQLCreateTable", dvr)
+	db, _ := sql.Open("TestToSQLCreateTable", "")
+	tx, _ := db.Begin()
+	qf := qframe.New(map[string]interface{}{
+		"COL1": []int{1, 2},
+		"COL2": []string{"one", "two"},
+	})
+	assertNotErr(t, qf.ToSQL(tx, qsql.Table("test"), qsql.SQLite(), qsql.CreateTable(), qsql.BatchSize(2)))
+}
+
+func BenchmarkQFrame_ToSQL(b *testing.B) {
+	rowCount := 10000
+	qf := qframe.New(map[string]interface{}{
+		"COL1": intSlice(1, rowCount),
+		"COL2": floatSlice(1.5, rowCount),
+		"COL3": stringSlice("foo", rowCount),
+		"COL4": boolSlice(true, rowCount),
+	})
+	sql.Register("BenchmarkToSQL", NopDriver{})
+	db, _ := sql.Open("BenchmarkToSQL", "")
+
+	for _, batchSize := range []int{1, 100, 1000} {
+		b.Run(fmt.Sprintf("BatchSize %d", batchSize), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				tx, _ := db.Begin()
+				if err := qf.ToSQL(tx, qsql.Table("test"), qsql.Postgres(), qsql.BatchSize(batchSize)); err != nil {
+					b.Errorf("Unexpected error: %s", err)
+				}
+				_ = tx.Commit()
+			}
+		})
+	}
+}
+
 func TestQFrame_ReadSQL(t *testing.T) {
 	dvr := MockDriver{t: t}
 	dvr.results.columns = []string{"COL1", "COL2", "COL3", "COL4"}
@@ -208,6 +506,221 @@ func TestQFrame_ReadSQLCoercion(t *testing.T) {
 	assertEquals(t, expected, qf)
 }
 
+func TestQFrame_ReadSQLColumnTypes(t *testing.T) {
+	columns := []string{"COL1", "COL2", "COL3", "COL4", "COL5", "COL6"}
+	typeNames := []string{"BIGINT", "INT4", "NUMERIC", "VARCHAR(10)", "MOOD", "BOOLEAN"}
+	nullable := []bool{false, true, false, true, false, false}
+	ten := "10"
+	table := []struct {
+		name     string
+		values   [][]driver.Value
+		expected map[string]interface{}
+	}{
+		{
+			name: "values",
+			values: [][]driver.Value{
+				{int64(1), int64(1), []byte("1.5"), int64(10), "happy", int64(1)},
+				{int64(2), nil, []byte("2.5"), nil, "sad", int64(0)},
+			},
+			expected: map[string]interface{}{
+				"COL1": []int{1, 2},
+				"COL2": []float64{1, math.NaN()},
+				"COL3": []float64{1.5, 2.5},
+				"COL4": []*string{&ten, nil},
+				"COL5": []string{"happy", "sad"},
+				"COL6": []bool{true, false},
+			},
+		},
+		{
+			name: "nullable int without nulls",
+			values: [][]driver.Value{
+				{int64(1), int64(1), []byte("1.5"), int64(10), "happy", int64(1)},
+				{int64(2), int64(2), []byte("2.5"), nil, "sad", int64(0)},
+			},
+			expected: map[string]interface{}{
+				"COL1": []int{1, 2},
+				"COL2": []int{1, 2},
+				"COL3": []float64{1.5, 2.5},
+				"COL4": []*string{&ten, nil},
+				"COL5": []string{"happy", "sad"},
+				"COL6": []bool{true, false},
+			},
+		},
+		{
+			name:   "no rows",
+			values: [][]driver.Value{},
+			expected: map[string]interface{}{
+				"COL1": []int{},
+				"COL2": []int{},
+				"COL3": []float64{},
+				"COL4": []string{},
+				"COL5": []string{},
+				"COL6": []bool{},
+			},
+		},
+	}
+
+	for i, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			dvr := MockDriver{t: t}
+			values := tc.values
+			dvr.mockQuery = func(args []driver.Value) (driver.Rows, error) {
+				return &TypedMockRows{
+					MockRows:  MockRows{t: t, columns: columns, values: values},
+					typeNames: typeNames,
+					nullable:  nullable,
+				}, nil
+			}
+			driverName := fmt.Sprintf("TestReadSQLColumnTypes%d", i)
+			sql.Register(driverName, dvr)
+			db, _ := sql.Open(driverName, "")
+			tx, _ := db.Begin()
+			qf := qframe.ReadSQL(tx, qsql.EnumTypes("mood"))
+			assertNotErr(t, qf.Err)
+			expected := qframe.New(tc.expected,
+				newqf.ColumnOrder(columns...),
+				newqf.Enums(map[string][]string{"COL5": nil}))
+			assertEquals(t, expected, qf)
+		})
+	}
+}
+
+func TestQFrame_ReadSQLCoerceUnknownColumn(t *testing.T) {
+	dvr := MockDriver{t: t}
+	dvr.results.columns = []string{"COL1"}
+	dvr.results.values = [][]driver.Value{{int64(1)}}
+	sql.Register("TestReadSQLCoerceUnknownColumn", dvr)
+	db, _ := sql.Open("TestReadSQLCoerceUnknownColumn", "")
+	tx, _ := db.Begin()
+	qf := qframe.ReadSQL(tx, qsql.Coerce(qsql.CoercePair{Column: "COL2", Type: qsql.Int64ToBool}))
+	assertErr(t, qf.Err, "does not exist")
+}
+
+func TestQFrame_ReadSQLChunks(t *testing.T) {
+	dvr := MockDriver{t: t}
+	dvr.results.columns = []string{"COL1", "COL2", "COL3"}
+	dvr.results.values = [][]driver.Value{
+		{int64(1), nil, nil},
+		{int64(2), nil, nil},
+		{int64(3), int64(3), "three"},
+		{nil, int64(4), "four"},
+		{int64(5), nil, nil},
+	}
+	sql.Register("TestReadSQLChunks", dvr)
+	db, _ := sql.Open("TestReadSQLChunks", "")
+	tx, _ := db.Begin()
+	it := qframe.ReadSQLChunks(context.Background(), tx, nil, qsql.ChunkSize(2))
+	defer it.Close()
+
+	// Ints of unknown nullability are read as floats, columns holding
+	// only NULLs in the first chunk are read as strings in all chunks.
+	three, four, s3, s4 := "three", "four", "3", "4"
+	expected := []map[string]interface{}{
+		{"COL1": []float64{1, 2}, "COL2": []*string{nil, nil}, "COL3": []*string{nil, nil}},
+		{"COL1": []float64{3, math.NaN()}, "COL2": []*string{&s3, &s4}, "COL3": []*string{&three, &four}},
+		{"COL1": []float64{5}, "COL2": []*string{nil}, "COL3": []*string{nil}},
+	}
+
+	i := 0
+	for it.Next() {
+		if i >= len(expected) {
+			t.Fatal("too many chunks")
+		}
+		assertEquals(t, qframe.New(expected[i], newqf.ColumnOrder("COL1", "COL2", "COL3")), it.QFrame())
+		i++
+	}
+	assertNotErr(t, it.Err())
+	if i != len(expected) {
+		t.Errorf("expected %d chunks, was %d", len(expected), i)
+	}
+}
+
+func TestQFrame_ReadSQLChunksTyped(t *testing.T) {
+	dvr := MockDriver{t: t}
+	values := [][]driver.Value{
+		{int64(1), "happy"},
+		{int64(2), "sad"},
+		{int64(3), nil},
+		{int64(4), "happy"},
+	}
+	dvr.mockQuery = func(args []driver.Value) (driver.Rows, error) {
+		return &TypedMockRows{
+			MockRows:  MockRows{t: t, columns: []string{"COL1", "COL2"}, values: values},
+			typeNames: []string{"BIGINT", "MOOD"},
+			nullable:  []bool{false, true},
+		}, nil
+	}
+	sql.Register("TestReadSQLChunksTyped", dvr)
+	db, _ := sql.Open("TestReadSQLChunksTyped", "")
+	tx, _ := db.Begin()
+	it := qframe.ReadSQLChunks(context.Background(), tx, nil, qsql.ChunkSize(2), qsql.EnumTypes("mood"))
+	defer it.Close()
+
+	// NOT NULL ints are kept as ints, enum values are kept across chunks
+	happy := "happy"
+	expected := []map[string]interface{}{
+		{"COL1": []int{1, 2}, "COL2": []string{"happy", "sad"}},
+		{"COL1": []int{3, 4}, "COL2": []*string{nil, &happy}},
+	}
+
+	i := 0
+	for it.Next() {
+		if i >= len(expected) {
+			t.Fatal("too many chunks")
+		}
+		qf := it.QFrame()
+		assertEquals(t, qframe.New(expected[i],
+			newqf.ColumnOrder("COL1", "COL2"),
+			newqf.Enums(map[string][]string{"COL2": {"happy", "sad"}})), qf)
+		assertTrue(t, reflect.DeepEqual([]string{"happy", "sad"}, qf.MustEnumView("COL2").Values()))
+		i++
+	}
+	assertNotErr(t, it.Err())
+	if i != len(expected) {
+		t.Errorf("expected %d chunks, was %d", len(expected), i)
+	}
+}
+
+func TestQFrame_ReadSQLChunksErrors(t *testing.T) {
+	t.Run("null in not null int column", func(t *testing.T) {
+		dvr := MockDriver{t: t}
+		dvr.mockQuery = func(args []driver.Value) (driver.Rows, error) {
+			return &TypedMockRows{
+				MockRows:  MockRows{t: t, columns: []string{"COL1"}, values: [][]driver.Value{{int64(1)}, {nil}}},
+				typeNames: []string{"BIGINT"},
+				nullable:  []bool{false},
+			}, nil
+		}
+		sql.Register("TestReadSQLChunksTypeChange", dvr)
+		db, _ := sql.Open("TestReadSQLChunksTypeChange", "")
+		tx, _ := db.Begin()
+		it := qframe.ReadSQLChunks(context.Background(), tx, nil, qsql.ChunkSize(1))
+		defer it.Close()
+		for it.Next() {
+		}
+		assertErr(t, it.Err(), "NULL value in int column")
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		dvr := MockDriver{t: t}
+		dvr.results.columns = []string{"COL1"}
+		dvr.results.values = [][]driver.Value{{int64(1)}, {int64(2)}, {int64(3)}}
+		sql.Register("TestReadSQLChunksCancelled", dvr)
+		db, _ := sql.Open("TestReadSQLChunksCancelled", "")
+		tx, _ := db.Begin()
+		ctx, cancel := context.WithCancel(context.Background())
+		it := qframe.ReadSQLChunks(ctx, tx, nil, qsql.ChunkSize(1))
+		defer it.Close()
+		if !it.Next() {
+			t.Fatal("expected first chunk")
+		}
+		cancel()
+		for it.Next() {
+		}
+		assertErr(t, it.Err(), "context canceled")
+	})
+}
+
 func TestQFrame_ReadWithArgs(t *testing.T) {
 	dvr := MockDriver{t: t}
 	dvr.args.values = [][]driver.Value{{""}}
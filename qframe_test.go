@@ -2,13 +2,18 @@ package qframe_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"math"
+	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/tobgu/qframe/config/rolling"
 
@@ -17,10 +22,16 @@ import (
 
 	"github.com/tobgu/qframe"
 	"github.com/tobgu/qframe/aggregation"
+	"github.com/tobgu/qframe/config/corr"
 	"github.com/tobgu/qframe/config/csv"
+	"github.com/tobgu/qframe/config/cut"
 	"github.com/tobgu/qframe/config/eval"
+	"github.com/tobgu/qframe/config/fixedwidth"
 	"github.com/tobgu/qframe/config/groupby"
+	"github.com/tobgu/qframe/config/json"
 	"github.com/tobgu/qframe/config/newqf"
+	"github.com/tobgu/qframe/config/valuecounts"
+	"github.com/tobgu/qframe/qerrors"
 	"github.com/tobgu/qframe/types"
 )
 
@@ -1419,6 +1430,65 @@ a,1.5`
 	assertEquals(t, expected, out)
 }
 
+func TestQFrame_ReadFixedWidth(t *testing.T) {
+	input := `ID  NAME      AMOUNT FLAG
+   1Alice      12.50 true
+  22Bob         7.00false
+
+ 333             1.5 true
+`
+	columns := fixedwidth.Columns(
+		fixedwidth.Column{Name: "ID", Start: 0, Width: 4},
+		fixedwidth.Column{Name: "NAME", Start: 4, Width: 10},
+		fixedwidth.Column{Name: "AMOUNT", Start: 14, Width: 6},
+		fixedwidth.Column{Name: "FLAG", Start: 20, Width: 5})
+
+	t.Run("Auto detect types", func(t *testing.T) {
+		out := qframe.ReadFixedWidth(strings.NewReader(input), columns,
+			fixedwidth.SkipLines(1), fixedwidth.IgnoreEmptyLines(true), fixedwidth.EmptyNull(true))
+		assertNotErr(t, out.Err)
+		alice, bob := "Alice", "Bob"
+		expected := qframe.New(map[string]interface{}{
+			"ID":     []int{1, 22, 333},
+			"NAME":   []*string{&alice, &bob, nil},
+			"AMOUNT": []float64{12.5, 7, 1.5},
+			"FLAG":   []bool{true, false, true},
+		}, newqf.ColumnOrder("ID", "NAME", "AMOUNT", "FLAG"))
+		assertEquals(t, expected, out)
+	})
+
+	t.Run("Explicit types and enums", func(t *testing.T) {
+		out := qframe.ReadFixedWidth(strings.NewReader("a1\nb2\na3"),
+			fixedwidth.Columns(
+				fixedwidth.Column{Name: "E", Start: 0, Width: 1, Type: "enum"},
+				fixedwidth.Column{Name: "S", Start: 1, Width: 1, Type: "string"}),
+			fixedwidth.EnumValues(map[string][]string{"E": {"b", "a"}}))
+		assertNotErr(t, out.Err)
+		expected := qframe.New(map[string]interface{}{
+			"E": []string{"a", "b", "a"},
+			"S": []string{"1", "2", "3"},
+		}, newqf.ColumnOrder("E", "S"), newqf.Enums(map[string][]string{"E": {"b", "a"}}))
+		assertEquals(t, expected, out)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		out := qframe.ReadFixedWidth(strings.NewReader("a"))
+		assertErr(t, out.Err, "no columns specified")
+
+		out = qframe.ReadFixedWidth(strings.NewReader("a"), fixedwidth.Columns(fixedwidth.Column{Name: "A", Width: 0}))
+		assertErr(t, out.Err, "invalid position")
+
+		out = qframe.ReadFixedWidth(strings.NewReader("a"),
+			fixedwidth.Columns(fixedwidth.Column{Name: "A", Width: 1, Type: "int"}))
+		assertErr(t, out.Err, "int")
+
+		out = qframe.ReadFixedWidth(strings.NewReader("a"),
+			fixedwidth.Columns(fixedwidth.Column{Name: "A", Width: 1}),
+			fixedwidth.EnumValues(map[string][]string{"A": {"a"}}))
+		assertErr(t, out.Err, "non enum column")
+	})
+}
+
 func TestQFrame_ReadJSON(t *testing.T) {
 	/*
 		>>> pd.DataFrame.from_records([dict(a=1.5), dict(a=None)])
@@ -1573,6 +1643,215 @@ false,2.5,2,"b,c"
 	}
 }
 
+func TestQFrame_ToCSVFormatting(t *testing.T) {
+	a, b, c, n := "a", "b;c", `d"e`, "NULL"
+	input := qframe.New(map[string]interface{}{
+		"STRING1": []*string{&a, nil, &b, &c, &n},
+		"INT1":    []int{1, 2, 3, 4, 5},
+		"FLOAT1":  []float64{1.25, math.NaN(), 3, 4.5, 5.125},
+		"BOOL1":   []bool{true, false, true, false, true},
+	}, newqf.ColumnOrder("STRING1", "INT1", "FLOAT1", "BOOL1"))
+	assertNotErr(t, input.Err)
+
+	table := []struct {
+		name     string
+		config   []csv.ToConfigFunc
+		expected string
+	}{
+		{
+			name:   "null rep and minimal quoting",
+			config: []csv.ToConfigFunc{csv.NullRep("NULL")},
+			expected: `STRING1,INT1,FLOAT1,BOOL1
+a,1,1.25,true
+NULL,2,NULL,false
+b;c,3,3,true
+"d""e",4,4.5,false
+"NULL",5,5.125,true
+`,
+		},
+		{
+			name:     "delimiter and line terminator",
+			config:   []csv.ToConfigFunc{csv.ToDelimiter(';'), csv.LineTerminator("\r\n"), csv.Header(false)},
+			expected: "a;1;1.25;true\r\n;2;;false\r\n\"b;c\";3;3;true\r\n\"d\"\"e\";4;4.5;false\r\nNULL;5;5.125;true\r\n",
+		},
+		{
+			name:   "quote all",
+			config: []csv.ToConfigFunc{csv.Quoting(csv.QuoteAll), csv.NullRep(`\N`)},
+			expected: `"STRING1","INT1","FLOAT1","BOOL1"
+"a","1","1.25","true"
+\N,"2",\N,"false"
+"b;c","3","3","true"
+"d""e","4","4.5","false"
+"NULL","5","5.125","true"
+`,
+		},
+		{
+			name:   "quote non numeric and float precision",
+			config: []csv.ToConfigFunc{csv.Quoting(csv.QuoteNonNumeric), csv.FloatPrecision(1)},
+			expected: `"STRING1","INT1","FLOAT1","BOOL1"
+"a",1,1.2,"true"
+,2,,"false"
+"b;c",3,3.0,"true"
+"d""e",4,4.5,"false"
+"NULL",5,5.1,"true"
+`,
+		},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := input.ToCSV(buf, tc.config...)
+			assertNotErr(t, err)
+			if buf.String() != tc.expected {
+				t.Errorf("CSV not equal. \nGot:\n%s\nExpected:\n%s", buf.String(), tc.expected)
+			}
+		})
+	}
+
+	for _, d := range []byte{'"', '\r', '\n'} {
+		err := input.ToCSV(new(bytes.Buffer), csv.ToDelimiter(d))
+		assertErr(t, err, "invalid delimiter")
+		assertTrue(t, errors.Is(err, qerrors.InvalidArgument))
+	}
+}
+
+func TestQFrame_CompressedIO(t *testing.T) {
+	original := qframe.New(map[string]interface{}{
+		"STRING1": []string{"a", "b"}, "INT1": []int{1, 2}, "FLOAT1": []float64{1.5, 2.5}, "BOOL1": []bool{true, false}})
+	assertNotErr(t, original.Err)
+
+	for _, c := range []types.Compression{types.CompressionNone, types.CompressionGzip, types.CompressionZstd} {
+		t.Run(fmt.Sprintf("CSV %s", c), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			assertNotErr(t, original.ToCSV(buf, csv.ToCompression(c)))
+			raw := buf.Bytes()
+
+			out := qframe.ReadCSV(bytes.NewReader(raw), csv.Compression(c))
+			assertNotErr(t, out.Err)
+			assertEquals(t, original, out)
+
+			out = qframe.ReadCSV(bytes.NewReader(raw), csv.Compression(types.CompressionAuto))
+			assertNotErr(t, out.Err)
+			assertEquals(t, original, out)
+		})
+
+		t.Run(fmt.Sprintf("JSON %s", c), func(t *testing.T) {
+			// Ints are read as floats from JSON, leave them out
+			in := original.Drop("INT1")
+			buf := new(bytes.Buffer)
+			assertNotErr(t, in.ToJSON(buf, json.ToCompression(c)))
+			raw := buf.Bytes()
+
+			out := qframe.ReadJSONWithConfig(bytes.NewReader(raw), json.Compression(c))
+			assertNotErr(t, out.Err)
+			assertEquals(t, in, out)
+
+			out = qframe.ReadJSON(bytes.NewReader(raw))
+			assertNotErr(t, out.Err)
+			assertEquals(t, in, out)
+		})
+	}
+}
+
+func TestQFrame_CompressedIOErrors(t *testing.T) {
+	out := qframe.ReadCSV(strings.NewReader("a,b\n1,2"), csv.Compression(types.CompressionGzip))
+	assertErr(t, out.Err, "gzip")
+
+	err := qframe.New(map[string]interface{}{"a": []int{1}}).ToCSV(new(bytes.Buffer), csv.ToCompression(types.CompressionAuto))
+	assertErr(t, err, "unsupported compression")
+}
+
+func TestQFrame_ToFromBinary(t *testing.T) {
+	a, b, c := "a", "bb", "ccc"
+	enums := newqf.Enums(map[string][]string{"ENUM": {"x", "y", "z"}, "ENUM2": nil})
+	original := qframe.New(map[string]interface{}{
+		"INT":    []int{3, 1, 2, 0},
+		"FLOAT":  []float64{1.5, math.NaN(), -2.25, 0},
+		"BOOL":   []bool{true, false, true, false},
+		"STRING": []*string{&a, nil, &c, &b},
+		"ENUM":   []string{"y", "x", "z", "x"},
+		"ENUM2":  []*string{&c, nil, &a, &c},
+	}, enums)
+	assertNotErr(t, original.Err)
+
+	table := []struct {
+		name string
+		qf   qframe.QFrame
+	}{
+		{name: "full", qf: original},
+		{name: "sorted and sliced", qf: original.Sort(qframe.Order{Column: "INT"}).Slice(1, 3)},
+		{name: "empty", qf: original.Filter(qframe.Filter{Column: "INT", Comparator: ">", Arg: 10})},
+		{name: "no columns", qf: qframe.New(map[string]interface{}{})},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			assertNotErr(t, tc.qf.ToBinary(buf))
+			out := qframe.ReadBinary(buf)
+			assertNotErr(t, out.Err)
+			assertEquals(t, tc.qf, out)
+		})
+	}
+}
+
+func TestQFrame_OpenBinary(t *testing.T) {
+	a, b := "a", "bb"
+	original := qframe.New(map[string]interface{}{
+		"INT":    []int{3, 1, 2},
+		"FLOAT":  []float64{1.5, math.NaN(), -2.25},
+		"BOOL":   []bool{true, false, true},
+		"STRING": []*string{&a, nil, &b},
+		"ENUM":   []string{"y", "x", "y"},
+	}, newqf.Enums(map[string][]string{"ENUM": nil}))
+	assertNotErr(t, original.Err)
+
+	path := filepath.Join(t.TempDir(), "frame.qfb")
+	f, err := os.Create(path)
+	assertNotErr(t, err)
+	assertNotErr(t, original.ToBinary(f))
+	assertNotErr(t, f.Close())
+
+	mapped := qframe.OpenBinary(path)
+	assertNotErr(t, mapped.Err)
+	assertEquals(t, original, mapped.QFrame)
+
+	sorted := mapped.Sort(qframe.Order{Column: "INT"})
+	assertEquals(t, original.Sort(qframe.Order{Column: "INT"}), sorted)
+
+	assertNotErr(t, mapped.Close())
+	assertNotErr(t, mapped.Close())
+
+	missing := qframe.OpenBinary(filepath.Join(t.TempDir(), "missing.qfb"))
+	assertErr(t, missing.Err, "OpenBinary")
+	assertNotErr(t, missing.Close())
+}
+
+func TestQFrame_ReadBinaryErrors(t *testing.T) {
+	buf := new(bytes.Buffer)
+	assertNotErr(t, qframe.New(map[string]interface{}{"A": []int{1, 2}}).ToBinary(buf))
+	valid := buf.Bytes()
+
+	table := []struct {
+		name  string
+		input []byte
+		err   string
+	}{
+		{name: "empty", input: []byte{}, err: "unexpected end of data"},
+		{name: "magic", input: []byte("NOTQFBIN"), err: "not a QFrame binary"},
+		{name: "version", input: append([]byte("QFBN\x09\x00\x00\x00"), valid[8:]...), err: "unsupported version"},
+		{name: "truncated", input: valid[:len(valid)-4], err: "unexpected end of data"},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			out := qframe.ReadBinary(bytes.NewReader(tc.input))
+			assertErr(t, out.Err, tc.err)
+		})
+	}
+}
+
 func TestQFrame_ToFromJSON(t *testing.T) {
 	config := []newqf.ConfigFunc{newqf.Enums(map[string][]string{"ENUM": {"aa", "bb"}})}
 	data := map[string]interface{}{
@@ -2678,3 +2957,1442 @@ func TestQFrame_GroupByQFrames(t *testing.T) {
 	assertContainsQFrame(t, ff, qframe.New(map[string]interface{}{"COL1": []int{2}, "COL2": []int{20}}))
 	assertContainsQFrame(t, ff, qframe.New(map[string]interface{}{"COL1": []int{3, 3}, "COL2": []int{30, 31}}))
 }
+
+func TestQFrame_ReadWriteFormat(t *testing.T) {
+	a, b := "a", "b"
+	original := qframe.New(map[string]interface{}{
+		"INT":    []int{1, 2, 3},
+		"FLOAT":  []float64{1.5, 2.5, 3.5},
+		"STRING": []*string{&a, nil, &b},
+		"ENUM":   []string{"x", "y", "x"},
+	}, newqf.Enums(map[string][]string{"ENUM": nil}),
+		newqf.ColumnOrder("INT", "FLOAT", "STRING", "ENUM"))
+
+	schema := qframe.NewSchema(
+		qframe.Field{Name: "ENUM", Type: types.Enum},
+		qframe.Field{Name: "INT", Type: types.Int},
+		qframe.Field{Name: "STRING", Type: types.String},
+	)
+
+	table := []struct {
+		format      string
+		writeConfig []interface{}
+		readConfig  []interface{}
+	}{
+		{format: "csv",
+			writeConfig: []interface{}{csv.ToDelimiter(';'), csv.Quoting(csv.QuoteAll)},
+			readConfig:  []interface{}{csv.Delimiter(';'), csv.EmptyNull(true)}},
+		{format: "binary"},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.format, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			assertNotErr(t, original.Write(tc.format, buf, qframe.WithConfig(tc.writeConfig...)))
+			result := qframe.Read(tc.format, buf, qframe.WithSchema(schema), qframe.WithConfig(tc.readConfig...))
+			assertNotErr(t, result.Err)
+			assertEquals(t, original.Select("ENUM", "INT", "STRING"), result)
+			assertTrue(t, reflect.DeepEqual(schema.Names(), result.Schema().Names()))
+		})
+	}
+
+	t.Run("fixedwidth", func(t *testing.T) {
+		input := "x  1\ny  2\n"
+		result := qframe.Read("fixedwidth", strings.NewReader(input),
+			qframe.WithSchema(qframe.NewSchema(qframe.Field{Name: "ENUM", Type: types.Enum, EnumValues: []string{"y", "x"}})),
+			qframe.WithConfig(fixedwidth.Columns(
+				fixedwidth.Column{Name: "ENUM", Start: 0, Width: 3},
+				fixedwidth.Column{Name: "INT", Start: 3, Width: 1})))
+		assertNotErr(t, result.Err)
+		expected := qframe.New(map[string]interface{}{"ENUM": []string{"x", "y"}},
+			newqf.Enums(map[string][]string{"ENUM": {"y", "x"}}))
+		assertEquals(t, expected, result)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		jsonSchema := qframe.NewSchema(qframe.Field{Name: "ENUM", Type: types.Enum}, qframe.Field{Name: "STRING"})
+		assertNotErr(t, original.Write("json", buf, qframe.WithSchema(jsonSchema)))
+		result := qframe.Read("json", buf, qframe.WithSchema(jsonSchema))
+		assertNotErr(t, result.Err)
+		assertEquals(t, original.Select("ENUM", "STRING"), result)
+	})
+}
+
+func TestQFrame_RegisterFormat(t *testing.T) {
+	// A format with one value per line in a single column named VALUE
+	qframe.RegisterSource("test-lines", qframe.DataSourceFunc(func(src interface{}, opts qframe.FormatOptions) qframe.QFrame {
+		b, err := io.ReadAll(src.(io.Reader))
+		if err != nil {
+			return qframe.QFrame{Err: err}
+		}
+		return qframe.New(map[string]interface{}{"VALUE": strings.Split(strings.TrimSpace(string(b)), "\n")})
+	}))
+	qframe.RegisterSink("test-lines", qframe.DataSinkFunc(func(qf qframe.QFrame, dst interface{}, opts qframe.FormatOptions) error {
+		view, err := qf.StringView("VALUE")
+		if err != nil {
+			return err
+		}
+		for i := 0; i < view.Len(); i++ {
+			if _, err := fmt.Fprintln(dst.(io.Writer), *view.ItemAt(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	f := qframe.Read("test-lines", strings.NewReader("a\nb\nc\n"))
+	assertNotErr(t, f.Err)
+	assertEquals(t, qframe.New(map[string]interface{}{"VALUE": []string{"a", "b", "c"}}), f)
+
+	buf := new(bytes.Buffer)
+	assertNotErr(t, f.Write("test-lines", buf))
+	assertTrue(t, buf.String() == "a\nb\nc\n")
+
+	assertTrue(t, reflect.DeepEqual([]string{"binary", "csv", "fixedwidth", "json", "sql", "test-lines"}, qframe.SourceFormats()))
+	assertTrue(t, reflect.DeepEqual([]string{"binary", "csv", "json", "sql", "test-lines"}, qframe.SinkFormats()))
+}
+
+func TestQFrame_ReadWriteFormatErrors(t *testing.T) {
+	f := qframe.New(map[string]interface{}{"COL1": []int{1, 2}})
+	table := []struct {
+		name string
+		err  error
+		kind qerrors.Kind
+	}{
+		{name: "unknown read format", err: qframe.Read("foo", strings.NewReader("")).Err, kind: qerrors.InvalidArgument},
+		{name: "unknown write format", err: f.Write("foo", new(bytes.Buffer)), kind: qerrors.InvalidArgument},
+		{name: "wrong source type", err: qframe.Read("csv", 123).Err, kind: qerrors.InvalidArgument},
+		{name: "wrong sql source type", err: qframe.Read("sql", strings.NewReader("")).Err, kind: qerrors.InvalidArgument},
+		{name: "wrong config type", err: f.Write("csv", new(bytes.Buffer), qframe.WithConfig(csv.Delimiter(';'))),
+			kind: qerrors.InvalidArgument},
+		{name: "binary config", err: qframe.Read("binary", strings.NewReader(""), qframe.WithConfig(csv.Delimiter(';'))).Err,
+			kind: qerrors.InvalidArgument},
+		{name: "unknown schema column", err: qframe.Read("csv", strings.NewReader("COL1\n1"),
+			qframe.WithSchema(qframe.NewSchema(qframe.Field{Name: "COL2"}))).Err, kind: qerrors.UnknownColumn},
+		{name: "schema conversion", err: qframe.Read("csv", strings.NewReader("COL1\na"),
+			qframe.WithSchema(qframe.NewSchema(qframe.Field{Name: "COL1", Type: types.Int}))).Err, kind: qerrors.TypeMismatch},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.err == nil {
+				t.Fatal("expected error")
+			}
+			if tc.kind != qerrors.Other && !errors.Is(tc.err, tc.kind) {
+				t.Errorf("expected error of kind %v, was: %v", tc.kind, tc.err)
+			}
+		})
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	a, b, c := "abc", "abd", "x"
+	f := qframe.New(map[string]interface{}{
+		"INT":    []int{1, 5, 10, 5},
+		"FLOAT":  []float64{1.5, math.NaN(), 2.5, 3.5},
+		"STRING": []*string{&a, &b, &c, nil},
+		"BOOL":   []bool{true, false, true, false},
+	})
+
+	min, max := 2.0, 8.0
+	schema := qframe.NewSchema(
+		qframe.Field{Name: "INT", Type: types.Int, Constraints: qframe.Constraints{Min: &min, Max: &max, Unique: true}},
+		qframe.Field{Name: "FLOAT", Type: types.Float},
+		qframe.Field{Name: "STRING", Type: types.String, Nullable: true, EnumValues: []string{"abc", "abd"},
+			Constraints: qframe.Constraints{Pattern: regexp.MustCompile("^ab")}},
+		qframe.Field{Name: "BOOL", Type: types.Int},
+		qframe.Field{Name: "MISSING", Type: types.Int},
+	)
+
+	violations, err := schema.Validate(f)
+	assertNotErr(t, err)
+	expected := []qframe.Violation{
+		{Column: "INT", Row: 0, Value: "1", Reason: "less than min 2"},
+		{Column: "INT", Row: 2, Value: "10", Reason: "greater than max 8"},
+		{Column: "INT", Row: 3, Value: "5", Reason: "duplicate of row 1"},
+		{Column: "FLOAT", Row: 1, Value: "null", Reason: "null value"},
+		{Column: "STRING", Row: 2, Value: "x", Reason: "not a valid enum value"},
+		{Column: "STRING", Row: 2, Value: "x", Reason: "does not match pattern ^ab"},
+		{Column: "BOOL", Row: -1, Reason: "type bool, expected int"},
+		{Column: "MISSING", Row: -1, Reason: "missing column"},
+	}
+	if !reflect.DeepEqual(expected, violations) {
+		t.Errorf("\n%v\n!=\n%v", expected, violations)
+	}
+
+	violations, err = f.Schema().Validate(f)
+	assertNotErr(t, err)
+	assertTrue(t, len(violations) == 0)
+}
+
+func TestQFrame_Coerce(t *testing.T) {
+	f := qframe.New(map[string]interface{}{
+		"INT":    []int{1, 0, 3},
+		"FLOAT":  []float64{1, 2, math.NaN()},
+		"STRING": []string{"1", "2", "3"},
+		"BOOL":   []bool{true, false, true},
+	})
+
+	one, two, three, four := "1", "0", "3", "2"
+	table := []struct {
+		name     string
+		field    qframe.Field
+		expected interface{}
+		enums    map[string][]string
+	}{
+		{name: "int to float", field: qframe.Field{Name: "INT", Type: types.Float}, expected: []float64{1, 0, 3}},
+		{name: "int to bool", field: qframe.Field{Name: "INT", Type: types.Bool}, expected: []bool{true, false, true}},
+		{name: "int to string", field: qframe.Field{Name: "INT", Type: types.String}, expected: []*string{&one, &two, &three}},
+		{name: "int to enum", field: qframe.Field{Name: "INT", Type: types.Enum, EnumValues: []string{"3", "1", "0"}},
+			expected: []string{"1", "0", "3"}, enums: map[string][]string{"INT": {"3", "1", "0"}}},
+		{name: "float to string", field: qframe.Field{Name: "FLOAT", Type: types.String}, expected: []*string{&one, &four, nil}},
+		{name: "string to int", field: qframe.Field{Name: "STRING", Type: types.Int}, expected: []int{1, 2, 3}},
+		{name: "bool to int", field: qframe.Field{Name: "BOOL", Type: types.Int}, expected: []int{1, 0, 1}},
+		{name: "missing nullable", field: qframe.Field{Name: "NEW", Type: types.String, Nullable: true}, expected: []*string{nil, nil, nil}},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			result := f.Coerce(qframe.NewSchema(tc.field))
+			assertNotErr(t, result.Err)
+			expected := qframe.New(map[string]interface{}{tc.field.Name: tc.expected}, newqf.Enums(tc.enums))
+			assertEquals(t, expected, result)
+		})
+	}
+
+	errTable := []struct {
+		name  string
+		field qframe.Field
+		err   string
+		kind  qerrors.Kind
+	}{
+		{name: "null float to int", field: qframe.Field{Name: "FLOAT", Type: types.Int}, err: "row 2", kind: qerrors.TypeMismatch},
+		{name: "missing not nullable", field: qframe.Field{Name: "NEW", Type: types.String}, err: "missing non nullable",
+			kind: qerrors.UnknownColumn},
+		{name: "missing nullable int", field: qframe.Field{Name: "NEW", Type: types.Int, Nullable: true}, err: "cannot add missing",
+			kind: qerrors.InvalidArgument},
+		{name: "invalid enum value", field: qframe.Field{Name: "INT", Type: types.Enum, EnumValues: []string{"1"}}, err: "Coerce"},
+	}
+
+	for _, tc := range errTable {
+		t.Run(tc.name, func(t *testing.T) {
+			err := f.Coerce(qframe.NewSchema(tc.field)).Err
+			assertErr(t, err, tc.err)
+			if tc.kind != qerrors.Other {
+				assertTrue(t, errors.Is(err, tc.kind))
+			}
+		})
+	}
+}
+
+func TestSchema_EmptyQFrame(t *testing.T) {
+	// Columns of zero row CSVs without types are undefined
+	f := qframe.ReadCSV(strings.NewReader("INT,STRING\n"))
+	assertNotErr(t, f.Err)
+	schema := qframe.NewSchema(
+		qframe.Field{Name: "INT", Type: types.Int},
+		qframe.Field{Name: "STRING", Type: types.String})
+
+	violations, err := schema.Validate(f)
+	assertNotErr(t, err)
+	assertTrue(t, len(violations) == 0)
+
+	result := f.Coerce(schema)
+	assertNotErr(t, result.Err)
+	expected := qframe.New(map[string]interface{}{"INT": []int{}, "STRING": []*string{}},
+		newqf.ColumnOrder("INT", "STRING"))
+	assertEquals(t, expected, result)
+}
+
+func TestSchema_ReaderConfig(t *testing.T) {
+	schema := qframe.NewSchema(
+		qframe.Field{Name: "ENUM", Type: types.Enum, EnumValues: []string{"b", "a"}},
+		qframe.Field{Name: "FLOAT", Type: types.Float},
+		qframe.Field{Name: "NEW", Type: types.String, Nullable: true})
+	expected := qframe.New(map[string]interface{}{
+		"ENUM":  []string{"a", "b"},
+		"FLOAT": []float64{1, 2},
+		"NEW":   []*string{nil, nil},
+	}, newqf.ColumnOrder("ENUM", "FLOAT", "NEW"),
+		newqf.Enums(map[string][]string{"ENUM": {"b", "a"}}))
+
+	t.Run("csv", func(t *testing.T) {
+		f := qframe.ReadCSV(strings.NewReader("FLOAT;ENUM;OTHER\n1;a;x\n2;b;y"), schema.CSV(), csv.Delimiter(';'))
+		assertNotErr(t, f.Err)
+		assertEquals(t, expected, f)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		input := `[{"FLOAT": 1, "ENUM": "a", "OTHER": "x"}, {"FLOAT": 2, "ENUM": "b", "OTHER": "y"}]`
+		f := qframe.ReadJSONWithConfig(strings.NewReader(input), schema.JSON())
+		assertNotErr(t, f.Err)
+		assertEquals(t, expected, f)
+	})
+
+	t.Run("csv invalid enum value", func(t *testing.T) {
+		f := qframe.ReadCSV(strings.NewReader("FLOAT,ENUM\n1,c"), schema.CSV())
+		assertErr(t, f.Err, "ReadCSV")
+	})
+}
+
+func TestInferCSV(t *testing.T) {
+	input := `INT,FLOAT,MIXED,BOOL,EMPTY
+1,1.5,1,true,
+2,,2,false,
+3,2.5,x,true,
+`
+
+	report, err := qframe.InferCSV(strings.NewReader(input))
+	assertNotErr(t, err)
+	if len(report.Columns) != 5 {
+		t.Fatalf("unexpected column count: %d", len(report.Columns))
+	}
+
+	expected := []struct {
+		name      string
+		typ       types.DataType
+		nullCount int
+		distinct  int
+		rejected  map[types.DataType]qframe.TypeCandidate
+	}{
+		{name: "INT", typ: types.Int, distinct: 3, rejected: map[types.DataType]qframe.TypeCandidate{
+			types.Bool: {Type: types.Bool, Row: 1, Line: 3, Value: "2"}}},
+		{name: "FLOAT", typ: types.Float, nullCount: 1, distinct: 2, rejected: map[types.DataType]qframe.TypeCandidate{
+			types.Int:  {Type: types.Int, Row: 0, Line: 2, Value: "1.5"},
+			types.Bool: {Type: types.Bool, Row: 0, Line: 2, Value: "1.5"}}},
+		{name: "MIXED", typ: types.String, distinct: 3, rejected: map[types.DataType]qframe.TypeCandidate{
+			types.Int:   {Type: types.Int, Row: 2, Line: 4, Value: "x"},
+			types.Float: {Type: types.Float, Row: 2, Line: 4, Value: "x"},
+			types.Bool:  {Type: types.Bool, Row: 1, Line: 3, Value: "2"}}},
+		{name: "BOOL", typ: types.Bool, distinct: 2, rejected: map[types.DataType]qframe.TypeCandidate{
+			types.Int:   {Type: types.Int, Row: 0, Line: 2, Value: "true"},
+			types.Float: {Type: types.Float, Row: 0, Line: 2, Value: "true"}}},
+		{name: "EMPTY", typ: types.Float, nullCount: 3, rejected: map[types.DataType]qframe.TypeCandidate{
+			types.Int:  {Type: types.Int, Row: 0, Line: 2},
+			types.Bool: {Type: types.Bool, Row: 0, Line: 2}}},
+	}
+
+	for i, e := range expected {
+		t.Run(e.name, func(t *testing.T) {
+			c := report.Columns[i]
+			assertTrue(t, c.Name == e.name)
+			if c.Type != e.typ || c.NullCount != e.nullCount || c.DistinctCount != e.distinct || !c.EnumFit || c.RowCount != 3 {
+				t.Errorf("unexpected inference: %+v", c)
+			}
+			for _, candidate := range c.Candidates {
+				rejected, ok := e.rejected[candidate.Type]
+				if !ok {
+					assertTrue(t, candidate.Fits && candidate.Row == -1)
+					continue
+				}
+				if candidate != rejected {
+					t.Errorf("%+v != %+v", rejected, candidate)
+				}
+			}
+		})
+	}
+
+	// The inferred types are the types read
+	f := qframe.ReadCSV(strings.NewReader(input))
+	assertNotErr(t, f.Err)
+	for _, c := range report.Columns {
+		assertTrue(t, f.ColumnTypeMap()[c.Name] == c.Type)
+	}
+}
+
+func TestInferJSON(t *testing.T) {
+	input := `[{"A": 1.5, "B": "x", "C": true},
+{"A": null, "B": "y", "C": 1},
+{"A": 2, "B": null}]`
+
+	report, err := qframe.InferJSON(strings.NewReader(input))
+	assertNotErr(t, err)
+	assertTrue(t, len(report.Columns) == 3)
+
+	a, _ := report.Column("A")
+	assertTrue(t, a.Type == types.None && a.NullCount == 1 && a.DistinctCount == 2)
+	assertTrue(t, a.Candidates[0] == qframe.TypeCandidate{Type: types.Float, Row: 1, Value: "null"})
+
+	b, _ := report.Column("B")
+	assertTrue(t, b.Type == types.String && b.NullCount == 1 && b.DistinctCount == 2 && b.EnumFit)
+
+	c, _ := report.Column("C")
+	assertTrue(t, c.Type == types.None)
+	assertTrue(t, c.Candidates[1] == qframe.TypeCandidate{Type: types.Bool, Row: 1, Value: "1"})
+	assertTrue(t, c.Candidates[2] == qframe.TypeCandidate{Type: types.String, Row: 0, Value: "true"})
+}
+
+func TestQFrame_ReadCSVBadRows(t *testing.T) {
+	input := `A,B,C
+1,1.5,true
+x,2.5,false
+3,4
+4,y,true
+5,5.5,"may,be"
+`
+	typs := csv.Types(map[string]string{"A": "int", "B": "float", "C": "bool"})
+
+	t.Run("fail", func(t *testing.T) {
+		f := qframe.ReadCSV(strings.NewReader(input), typs)
+		assertErr(t, f.Err, "line 4")
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		f := qframe.ReadCSV(strings.NewReader(input), typs, csv.BadRows(csv.SkipBadRows, nil))
+		assertNotErr(t, f.Err)
+		expected := qframe.New(map[string]interface{}{
+			"A": []int{1}, "B": []float64{1.5}, "C": []bool{true}}, newqf.ColumnOrder("A", "B", "C"))
+		assertEquals(t, expected, f)
+	})
+
+	t.Run("collect", func(t *testing.T) {
+		var badRows []csv.BadRow
+		f := qframe.ReadCSV(strings.NewReader(input), typs, csv.BadRows(csv.CollectBadRows, &badRows))
+		assertNotErr(t, f.Err)
+		assertTrue(t, f.Len() == 1)
+		expected := []csv.BadRow{
+			{Line: 3, Encoded: "x,2.5,false", Reason: `column A: invalid int value "x"`},
+			{Line: 4, Encoded: "3,4", Reason: "wrong number of columns, expected 3, was 2"},
+			{Line: 5, Encoded: "4,y,true", Reason: `column B: invalid float value "y"`},
+			{Line: 6, Encoded: `5,5.5,"may,be"`, Reason: `column C: invalid bool value "may,be"`},
+		}
+		if !reflect.DeepEqual(expected, badRows) {
+			t.Errorf("\n%v\n!=\n%v", expected, badRows)
+		}
+	})
+
+	t.Run("multi line row", func(t *testing.T) {
+		input := "A,B,C\n1,1.5,\"tr\nue\"\n2,2.5,true\nx,3.5,false\n3,4\n"
+		var badRows []csv.BadRow
+		f := qframe.ReadCSV(strings.NewReader(input), csv.Types(map[string]string{"A": "int"}),
+			csv.BadRows(csv.CollectBadRows, &badRows))
+		assertNotErr(t, f.Err)
+		assertTrue(t, f.Len() == 2)
+		expected := []csv.BadRow{
+			{Line: 5, Encoded: "x,3.5,false", Reason: `column A: invalid int value "x"`},
+			{Line: 6, Encoded: "3,4", Reason: "wrong number of columns, expected 3, was 2"},
+		}
+		if !reflect.DeepEqual(expected, badRows) {
+			t.Errorf("\n%v\n!=\n%v", expected, badRows)
+		}
+
+		f = qframe.ReadCSV(strings.NewReader(input), csv.Types(map[string]string{"A": "int"}))
+		assertErr(t, f.Err, "line 6")
+	})
+
+	t.Run("null invalid", func(t *testing.T) {
+		f := qframe.ReadCSV(strings.NewReader(input), typs, csv.BadRows(csv.SkipBadRows, nil), csv.NullInvalid(true))
+		assertNotErr(t, f.Err)
+		expected := qframe.New(map[string]interface{}{
+			"A": []float64{1, math.NaN(), 4},
+			"B": []float64{1.5, 2.5, math.NaN()},
+			"C": []bool{true, false, true}}, newqf.ColumnOrder("A", "B", "C"))
+		assertEquals(t, expected, f)
+	})
+
+	t.Run("null invalid enum", func(t *testing.T) {
+		f := qframe.ReadCSV(strings.NewReader("E\na\nb\nc\n"),
+			csv.Types(map[string]string{"E": "enum"}),
+			csv.EnumValues(map[string][]string{"E": {"a", "b"}}),
+			csv.NullInvalid(true))
+		assertNotErr(t, f.Err)
+		a, b := "a", "b"
+		expected := qframe.New(map[string]interface{}{"E": []*string{&a, &b, nil}},
+			newqf.Enums(map[string][]string{"E": {"a", "b"}}))
+		assertEquals(t, expected, f)
+	})
+}
+
+type testAudit struct {
+	Created time.Time `qframe:"created"`
+	Deleted *time.Time
+}
+
+type testOrder struct {
+	testAudit
+	ID       int     `qframe:"id"`
+	Status   string  `qframe:"status,enum"`
+	Price    float64 `qframe:"price"`
+	Discount *float64
+	Quantity *int32
+	Note     *string
+	Paid     bool
+	Secret   string `qframe:"-"`
+	internal int
+}
+
+func TestQFrame_FromToStructs(t *testing.T) {
+	t1 := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	discount, quantity, note := 0.5, int32(3), "fragile"
+	orders := []testOrder{
+		{testAudit: testAudit{Created: t1, Deleted: &t1}, ID: 1, Status: "new", Price: 1.5,
+			Discount: &discount, Quantity: &quantity, Note: &note, Paid: true},
+		{testAudit: testAudit{Created: t1}, ID: 2, Status: "shipped", Price: 2.5},
+	}
+
+	f := qframe.FromStructs(orders)
+	assertNotErr(t, f.Err)
+
+	ts := t1.Format(time.RFC3339Nano)
+	expected := qframe.New(map[string]interface{}{
+		"created":  []string{ts, ts},
+		"Deleted":  []*string{&ts, nil},
+		"id":       []int{1, 2},
+		"status":   []string{"new", "shipped"},
+		"price":    []float64{1.5, 2.5},
+		"Discount": []float64{0.5, math.NaN()},
+		"Quantity": []float64{3, math.NaN()},
+		"Note":     []*string{&note, nil},
+		"Paid":     []bool{true, false},
+	}, newqf.ColumnOrder("created", "Deleted", "id", "status", "price", "Discount", "Quantity", "Note", "Paid"),
+		newqf.Enums(map[string][]string{"status": nil}))
+	assertEquals(t, expected, f)
+
+	var result []testOrder
+	assertNotErr(t, f.ToStructs(&result))
+	if !reflect.DeepEqual(orders, result) {
+		t.Errorf("\n%v\n!=\n%v", orders, result)
+	}
+
+	var ptrResult []*testOrder
+	assertNotErr(t, f.Sort(qframe.Order{Column: "id", Reverse: true}).ToStructs(&ptrResult))
+	assertTrue(t, len(ptrResult) == 2 && ptrResult[0].ID == 2 && ptrResult[1].ID == 1)
+
+	fromPtrs := qframe.FromStructs(ptrResult)
+	assertNotErr(t, fromPtrs.Err)
+	assertEquals(t, f.Sort(qframe.Order{Column: "id", Reverse: true}), fromPtrs)
+}
+
+func TestQFrame_FromToStructsErrors(t *testing.T) {
+	type unsupported struct {
+		A []int
+	}
+	type nilBool struct {
+		A *bool
+	}
+	type missing struct {
+		A int
+		B int
+	}
+	type nonNullable struct {
+		A int
+	}
+
+	err := qframe.FromStructs([]int{1}).Err
+	assertErr(t, err, "expected slice of structs")
+	assertTrue(t, errors.Is(err, qerrors.InvalidArgument))
+	err = qframe.FromStructs([]unsupported{{}}).Err
+	assertErr(t, err, "unsupported type")
+	assertTrue(t, errors.Is(err, qerrors.TypeMismatch))
+	assertErr(t, qframe.FromStructs([]nilBool{{}}).Err, "nil bool pointer")
+
+	f := qframe.New(map[string]interface{}{"A": []float64{1, math.NaN()}})
+	var m []missing
+	assertErr(t, f.ToStructs(&m), "unknown column")
+	var n []nonNullable
+	err = f.ToStructs(&n)
+	assertErr(t, err, "missing value")
+	assertTrue(t, errors.Is(err, qerrors.TypeMismatch))
+	err = f.ToStructs(n)
+	assertErr(t, err, "expected pointer")
+	assertTrue(t, errors.Is(err, qerrors.InvalidArgument))
+	assertErr(t, qframe.FromStructs(nil).Err, "expected slice of structs")
+
+	type narrow struct {
+		I8  int8
+		U8  uint8
+		F32 float32
+	}
+	overflows := []struct {
+		name string
+		f    qframe.QFrame
+	}{
+		{name: "int to int8", f: qframe.New(map[string]interface{}{"I8": []int{300}, "U8": []int{1}, "F32": []float64{1}})},
+		{name: "negative int to uint8", f: qframe.New(map[string]interface{}{"I8": []int{1}, "U8": []int{-1}, "F32": []float64{1}})},
+		{name: "int to uint8", f: qframe.New(map[string]interface{}{"I8": []int{1}, "U8": []int{256}, "F32": []float64{1}})},
+		{name: "float to int8", f: qframe.New(map[string]interface{}{"I8": []float64{-129}, "U8": []int{1}, "F32": []float64{1}})},
+		{name: "float to float32", f: qframe.New(map[string]interface{}{"I8": []int{1}, "U8": []int{1}, "F32": []float64{1e300}})},
+	}
+	for _, tc := range overflows {
+		t.Run(tc.name, func(t *testing.T) {
+			var result []narrow
+			err := tc.f.ToStructs(&result)
+			assertErr(t, err, "overflows")
+			assertTrue(t, errors.Is(err, qerrors.TypeMismatch))
+		})
+	}
+}
+
+func TestQFrame_Parallelism(t *testing.T) {
+	size := 50000
+	a, b := make([]int, size), make([]int, size)
+	c := make([]string, size)
+	for i := range a {
+		a[i] = (i * 7919) % 1000
+		b[i] = i % 13
+		c[i] = strconv.Itoa(i % 17)
+	}
+	input := qframe.New(map[string]interface{}{"a": a, "b": b, "c": c})
+
+	pipeline := func(f qframe.QFrame) qframe.QFrame {
+		return f.Filter(qframe.Or(
+			qframe.Filter{Column: "a", Comparator: ">", Arg: 300},
+			qframe.Filter{Column: "c", Comparator: "=", Arg: "3"})).
+			Filter(qframe.Filter{Column: "a", Comparator: "=", Arg: 999, Inverse: true}).
+			Apply(
+				qframe.Instruction{Fn: func(x int) int { return 2 * x }, DstCol: "d", SrcCol1: "a"},
+				qframe.Instruction{Fn: "ToUpper", DstCol: "e", SrcCol1: "c"},
+				qframe.Instruction{Fn: func(x, y int) int { return x + y }, DstCol: "f", SrcCol1: "a", SrcCol2: "b"}).
+			GroupBy(groupby.Columns("b")).
+			Aggregate(
+				qframe.Aggregation{Fn: "sum", Column: "d"},
+				qframe.Aggregation{Fn: "max", Column: "f"},
+				qframe.Aggregation{Fn: "count", Column: "a"},
+				qframe.Aggregation{Fn: func(xs []*string) *string { return xs[0] }, Column: "e"})
+	}
+
+	expected := pipeline(input)
+	assertNotErr(t, expected.Err)
+
+	for _, n := range []int{2, 4, 7} {
+		t.Run(fmt.Sprintf("per frame %d", n), func(t *testing.T) {
+			assertEquals(t, expected, pipeline(input.WithParallelism(n)))
+		})
+
+		t.Run(fmt.Sprintf("global %d", n), func(t *testing.T) {
+			qframe.SetParallelism(n)
+			defer qframe.SetParallelism(1)
+			assertTrue(t, qframe.Parallelism() == n)
+			assertEquals(t, expected, pipeline(input))
+		})
+	}
+
+	// Errors are reported the same way as when running serially
+	f := input.WithParallelism(4).Filter(qframe.Filter{Column: "a", Comparator: "~", Arg: 1})
+	assertErr(t, f.Err, "Filter column 'a'")
+}
+
+func TestQFrame_GroupByOrder(t *testing.T) {
+	f := qframe.New(map[string]interface{}{
+		"a": []int{3, 1, 3, 2, 1},
+		"b": []int{1, 2, 3, 4, 5},
+	})
+
+	expected := qframe.New(map[string]interface{}{
+		"a": []int{3, 1, 2},
+		"b": []int{4, 7, 4},
+	})
+	assertEquals(t, expected, f.GroupBy(groupby.Columns("a")).Aggregate(qframe.Aggregation{Fn: "sum", Column: "b"}))
+
+	expected = qframe.New(map[string]interface{}{
+		"a": []int{3, 1, 2},
+		"b": []int{1, 2, 4},
+	})
+	assertEquals(t, expected, f.Distinct(groupby.Columns("a")))
+}
+
+func TestQFrame_GroupByParallel(t *testing.T) {
+	size := 100000
+	a := make([]int, size)
+	b := make([]string, size)
+	for i := range a {
+		a[i] = (i * 7919) % 20011
+		b[i] = strconv.Itoa(i % 3)
+	}
+	input := qframe.New(map[string]interface{}{"a": a, "b": b}).Sort(qframe.Order{Column: "b"})
+
+	group := func(f qframe.QFrame, fns ...groupby.ConfigFunc) (qframe.QFrame, qframe.GroupStats) {
+		g := f.GroupBy(append(fns, groupby.Columns("a", "b"))...)
+		return g.Aggregate(qframe.Aggregation{Fn: "count", Column: "a", As: "count"}), g.Stats
+	}
+
+	expected, expectedStats := group(input)
+	assertNotErr(t, expected.Err)
+
+	for _, n := range []int{2, 3, 8} {
+		t.Run(fmt.Sprintf("parallelism %d", n), func(t *testing.T) {
+			actual, stats := group(input.WithParallelism(n))
+			assertEquals(t, expected, actual)
+			assertTrue(t, stats.GroupCount == expectedStats.GroupCount)
+			assertTrue(t, stats.LoadFactor > 0 && stats.LoadFactor <= 1)
+
+			unordered, _ := group(input.WithParallelism(n), groupby.Unordered(true))
+			byKey := []qframe.Order{{Column: "a"}, {Column: "b"}}
+			assertEquals(t, expected.Sort(byKey...), unordered.Sort(byKey...))
+
+			assertEquals(t, input.Distinct(groupby.Columns("a")), input.WithParallelism(n).Distinct(groupby.Columns("a")))
+		})
+	}
+}
+
+func TestQFrame_SortAlgorithms(t *testing.T) {
+	size := 20000
+	ints, floats, zero := make([]int, size), make([]float64, size), make([]int, size)
+	enums := make([]*string, size)
+	values := []string{"a", "b", "c"}
+	for i := range ints {
+		x := (i * 7919) % 1001
+		ints[i] = x - 500
+		floats[i] = float64(x-500) / 3
+		if x%10 == 0 {
+			floats[i] = math.NaN()
+		}
+		if x%7 != 0 {
+			enums[i] = &values[x%3]
+		}
+	}
+
+	input := qframe.New(map[string]interface{}{"i": ints, "f": floats, "e": enums, "zero": zero},
+		newqf.Enums(map[string][]string{"e": {"c", "a", "b"}}))
+
+	for _, col := range []string{"i", "f", "e"} {
+		for _, reverse := range []bool{false, true} {
+			for _, nullLast := range []bool{false, true} {
+				t.Run(fmt.Sprintf("%s reverse=%t nullLast=%t", col, reverse, nullLast), func(t *testing.T) {
+					o := qframe.Order{Column: col, Reverse: reverse, NullLast: nullLast}
+
+					// Sorting on more than one column forces a comparison based sort
+					expected := input.Sort(o, qframe.Order{Column: "zero"}).Select(col)
+					assertEquals(t, expected, input.Sort(o).Select(col))
+					assertEquals(t, expected, input.WithParallelism(3).Sort(o, qframe.Order{Column: "zero"}).Select(col))
+				})
+			}
+		}
+	}
+
+	// Single column sorts are stable, both using radix sort and for small QFrames
+	for _, f := range []qframe.QFrame{input, input.Slice(0, 255)} {
+		for _, col := range []string{"i", "f", "e"} {
+			other := "i"
+			if col == "i" {
+				other = "f"
+			}
+			expected := f.Sort(qframe.Order{Column: col}, qframe.Order{Column: other}).Select(col, other)
+			sorted := f.Sort(qframe.Order{Column: other}).Sort(qframe.Order{Column: col})
+			assertEquals(t, expected, sorted.Select(col, other))
+		}
+	}
+}
+
+func TestLazyFrame(t *testing.T) {
+	input := `a,b,c,bad
+1,x,10,foo
+5,y,20,bar
+3,x,30,baz
+4,z,40,qux
+2,y,50,quux
+`
+	csvConf := csv.Types(map[string]string{"bad": "int"})
+	assertErr(t, qframe.ReadCSV(strings.NewReader(input), csvConf).Err, "int")
+
+	eager := qframe.ReadCSV(strings.NewReader(input)).
+		Eval("d", qframe.Expr("+", types.ColumnName("a"), types.ColumnName("c"))).
+		Eval("unused", qframe.Expr("abs", types.ColumnName("c"))).
+		Select("a", "b", "d").
+		Filter(qframe.Filter{Column: "a", Comparator: ">", Arg: 1}).
+		Sort(qframe.Order{Column: "d", Reverse: true}).
+		Filter(qframe.Filter{Column: "b", Comparator: "!=", Arg: "z"}).
+		Slice(0, 2)
+
+	lazy := qframe.LazyCSV(strings.NewReader(input), csvConf).
+		Eval("d", qframe.Expr("+", types.ColumnName("a"), types.ColumnName("c"))).
+		Eval("unused", qframe.Expr("abs", types.ColumnName("c"))).
+		Select("a", "b", "d").
+		Filter(qframe.Filter{Column: "a", Comparator: ">", Arg: 1}).
+		Sort(qframe.Order{Column: "d", Reverse: true}).
+		Filter(qframe.Filter{Column: "b", Comparator: "!=", Arg: "z"}).
+		Slice(0, 2)
+
+	expectedPlan := `Slice [0, 2)
+  TopN 2 [d desc]
+    Select [a b d]
+      Eval unused = ["abs", c]
+        Eval d = ["+", a, c]
+          Scan CSV columns=[a b c] filter=["and", [">", "a", 1], ["!=", "b", "z"]]
+`
+	if plan := lazy.Explain(); plan != expectedPlan {
+		t.Errorf("Unexpected plan:\n%s\nexpected:\n%s", plan, expectedPlan)
+	}
+
+	assertNotErr(t, eager.Err)
+	assertEquals(t, eager, lazy.Collect())
+}
+
+func TestLazyFrame_Operations(t *testing.T) {
+	f := qframe.New(map[string]interface{}{
+		"a": []int{1, 2, 3, 1, 2},
+		"b": []float64{1, 2, 3, 4, 5},
+		"c": []string{"x", "y", "z", "w", "v"},
+	})
+
+	table := []struct {
+		name  string
+		eager qframe.QFrame
+		lazy  qframe.LazyFrame
+		plan  string
+	}{
+		{
+			name: "filter on group column pushed before aggregate",
+			eager: f.GroupBy(groupby.Columns("a")).Aggregate(qframe.Aggregation{Fn: "sum", Column: "b"}).
+				Filter(qframe.Filter{Column: "a", Comparator: "<", Arg: 3}),
+			lazy: qframe.Lazy(f).GroupBy(groupby.Columns("a")).Aggregate(qframe.Aggregation{Fn: "sum", Column: "b"}).
+				Filter(qframe.Filter{Column: "a", Comparator: "<", Arg: 3}),
+			plan: `Aggregate by [a] [sum(b)]
+  Scan QFrame columns=[a b] filter=["<", "a", 3]
+`,
+		},
+		{
+			name: "filter on aggregate kept after aggregate",
+			eager: f.GroupBy(groupby.Columns("a")).Aggregate(qframe.Aggregation{Fn: "sum", Column: "b"}).
+				Filter(qframe.Filter{Column: "b", Comparator: ">", Arg: 4.0}),
+			lazy: qframe.Lazy(f).GroupBy(groupby.Columns("a")).Aggregate(qframe.Aggregation{Fn: "sum", Column: "b"}).
+				Filter(qframe.Filter{Column: "b", Comparator: ">", Arg: 4.0}),
+			plan: `Filter [">", "b", 4]
+  Aggregate by [a] [sum(b)]
+    Scan QFrame columns=[a b]
+`,
+		},
+		{
+			name:  "filter not moved before slice",
+			eager: f.Slice(1, 4).Filter(qframe.Filter{Column: "a", Comparator: "=", Arg: 2}),
+			lazy:  qframe.Lazy(f).Slice(1, 4).Filter(qframe.Filter{Column: "a", Comparator: "=", Arg: 2}),
+			plan: `Filter ["=", "a", 2]
+  Slice [1, 4)
+    Scan QFrame
+`,
+		},
+		{
+			name:  "filter on evaluated column",
+			eager: f.Eval("a", qframe.Expr("+", types.ColumnName("a"), 1)).Filter(qframe.Filter{Column: "a", Comparator: ">", Arg: 2}),
+			lazy:  qframe.Lazy(f).Eval("a", qframe.Expr("+", types.ColumnName("a"), 1)).Filter(qframe.Filter{Column: "a", Comparator: ">", Arg: 2}),
+			plan: `Filter [">", "a", 2]
+  Eval a = ["+", a, 1]
+    Scan QFrame
+`,
+		},
+		{
+			name:  "slice moved before select",
+			eager: f.Sort(qframe.Order{Column: "b"}).Select("c").Slice(1, 3),
+			lazy:  qframe.Lazy(f).Sort(qframe.Order{Column: "b"}).Select("c").Slice(1, 3),
+			plan: `Select [c]
+  Slice [1, 3)
+    TopN 3 [b]
+      Scan QFrame columns=[b c]
+`,
+		},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			if plan := tc.lazy.Explain(); plan != tc.plan {
+				t.Errorf("Unexpected plan:\n%s\nexpected:\n%s", plan, tc.plan)
+			}
+			assertNotErr(t, tc.eager.Err)
+			assertEquals(t, tc.eager, tc.lazy.Collect())
+		})
+	}
+
+	assertErr(t, qframe.Lazy(f).Select("a").Filter(qframe.Filter{Column: "b", Comparator: ">", Arg: 1.0}).Collect().Err, "unknown column")
+	assertErr(t, qframe.Lazy(f).Sort(qframe.Order{Column: "a"}).Slice(0, 10).Collect().Err, "Slice")
+
+	// Evals of unused columns fail as when executed directly
+	unused := qframe.Expr("abs", types.ColumnName("c"))
+	assertErr(t, f.Eval("d", unused).Select("a").Err, "abs")
+	assertErr(t, qframe.Lazy(f).Eval("d", unused).Select("a").Collect().Err, "abs")
+}
+
+func TestQFrame_TopN(t *testing.T) {
+	size := 1000
+	a, b := make([]int, size), make([]float64, size)
+	for i := range a {
+		a[i] = (i * 7919) % size
+		b[i] = float64(i % 10)
+		if i%17 == 0 {
+			b[i] = math.NaN()
+		}
+	}
+	f := qframe.New(map[string]interface{}{"a": a, "b": b})
+
+	for _, n := range []int{0, 1, 10, 999, 1000, 2000} {
+		for _, orders := range [][]qframe.Order{
+			{{Column: "a"}},
+			{{Column: "a", Reverse: true}},
+			{{Column: "b", NullLast: true}, {Column: "a", Reverse: true}},
+		} {
+			t.Run(fmt.Sprintf("n=%d %v", n, orders), func(t *testing.T) {
+				sorted := f.Sort(orders...)
+				end := n
+				if end > size {
+					end = size
+				}
+				assertEquals(t, sorted.Slice(0, end), f.TopN(n, orders...))
+			})
+		}
+	}
+
+	// Equal rows keep their order
+	assertEquals(t, f.Slice(0, 5), f.TopN(5))
+	assertEquals(t, f.Filter(qframe.Filter{Column: "b", Comparator: "=", Arg: 9.0}).Slice(0, 3),
+		f.TopN(3, qframe.Order{Column: "b", Reverse: true}))
+
+	assertErr(t, f.TopN(1, qframe.Order{Column: "x"}).Err, "unknown column")
+	assertErr(t, f.TopN(-1, qframe.Order{Column: "a"}).Err, "non negative")
+}
+
+func TestGrouper_TopN(t *testing.T) {
+	f := qframe.New(map[string]interface{}{
+		"g": []string{"x", "y", "x", "y", "x", "z"},
+		"v": []int{1, 2, 3, 4, 5, 6},
+	})
+
+	expected := qframe.New(map[string]interface{}{
+		"g": []string{"x", "x", "y", "y", "z"},
+		"v": []int{5, 3, 4, 2, 6},
+	})
+	assertEquals(t, expected, f.GroupBy(groupby.Columns("g")).TopN(2, qframe.Order{Column: "v", Reverse: true}))
+	assertErr(t, f.GroupBy(groupby.Columns("g")).TopN(2, qframe.Order{Column: "x"}).Err, "unknown column")
+}
+
+func TestQFrame_WithContext(t *testing.T) {
+	size := 50000
+	a, b := make([]int, size), make([]float64, size)
+	for i := range a {
+		a[i] = (i * 7919) % size
+		b[i] = float64(i % 13)
+	}
+	input := qframe.New(map[string]interface{}{"a": a, "b": b})
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	operations := []struct {
+		name string
+		fn   func(f qframe.QFrame) error
+	}{
+		{name: "filter", fn: func(f qframe.QFrame) error {
+			return f.Filter(qframe.Filter{Column: "a", Comparator: ">", Arg: 10}).Err
+		}},
+		{name: "group by", fn: func(f qframe.QFrame) error { return f.GroupBy(groupby.Columns("a")).Err }},
+		{name: "distinct", fn: func(f qframe.QFrame) error { return f.Distinct(groupby.Columns("b")).Err }},
+		{name: "radix sort", fn: func(f qframe.QFrame) error { return f.Sort(qframe.Order{Column: "a"}).Err }},
+		{name: "sort", fn: func(f qframe.QFrame) error {
+			return f.Sort(qframe.Order{Column: "b"}, qframe.Order{Column: "a"}).Err
+		}},
+	}
+
+	for _, op := range operations {
+		for _, n := range []int{1, 4} {
+			t.Run(fmt.Sprintf("%s parallelism %d", op.name, n), func(t *testing.T) {
+				f := input.WithParallelism(n)
+				assertNotErr(t, op.fn(f.WithContext(context.Background())))
+
+				err := op.fn(f.WithContext(canceled))
+				assertErr(t, err, "context canceled")
+				assertTrue(t, qerrors.IsCanceled(err))
+				assertTrue(t, !qerrors.IsMemoryLimit(err))
+			})
+		}
+	}
+
+	t.Run("cancel during filter", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		calls := 0
+		f := input.WithContext(ctx).Filter(qframe.Filter{Column: "a", Comparator: func(x int) bool {
+			calls++
+			cancel()
+			return true
+		}})
+		assertTrue(t, qerrors.IsCanceled(f.Err))
+		assertTrue(t, calls < size)
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+		defer cancel()
+		f := input.WithContext(ctx).Sort(qframe.Order{Column: "a"})
+		assertErr(t, f.Err, "deadline exceeded")
+		assertTrue(t, qerrors.IsCanceled(f.Err))
+	})
+
+	t.Run("inherited", func(t *testing.T) {
+		f := input.WithContext(canceled).Select("a")
+		assertTrue(t, f.Context() == canceled)
+		assertTrue(t, input.Context() == context.Background())
+		assertTrue(t, qerrors.IsCanceled(f.Filter(qframe.Filter{Column: "a", Comparator: ">", Arg: 10}).Err))
+	})
+}
+
+func TestQFrame_WithMemoryLimit(t *testing.T) {
+	size := 10000
+	a := make([]int, size)
+	for i := range a {
+		a[i] = i
+	}
+	input := qframe.New(map[string]interface{}{"a": a})
+	frameSize := input.ByteSize()
+
+	for _, n := range []int{1, 4} {
+		t.Run(fmt.Sprintf("parallelism %d", n), func(t *testing.T) {
+			f := input.WithParallelism(n)
+
+			// Not enough for anything
+			limited := f.WithMemoryLimit(frameSize)
+			err := limited.Filter(qframe.Filter{Column: "a", Comparator: ">", Arg: 10}).Err
+			assertErr(t, err, "memory limit exceeded")
+			assertTrue(t, qerrors.IsMemoryLimit(err))
+			assertTrue(t, !qerrors.IsCanceled(err))
+			assertTrue(t, qerrors.IsMemoryLimit(limited.Sort(qframe.Order{Column: "a"}).Err))
+
+			// Enough for filtering and sorting but not for the hash table when all values are distinct
+			limited = f.WithMemoryLimit(frameSize + 30*size)
+			assertNotErr(t, limited.Filter(qframe.Filter{Column: "a", Comparator: ">", Arg: 10}).Err)
+			assertNotErr(t, limited.Sort(qframe.Order{Column: "a", Reverse: true}).Err)
+			err = limited.GroupBy(groupby.Columns("a")).Err
+			assertErr(t, err, "GroupBy")
+			assertTrue(t, qerrors.IsMemoryLimit(err))
+			assertTrue(t, qerrors.IsMemoryLimit(limited.Distinct().Err))
+
+			// Removing the limit
+			assertNotErr(t, limited.WithMemoryLimit(0).GroupBy(groupby.Columns("a")).Err)
+		})
+	}
+}
+
+func TestQFrame_ErrorKinds(t *testing.T) {
+	f := qframe.New(map[string]interface{}{"a": []int{1, 2, 3}, "b": []string{"x", "y", "z"}})
+
+	table := []struct {
+		name string
+		err  error
+		kind qerrors.Kind
+	}{
+		{name: "unknown filter column", err: f.Filter(qframe.Filter{Column: "c", Comparator: ">", Arg: 1}).Err, kind: qerrors.UnknownColumn},
+		{name: "unknown sort column", err: f.Sort(qframe.Order{Column: "c"}).Err, kind: qerrors.UnknownColumn},
+		{name: "unknown group by column", err: f.GroupBy(groupby.Columns("c")).Err, kind: qerrors.UnknownColumn},
+		{name: "filter argument type", err: f.Filter(qframe.Filter{Column: "a", Comparator: ">", Arg: "x"}).Err, kind: qerrors.TypeMismatch},
+		{name: "filter operator", err: f.Filter(qframe.Filter{Column: "b", Comparator: "?", Arg: "x"}).Err, kind: qerrors.InvalidArgument},
+		{name: "apply function type", err: f.Apply(qframe.Instruction{Fn: func(x float64) float64 { return x }, DstCol: "a", SrcCol1: "a"}).Err, kind: qerrors.TypeMismatch},
+		{name: "slice", err: f.Slice(2, 1).Err, kind: qerrors.InvalidArgument},
+		{name: "empty and", err: f.Filter(qframe.And()).Err, kind: qerrors.InvalidArgument},
+		{name: "unknown aggregation", err: f.GroupBy(groupby.Columns("b")).Aggregate(qframe.Aggregation{Fn: "foo", Column: "a"}).Err, kind: qerrors.InvalidArgument},
+		{name: "csv", err: qframe.ReadCSV(strings.NewReader("a\n1\nx"), csv.Types(map[string]string{"a": "int"})).Err, kind: qerrors.Parse},
+		{name: "json", err: qframe.ReadJSON(strings.NewReader(`[{"a": 1}`)).Err, kind: qerrors.Parse},
+		{name: "open binary", err: qframe.OpenBinary(filepath.Join(t.TempDir(), "missing")).Err, kind: qerrors.IO},
+		{name: "write", err: f.ToCSV(errorWriter{}), kind: qerrors.IO},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			assertTrue(t, tc.err != nil)
+			assertTrue(t, qerrors.KindOf(tc.err) == tc.kind)
+			assertTrue(t, errors.Is(tc.err, tc.kind))
+
+			var qErr qerrors.Error
+			assertTrue(t, errors.As(tc.err, &qErr))
+			assertTrue(t, qErr.Kind() == tc.kind)
+			for _, k := range []qerrors.Kind{qerrors.UnknownColumn, qerrors.TypeMismatch, qerrors.InvalidArgument, qerrors.IO, qerrors.Parse} {
+				if k != tc.kind {
+					assertTrue(t, !errors.Is(tc.err, k))
+				}
+			}
+		})
+	}
+
+	t.Run("csv position", func(t *testing.T) {
+		input := "a,b\n1,2\n\n3,x\n"
+		f := qframe.ReadCSV(strings.NewReader(input), csv.Types(map[string]string{"b": "int"}), csv.IgnoreEmptyLines(true))
+		var qErr qerrors.Error
+		assertTrue(t, errors.As(f.Err, &qErr))
+		assertTrue(t, qErr.Kind() == qerrors.Parse)
+		assertTrue(t, qErr.Line() == 4)
+		assertTrue(t, qErr.Column() == 2)
+		assertErr(t, f.Err, "line 4, column 2")
+	})
+
+	t.Run("fixed width position", func(t *testing.T) {
+		input := "ID  N\n   1a\n  x2b"
+		f := qframe.ReadFixedWidth(strings.NewReader(input),
+			fixedwidth.Columns(
+				fixedwidth.Column{Name: "ID", Start: 0, Width: 4, Type: "int"},
+				fixedwidth.Column{Name: "N", Start: 4, Width: 1}),
+			fixedwidth.SkipLines(1))
+		var qErr qerrors.Error
+		assertTrue(t, errors.As(f.Err, &qErr))
+		assertTrue(t, qErr.Kind() == qerrors.Parse)
+		assertTrue(t, qErr.Line() == 3)
+		assertTrue(t, qErr.Column() == 1)
+	})
+
+	t.Run("unwrap", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := f.WithContext(ctx).Sort(qframe.Order{Column: "a"}).Err
+		assertTrue(t, errors.Is(err, context.Canceled))
+		assertTrue(t, qerrors.KindOf(err) == qerrors.Other)
+		assertTrue(t, errors.Unwrap(err) == context.Canceled)
+		assertTrue(t, qerrors.KindOf(errors.New("plain")) == qerrors.Other)
+	})
+}
+
+type errorWriter struct{}
+
+func (errorWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestQFrame_Describe(t *testing.T) {
+	a, b, c := "a", "b", "c"
+	f := qframe.New(map[string]interface{}{
+		"i": []int{4, 1, 3, 2, 5},
+		"f": []float64{1.5, math.NaN(), 2.5, 2.5, 4.5},
+		"b": []bool{true, false, true, true, false},
+		"s": []*string{&a, &b, nil, &b, &c},
+		"e": []*string{&c, &c, &a, nil, nil},
+	}, newqf.ColumnOrder("i", "f", "b", "s", "e"), newqf.Enums(map[string][]string{"e": nil}))
+
+	nan := math.NaN()
+	expected := qframe.New(map[string]interface{}{
+		"column":     []string{"i", "f", "b", "s", "e"},
+		"type":       []string{"int", "float", "bool", "string", "enum"},
+		"count":      []int{5, 4, 5, 4, 3},
+		"nulls":      []int{0, 1, 0, 1, 2},
+		"distinct":   []int{5, 3, 2, 3, 2},
+		"min":        []float64{1, 1.5, nan, nan, nan},
+		"max":        []float64{5, 4.5, nan, nan, nan},
+		"mean":       []float64{3, 2.75, nan, nan, nan},
+		"25%":        []float64{2, 2.25, nan, nan, nan},
+		"50%":        []float64{3, 2.5, nan, nan, nan},
+		"75%":        []float64{4, 3, nan, nan, nan},
+		"top":        []*string{nil, nil, nil, &b, &c},
+		"freq":       []int{0, 0, 0, 2, 2},
+		"true_ratio": []float64{nan, nan, 0.6, nan, nan},
+	}, newqf.ColumnOrder("column", "type", "count", "nulls", "distinct", "min", "max", "mean",
+		"25%", "50%", "75%", "top", "freq", "true_ratio"))
+
+	out := f.Describe()
+	assertEquals(t, expected, out.Drop("std"))
+
+	std := out.MustFloatView("std").Slice()
+	assertTrue(t, math.Abs(std[0]-math.Sqrt(2.5)) < 1e-12)
+	assertTrue(t, math.Abs(std[1]-math.Sqrt(4.75/3)) < 1e-12)
+	for _, x := range std[2:] {
+		assertTrue(t, math.IsNaN(x))
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		out := f.Filter(qframe.Filter{Column: "i", Comparator: ">", Arg: 10}).Describe()
+		assertNotErr(t, out.Err)
+		assertTrue(t, out.Len() == 5)
+		counts := out.MustIntView("count").Slice()
+		assertTrue(t, reflect.DeepEqual(counts, []int{0, 0, 0, 0, 0}))
+		assertTrue(t, math.IsNaN(out.MustFloatView("mean").ItemAt(0)))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		out := f.Select("x").Describe()
+		assertErr(t, out.Err, "unknown column")
+	})
+}
+
+func TestQFrame_ValueCounts(t *testing.T) {
+	a, b, c := "a", "b", "c"
+	f := qframe.New(map[string]interface{}{
+		"s": []*string{&a, &b, nil, &b, &c, &c, nil, &c},
+		"i": []int{1, 1, 2, 1, 2, 2, 2, 1},
+	}, newqf.ColumnOrder("s", "i"))
+
+	table := []struct {
+		name     string
+		configs  []valuecounts.ConfigFunc
+		expected map[string]interface{}
+	}{
+		{
+			name:     "sorted",
+			configs:  []valuecounts.ConfigFunc{valuecounts.Columns("s")},
+			expected: map[string]interface{}{"s": []*string{&c, &b, nil, &a}, "count": []int{3, 2, 2, 1}},
+		},
+		{
+			name:     "unsorted",
+			configs:  []valuecounts.ConfigFunc{valuecounts.Columns("s"), valuecounts.Sort(false)},
+			expected: map[string]interface{}{"s": []*string{&a, &b, nil, &c}, "count": []int{1, 2, 2, 3}},
+		},
+		{
+			name:     "drop null",
+			configs:  []valuecounts.ConfigFunc{valuecounts.Columns("s"), valuecounts.DropNull(true)},
+			expected: map[string]interface{}{"s": []*string{&c, &b, &a}, "count": []int{3, 2, 1}},
+		},
+		{
+			name:     "normalize",
+			configs:  []valuecounts.ConfigFunc{valuecounts.Columns("i"), valuecounts.Normalize(true)},
+			expected: map[string]interface{}{"i": []int{1, 2}, "proportion": []float64{0.5, 0.5}},
+		},
+		{
+			name:    "all columns",
+			configs: []valuecounts.ConfigFunc{},
+			expected: map[string]interface{}{
+				"s":     []*string{&b, nil, &c, &a, &c},
+				"i":     []int{1, 2, 2, 1, 1},
+				"count": []int{2, 2, 2, 1, 1}},
+		},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			out := f.ValueCounts(tc.configs...)
+			assertNotErr(t, out.Err)
+			assertEquals(t, qframe.New(tc.expected, newqf.ColumnOrder(out.ColumnNames()...)), out)
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		assertErr(t, f.ValueCounts(valuecounts.Columns("x")).Err, "unknown column")
+		out := f.Copy("count", "i").ValueCounts(valuecounts.Columns("count"))
+		assertTrue(t, errors.Is(out.Err, qerrors.InvalidArgument))
+	})
+}
+
+func TestQFrame_Cut(t *testing.T) {
+	nan := math.NaN()
+	f := qframe.New(map[string]interface{}{
+		"f": []float64{0, 1, 2.5, 4, nan, 5, 10},
+		"i": []int{1, 2, 3, 4, 5, 6, 7},
+	}, newqf.ColumnOrder("f", "i"))
+
+	str := func(s ...string) []*string {
+		result := make([]*string, len(s))
+		for i := range s {
+			if s[i] != "" {
+				result[i] = &s[i]
+			}
+		}
+		return result
+	}
+
+	table := []struct {
+		name     string
+		quantile bool
+		src      string
+		configs  []cut.ConfigFunc
+		expected []*string
+		labels   []string
+	}{
+		{
+			name:     "edges",
+			src:      "f",
+			configs:  []cut.ConfigFunc{cut.Edges(0, 2, 5)},
+			expected: str("[0, 2]", "[0, 2]", "(2, 5]", "(2, 5]", "", "(2, 5]", ""),
+			labels:   []string{"[0, 2]", "(2, 5]"},
+		},
+		{
+			name:     "edges left closed",
+			src:      "f",
+			configs:  []cut.ConfigFunc{cut.Edges(1, 4, 5), cut.Left(true)},
+			expected: str("", "[1, 4)", "[1, 4)", "[4, 5]", "", "[4, 5]", ""),
+			labels:   []string{"[1, 4)", "[4, 5]"},
+		},
+		{
+			name:     "equal width",
+			src:      "f",
+			configs:  []cut.ConfigFunc{cut.Bins(3), cut.Labels("low", "mid", "high")},
+			expected: str("low", "low", "low", "mid", "", "mid", "high"),
+			labels:   []string{"low", "mid", "high"},
+		},
+		{
+			name:     "precision",
+			src:      "i",
+			configs:  []cut.ConfigFunc{cut.Bins(3), cut.Precision(1)},
+			expected: str("[1, 3]", "[1, 3]", "[1, 3]", "(3, 5]", "(3, 5]", "(5, 7]", "(5, 7]"),
+			labels:   []string{"[1, 3]", "(3, 5]", "(5, 7]"},
+		},
+		{
+			name:     "quantile bins",
+			quantile: true,
+			src:      "i",
+			configs:  []cut.ConfigFunc{cut.Bins(2)},
+			expected: str("[1, 4]", "[1, 4]", "[1, 4]", "[1, 4]", "(4, 7]", "(4, 7]", "(4, 7]"),
+			labels:   []string{"[1, 4]", "(4, 7]"},
+		},
+		{
+			name:     "quantile edges",
+			quantile: true,
+			src:      "f",
+			configs:  []cut.ConfigFunc{cut.Edges(0.5, 1)},
+			expected: str("", "", "", "[3.25, 10]", "", "[3.25, 10]", "[3.25, 10]"),
+			labels:   []string{"[3.25, 10]"},
+		},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			var out qframe.QFrame
+			if tc.quantile {
+				out = f.QCut("bin", tc.src, tc.configs...)
+			} else {
+				out = f.Cut("bin", tc.src, tc.configs...)
+			}
+			assertNotErr(t, out.Err)
+			expected := qframe.New(map[string]interface{}{
+				"f": f.MustFloatView("f").Slice(), "i": f.MustIntView("i").Slice(), "bin": tc.expected},
+				newqf.ColumnOrder("f", "i", "bin"), newqf.Enums(map[string][]string{"bin": tc.labels}))
+			assertEquals(t, expected, out)
+		})
+	}
+
+	t.Run("sorted by bin", func(t *testing.T) {
+		out := f.Cut("bin", "f", cut.Bins(2), cut.Labels("high", "low")).Sort(qframe.Order{Column: "bin"}, qframe.Order{Column: "i"})
+		assertNotErr(t, out.Err)
+		assertTrue(t, reflect.DeepEqual(out.MustIntView("i").Slice(), []int{5, 1, 2, 3, 4, 6, 7}))
+	})
+
+	t.Run("value counts", func(t *testing.T) {
+		out := f.Cut("f", "f", cut.Edges(0, 5, 10)).ValueCounts(valuecounts.Columns("f"), valuecounts.DropNull(true))
+		assertNotErr(t, out.Err)
+		assertTrue(t, reflect.DeepEqual(out.MustIntView("count").Slice(), []int{5, 1}))
+	})
+
+	t.Run("edges", func(t *testing.T) {
+		edges, err := f.CutEdges("i", cut.Bins(4))
+		assertNotErr(t, err)
+		assertTrue(t, reflect.DeepEqual(edges, []float64{1, 2.5, 4, 5.5, 7}))
+
+		edges, err = f.QCutEdges("f", cut.Bins(2))
+		assertNotErr(t, err)
+		assertTrue(t, reflect.DeepEqual(edges, []float64{0, 3.25, 10}))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		table := []struct {
+			name string
+			out  qframe.QFrame
+			kind qerrors.Kind
+		}{
+			{name: "unknown column", out: f.Cut("bin", "x", cut.Bins(2)), kind: qerrors.UnknownColumn},
+			{name: "non numeric", out: qframe.New(map[string]interface{}{"s": []string{"a"}}).Cut("bin", "s", cut.Bins(2)), kind: qerrors.TypeMismatch},
+			{name: "no bins", out: f.Cut("bin", "f"), kind: qerrors.InvalidArgument},
+			{name: "decreasing edges", out: f.Cut("bin", "f", cut.Edges(2, 1)), kind: qerrors.InvalidArgument},
+			{name: "label count", out: f.Cut("bin", "f", cut.Bins(2), cut.Labels("a")), kind: qerrors.InvalidArgument},
+			{name: "duplicate labels", out: f.Cut("bin", "f", cut.Edges(0, 0.01, 0.02, 0.03), cut.Precision(1)), kind: qerrors.InvalidArgument},
+			{name: "quantile range", out: f.QCut("bin", "f", cut.Edges(0, 2)), kind: qerrors.InvalidArgument},
+			{name: "duplicate quantiles", out: f.Apply(qframe.Instruction{Fn: 1, DstCol: "c"}).QCut("bin", "c", cut.Bins(2)), kind: qerrors.InvalidArgument},
+		}
+
+		for _, tc := range table {
+			t.Run(tc.name, func(t *testing.T) {
+				assertTrue(t, errors.Is(tc.out.Err, tc.kind))
+			})
+		}
+
+		// Configuration errors name the function called
+		assertErr(t, f.QCut("bin", "f").Err, "QCut: one of bins or edges")
+		_, err := f.CutEdges("f", cut.Bins(-1))
+		assertErr(t, err, "CutEdges: number of bins")
+		_, err = f.QCutEdges("f", cut.Bins(2), cut.Edges(0, 1))
+		assertErr(t, err, "QCutEdges: bins and edges")
+	})
+}
+
+func TestQFrame_CorrCov(t *testing.T) {
+	nan := math.NaN()
+	a := "a"
+	f := qframe.New(map[string]interface{}{
+		"x": []int{1, 2, 3, 4, 5},
+		"s": []*string{&a, &a, nil, &a, &a},
+		"y": []float64{2, 4, 6, 8, 10},
+		"z": []float64{5, nan, 3, 1, 2},
+		"c": []float64{1, 1, 1, 1, 1},
+	}, newqf.ColumnOrder("x", "s", "y", "z", "c"))
+
+	assertMatrix := func(t *testing.T, out qframe.QFrame, expected [][]float64) {
+		t.Helper()
+		assertNotErr(t, out.Err)
+		labels := out.MustStringView("column").Slice()
+		for j, col := range out.ColumnNames()[1:] {
+			assertTrue(t, *labels[j] == col)
+			for i, actual := range out.MustFloatView(col).Slice() {
+				if e := expected[i][j]; !(math.IsNaN(e) && math.IsNaN(actual) || math.Abs(e-actual) < 1e-12) {
+					t.Errorf("Unexpected value at %d, %d: expected %v, was %v", i, j, e, actual)
+				}
+			}
+		}
+	}
+
+	table := []struct {
+		name     string
+		cov      bool
+		configs  []corr.ConfigFunc
+		expected [][]float64
+	}{
+		{
+			name: "pearson",
+			expected: [][]float64{
+				{1, 1, -7.75 / 8.75, nan},
+				{1, 1, -7.75 / 8.75, nan},
+				{-7.75 / 8.75, -7.75 / 8.75, 1, nan},
+				{nan, nan, nan, nan}},
+		},
+		{
+			name:     "spearman",
+			configs:  []corr.ConfigFunc{corr.Columns("x", "z"), corr.Method(corr.Spearman)},
+			expected: [][]float64{{1, -0.8}, {-0.8, 1}},
+		},
+		{
+			name:     "kendall",
+			configs:  []corr.ConfigFunc{corr.Columns("z", "x"), corr.Method(corr.Kendall)},
+			expected: [][]float64{{1, -2.0 / 3}, {-2.0 / 3, 1}},
+		},
+		{
+			name:     "min periods",
+			configs:  []corr.ConfigFunc{corr.Columns("x", "y", "z"), corr.MinPeriods(5)},
+			expected: [][]float64{{1, 1, nan}, {1, 1, nan}, {nan, nan, nan}},
+		},
+		{
+			name: "covariance",
+			cov:  true,
+			expected: [][]float64{
+				{2.5, 5, -7.75 / 3, 0},
+				{5, 10, -15.5 / 3, 0},
+				{-7.75 / 3, -15.5 / 3, 8.75 / 3, 0},
+				{0, 0, 0, 0}},
+		},
+		{
+			name:     "spearman covariance",
+			cov:      true,
+			configs:  []corr.ConfigFunc{corr.Columns("x", "z"), corr.Method(corr.Spearman)},
+			expected: [][]float64{{2.5, -4.0 / 3}, {-4.0 / 3, 5.0 / 3}},
+		},
+	}
+
+	for _, tc := range table {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.cov {
+				assertMatrix(t, f.Cov(tc.configs...), tc.expected)
+			} else {
+				assertMatrix(t, f.Corr(tc.configs...), tc.expected)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		table := []struct {
+			name string
+			out  qframe.QFrame
+			kind qerrors.Kind
+		}{
+			{name: "unknown column", out: f.Corr(corr.Columns("x", "q")), kind: qerrors.UnknownColumn},
+			{name: "non numeric", out: f.Corr(corr.Columns("x", "s")), kind: qerrors.TypeMismatch},
+			{name: "unknown method", out: f.Corr(corr.Method("foo")), kind: qerrors.InvalidArgument},
+			{name: "min periods", out: f.Corr(corr.MinPeriods(0)), kind: qerrors.InvalidArgument},
+			{name: "kendall covariance", out: f.Cov(corr.Method(corr.Kendall)), kind: qerrors.InvalidArgument},
+			{name: "kendall covariance too few rows", out: f.Cov(corr.Method(corr.Kendall), corr.MinPeriods(f.Len()+1)),
+				kind: qerrors.InvalidArgument},
+		}
+
+		for _, tc := range table {
+			t.Run(tc.name, func(t *testing.T) {
+				assertTrue(t, errors.Is(tc.out.Err, tc.kind))
+			})
+		}
+	})
+}
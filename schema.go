@@ -0,0 +1,537 @@
+package qframe
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/tobgu/qframe/config/csv"
+	"github.com/tobgu/qframe/config/json"
+	"github.com/tobgu/qframe/config/newqf"
+	"github.com/tobgu/qframe/internal/ncolumn"
+	"github.com/tobgu/qframe/qerrors"
+	"github.com/tobgu/qframe/types"
+)
+
+// Field describes a column in a Schema.
+type Field struct {
+	// Name of the column.
+	Name string
+
+	// Type of the column, types.None if any type is accepted.
+	Type types.DataType
+
+	// Nullable is set if the column may contain missing values.
+	Nullable bool
+
+	// EnumValues are the valid values, in order, of an enum column.
+	// For string columns the values are only used for validation.
+	EnumValues []string
+
+	// Constraints are additional constraints on the values of the column.
+	Constraints Constraints
+}
+
+// Constraints on the values of a column.
+type Constraints struct {
+	// Min is the minimum allowed value of an int or float column, if not nil.
+	Min *float64
+
+	// Max is the maximum allowed value of an int or float column, if not nil.
+	Max *float64
+
+	// Pattern is a regular expression that all values of
+	// a string or enum column must match, if not nil.
+	Pattern *regexp.Regexp
+
+	// Unique is set if all values of the column must be unique.
+	Unique bool
+}
+
+// Schema describes the columns, and their order, of a QFrame.
+type Schema struct {
+	Fields []Field
+}
+
+// NewSchema creates a new schema from the given fields.
+func NewSchema(fields ...Field) Schema {
+	return Schema{Fields: fields}
+}
+
+// Names returns the names of all fields in the schema.
+func (s Schema) Names() []string {
+	result := make([]string, len(s.Fields))
+	for i, f := range s.Fields {
+		result[i] = f.Name
+	}
+	return result
+}
+
+// Field returns the field with the given name and true if it exists.
+func (s Schema) Field(name string) (Field, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// CSV returns a configuration function for ReadCSV reading the columns in the
+// schema, coerced into the schema types, see QFrame.Coerce. String and enum
+// columns, and enum values, are added to any types set by csv.Types and
+// csv.EnumValues before it.
+func (s Schema) CSV() csv.ConfigFunc {
+	return func(c *csv.Config) {
+		s.csvTypes(c)
+		c.Schema = s
+	}
+}
+
+// csvTypes adds the string and enum columns, and enum values, of the schema to c.
+func (s Schema) csvTypes(c *csv.Config) {
+	for _, f := range s.Fields {
+		if f.Type != types.Enum && f.Type != types.String {
+			// Other types are converted after reading if needed
+			continue
+		}
+
+		if c.Types == nil {
+			c.Types = map[string]types.DataType{}
+		}
+		c.Types[f.Name] = f.Type
+		if f.Type == types.Enum && len(f.EnumValues) > 0 {
+			if c.EnumVals == nil {
+				c.EnumVals = map[string][]string{}
+			}
+			c.EnumVals[f.Name] = f.EnumValues
+		}
+	}
+}
+
+// JSON returns a configuration function for ReadJSONWithConfig reading the columns in the
+// schema, coerced into the schema types, see QFrame.Coerce.
+func (s Schema) JSON() json.ConfigFunc {
+	return func(c *json.Config) {
+		c.Schema = s
+	}
+}
+
+// coerceSchema coerces the QFrame into schema if it is a Schema.
+func (qf QFrame) coerceSchema(schema interface{}) QFrame {
+	if s, ok := schema.(Schema); ok {
+		return qf.Coerce(s)
+	}
+	return qf
+}
+
+// Schema returns the schema of the QFrame. Float, string and enum
+// fields are always nullable and enum fields list the enum values.
+// No constraints are set.
+//
+// Time complexity O(n) where n = number of columns.
+func (qf QFrame) Schema() Schema {
+	fields := make([]Field, len(qf.columns))
+	for i, col := range qf.columns {
+		t := col.DataType()
+		fields[i] = Field{Name: col.name, Type: t, Nullable: t != types.Int && t != types.Bool}
+		if t == types.Enum {
+			view, _ := qf.EnumView(col.name)
+			fields[i].EnumValues = view.Values()
+		}
+	}
+	return Schema{Fields: fields}
+}
+
+// Violation describes a value, or column, that does not conform to a schema.
+type Violation struct {
+	// Column is the name of the column.
+	Column string
+
+	// Row is the row number of the value, -1 if the violation
+	// concerns the column as a whole.
+	Row int
+
+	// Value is a string representation of the value, "null" for missing values.
+	Value string
+
+	// Reason describes the violation.
+	Reason string
+}
+
+func (v Violation) String() string {
+	if v.Row < 0 {
+		return fmt.Sprintf("column %s: %s", v.Column, v.Reason)
+	}
+	return fmt.Sprintf("column %s, row %d, value %s: %s", v.Column, v.Row, v.Value, v.Reason)
+}
+
+// Validate checks the QFrame against the schema and returns all violations
+// found, nil if the QFrame conforms to the schema. Columns not in the schema
+// are ignored.
+//
+// Time complexity O(m * n) where m = number of fields, n = number of rows.
+func (s Schema) Validate(qf QFrame) ([]Violation, error) {
+	if qf.Err != nil {
+		return nil, qerrors.Propagate("Validate", qf.Err)
+	}
+
+	var result []Violation
+	for _, f := range s.Fields {
+		col, ok := qf.columnsByName[f.Name]
+		if !ok {
+			result = append(result, Violation{Column: f.Name, Row: -1, Reason: "missing column"})
+			continue
+		}
+
+		// Undefined columns hold no values and may be of any type
+		if f.Type != types.None && col.DataType() != f.Type && col.DataType() != types.Undefined {
+			result = append(result, Violation{
+				Column: f.Name, Row: -1, Reason: fmt.Sprintf("type %s, expected %s", col.DataType(), f.Type)})
+			continue
+		}
+
+		values, err := qf.columnValues(f.Name)
+		if err != nil {
+			return nil, qerrors.Propagate("Validate", err)
+		}
+
+		result = append(result, f.validate(values)...)
+	}
+
+	return result, nil
+}
+
+func (f Field) validate(values types.DataSlice) []Violation {
+	var result []Violation
+	violation := func(row int, value, reason string, params ...interface{}) {
+		result = append(result, Violation{Column: f.Name, Row: row, Value: value, Reason: fmt.Sprintf(reason, params...)})
+	}
+
+	checkRange := func(row int, x float64, value string) {
+		if f.Constraints.Min != nil && x < *f.Constraints.Min {
+			violation(row, value, "less than min %v", *f.Constraints.Min)
+		}
+		if f.Constraints.Max != nil && x > *f.Constraints.Max {
+			violation(row, value, "greater than max %v", *f.Constraints.Max)
+		}
+	}
+
+	var enumValues map[string]bool
+	if len(f.EnumValues) > 0 {
+		enumValues = make(map[string]bool, len(f.EnumValues))
+		for _, v := range f.EnumValues {
+			enumValues[v] = true
+		}
+	}
+
+	seen := map[interface{}]int{}
+	checkUnique := func(row int, key interface{}, value string) {
+		if !f.Constraints.Unique {
+			return
+		}
+		if first, ok := seen[key]; ok {
+			violation(row, value, "duplicate of row %d", first)
+			return
+		}
+		seen[key] = row
+	}
+
+	switch vals := values.(type) {
+	case []int:
+		for i, x := range vals {
+			value := strconv.Itoa(x)
+			checkRange(i, float64(x), value)
+			checkUnique(i, x, value)
+		}
+	case []float64:
+		for i, x := range vals {
+			if math.IsNaN(x) {
+				if !f.Nullable {
+					violation(i, "null", "null value")
+				}
+				continue
+			}
+			value := strconv.FormatFloat(x, 'f', -1, 64)
+			checkRange(i, x, value)
+			checkUnique(i, x, value)
+		}
+	case []bool:
+		for i, x := range vals {
+			checkUnique(i, x, strconv.FormatBool(x))
+		}
+	case []*string:
+		for i, x := range vals {
+			if x == nil {
+				if !f.Nullable {
+					violation(i, "null", "null value")
+				}
+				continue
+			}
+			if enumValues != nil && !enumValues[*x] {
+				violation(i, *x, "not a valid enum value")
+			}
+			if f.Constraints.Pattern != nil && !f.Constraints.Pattern.MatchString(*x) {
+				violation(i, *x, "does not match pattern %s", f.Constraints.Pattern)
+			}
+			checkUnique(i, *x, *x)
+		}
+	}
+
+	return result
+}
+
+// columnValues returns the values of a column, in row order, as one of
+// []int, []float64, []bool or []*string, or an ncolumn.Column for
+// undefined columns.
+func (qf QFrame) columnValues(name string) (types.DataSlice, error) {
+	switch qf.columnsByName[name].DataType() {
+	case types.Undefined:
+		return ncolumn.Column{}, nil
+	case types.Int:
+		view, err := qf.IntView(name)
+		if err != nil {
+			return nil, err
+		}
+		return view.Slice(), nil
+	case types.Float:
+		view, err := qf.FloatView(name)
+		if err != nil {
+			return nil, err
+		}
+		return view.Slice(), nil
+	case types.Bool:
+		view, err := qf.BoolView(name)
+		if err != nil {
+			return nil, err
+		}
+		return view.Slice(), nil
+	case types.String:
+		view, err := qf.StringView(name)
+		if err != nil {
+			return nil, err
+		}
+		return view.Slice(), nil
+	case types.Enum:
+		view, err := qf.EnumView(name)
+		if err != nil {
+			return nil, err
+		}
+		return view.Slice(), nil
+	default:
+		return nil, qerrors.TypeMismatch.New("columnValues", "unknown column type of column %s", name)
+	}
+}
+
+// Coerce returns a QFrame with the columns in the schema, in schema order,
+// converted into the types specified by the schema. Columns that are missing
+// in the QFrame are added with all values missing if the field is nullable.
+//
+// The following conversions are supported:
+//   - int, float and bool are converted into each other. Floats must be integral to
+//     be converted into ints, true is converted into 1 and non zero numbers into true.
+//   - string and enum values are parsed into int, float and bool.
+//   - any type is converted into string and enum.
+//
+// Missing values cannot be converted into int and bool.
+//
+// Time complexity O(m * n) where m = number of converted columns, n = number of rows.
+func (qf QFrame) Coerce(schema Schema) QFrame {
+	if qf.Err != nil {
+		return qf
+	}
+
+	converted := false
+	for _, f := range schema.Fields {
+		col, ok := qf.columnsByName[f.Name]
+		if !ok || (f.Type != types.None && col.DataType() != f.Type) || (f.Type == types.Enum && len(f.EnumValues) > 0) {
+			converted = true
+			break
+		}
+	}
+
+	if !converted {
+		return qf.Select(schema.Names()...)
+	}
+
+	data := make(map[string]interface{}, len(schema.Fields))
+	enums := map[string][]string{}
+	for _, f := range schema.Fields {
+		values, err := qf.coerceColumn(f)
+		if err != nil {
+			return qf.withErr(qerrors.Propagate("Coerce", err))
+		}
+
+		data[f.Name] = values
+		if f.Type == types.Enum {
+			enums[f.Name] = f.EnumValues
+		}
+	}
+
+	result := New(data, newqf.ColumnOrder(schema.Names()...), newqf.Enums(enums))
+	if result.Err != nil {
+		return qf.withErr(qerrors.Propagate("Coerce", result.Err))
+	}
+	return result
+}
+
+func (qf QFrame) coerceColumn(f Field) (types.DataSlice, error) {
+	col, ok := qf.columnsByName[f.Name]
+	if !ok {
+		if !f.Nullable {
+			return nil, qerrors.UnknownColumn.New("Coerce", "missing non nullable column %s", f.Name)
+		}
+
+		switch f.Type {
+		case types.Float:
+			values := make([]float64, qf.Len())
+			for i := range values {
+				values[i] = math.NaN()
+			}
+			return values, nil
+		case types.String, types.Enum:
+			return make([]*string, qf.Len()), nil
+		default:
+			return nil, qerrors.InvalidArgument.New("Coerce", "cannot add missing column %s of type %s", f.Name, f.Type)
+		}
+	}
+
+	values, err := qf.columnValues(f.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.Type == types.None || f.Type == col.DataType() {
+		return values, nil
+	}
+
+	if col.DataType() == types.Undefined {
+		// No values to convert
+		return emptyValues(f.Type), nil
+	}
+
+	convErr := func(row int, value interface{}) error {
+		return qerrors.TypeMismatch.New("Coerce", "column %s, row %d: cannot convert %v to %s", f.Name, row, value, f.Type)
+	}
+
+	switch f.Type {
+	case types.Int:
+		result := make([]int, qf.Len())
+		switch vals := values.(type) {
+		case []float64:
+			for i, x := range vals {
+				if math.IsNaN(x) || x != math.Trunc(x) {
+					return nil, convErr(i, x)
+				}
+				result[i] = int(x)
+			}
+		case []bool:
+			for i, x := range vals {
+				if x {
+					result[i] = 1
+				}
+			}
+		case []*string:
+			for i, x := range vals {
+				if x == nil {
+					return nil, convErr(i, "null")
+				}
+				if result[i], err = strconv.Atoi(*x); err != nil {
+					return nil, convErr(i, *x)
+				}
+			}
+		}
+		return result, nil
+	case types.Float:
+		result := make([]float64, qf.Len())
+		switch vals := values.(type) {
+		case []int:
+			for i, x := range vals {
+				result[i] = float64(x)
+			}
+		case []bool:
+			for i, x := range vals {
+				if x {
+					result[i] = 1
+				}
+			}
+		case []*string:
+			for i, x := range vals {
+				if x == nil {
+					result[i] = math.NaN()
+					continue
+				}
+				if result[i], err = strconv.ParseFloat(*x, 64); err != nil {
+					return nil, convErr(i, *x)
+				}
+			}
+		}
+		return result, nil
+	case types.Bool:
+		result := make([]bool, qf.Len())
+		switch vals := values.(type) {
+		case []int:
+			for i, x := range vals {
+				result[i] = x != 0
+			}
+		case []float64:
+			for i, x := range vals {
+				if math.IsNaN(x) {
+					return nil, convErr(i, "null")
+				}
+				result[i] = x != 0
+			}
+		case []*string:
+			for i, x := range vals {
+				if x == nil {
+					return nil, convErr(i, "null")
+				}
+				if result[i], err = strconv.ParseBool(*x); err != nil {
+					return nil, convErr(i, *x)
+				}
+			}
+		}
+		return result, nil
+	case types.String, types.Enum:
+		result := make([]*string, qf.Len())
+		format := func(i int, s string) {
+			result[i] = &s
+		}
+		switch vals := values.(type) {
+		case []int:
+			for i, x := range vals {
+				format(i, strconv.Itoa(x))
+			}
+		case []float64:
+			for i, x := range vals {
+				if !math.IsNaN(x) {
+					format(i, strconv.FormatFloat(x, 'f', -1, 64))
+				}
+			}
+		case []bool:
+			for i, x := range vals {
+				format(i, strconv.FormatBool(x))
+			}
+		case []*string:
+			copy(result, vals)
+		}
+		return result, nil
+	default:
+		return nil, qerrors.InvalidArgument.New("Coerce", "unknown type %s of column %s", f.Type, f.Name)
+	}
+}
+
+// emptyValues returns an empty slice of values of type t.
+func emptyValues(t types.DataType) types.DataSlice {
+	switch t {
+	case types.Int:
+		return []int{}
+	case types.Float:
+		return []float64{}
+	case types.Bool:
+		return []bool{}
+	default:
+		return []*string{}
+	}
+}
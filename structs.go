@@ -0,0 +1,442 @@
+package qframe
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/tobgu/qframe/config/newqf"
+	"github.com/tobgu/qframe/qerrors"
+	"github.com/tobgu/qframe/types"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// structField describes how a struct field maps to a column.
+type structField struct {
+	name     string
+	index    []int
+	typ      reflect.Type // type of the field, pointers dereferenced
+	nullable bool         // set for pointer fields
+	enum     bool
+}
+
+// dataType returns the type of the column holding the field.
+func (f structField) dataType() types.DataType {
+	switch {
+	case f.typ == timeType:
+		return types.String
+	case f.enum:
+		return types.Enum
+	}
+
+	switch f.typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		if f.nullable {
+			// Ints cannot represent missing values
+			return types.Float
+		}
+		return types.Int
+	case reflect.Float32, reflect.Float64:
+		return types.Float
+	case reflect.Bool:
+		return types.Bool
+	case reflect.String:
+		return types.String
+	}
+	return types.None
+}
+
+// structFields returns the fields of struct type t that map to columns,
+// in field order, with the fields of embedded structs inlined.
+func structFields(t reflect.Type, parentIndex []int) ([]structField, error) {
+	var result []structField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("qframe")
+		if tag == "-" {
+			continue
+		}
+
+		index := make([]int, len(parentIndex), len(parentIndex)+1)
+		copy(index, parentIndex)
+		index = append(index, i)
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				return nil, qerrors.TypeMismatch.New("structFields", "embedded struct pointer %s not supported", sf.Name)
+			}
+
+			if ft.Kind() == reflect.Struct && ft != timeType {
+				fields, err := structFields(ft, index)
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, fields...)
+				continue
+			}
+		}
+
+		if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+
+		f := structField{name: name, index: index, typ: sf.Type}
+		if f.typ.Kind() == reflect.Ptr {
+			f.typ = f.typ.Elem()
+			f.nullable = true
+		}
+
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "":
+			case "enum":
+				if f.typ.Kind() != reflect.String {
+					return nil, qerrors.TypeMismatch.New("structFields", "enum field %s must be a string", sf.Name)
+				}
+				f.enum = true
+			default:
+				return nil, qerrors.InvalidArgument.New("structFields", "unknown tag option %s on field %s", opt, sf.Name)
+			}
+		}
+
+		if f.dataType() == types.None {
+			return nil, qerrors.TypeMismatch.New("structFields", "unsupported type %s of field %s", sf.Type, sf.Name)
+		}
+
+		result = append(result, f)
+	}
+
+	return result, nil
+}
+
+func structSliceElem(t reflect.Type) (elem reflect.Type, isPtr bool, ok bool) {
+	if t.Kind() != reflect.Slice {
+		return nil, false, false
+	}
+
+	elem = t.Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem, isPtr = elem.Elem(), true
+	}
+
+	return elem, isPtr, elem.Kind() == reflect.Struct
+}
+
+// FromStructs creates a new QFrame from a slice of structs, or pointers to structs.
+// Each exported field becomes a column, in field order. Fields of embedded
+// structs are included as if they were fields of the outer struct.
+//
+// The column name and options can be set using a tag on the field:
+//
+//	type Order struct {
+//		ID     int     `qframe:"id"`
+//		Status string  `qframe:"status,enum"`
+//		Price  *float64
+//		Secret string  `qframe:"-"`
+//	}
+//
+// Field types map to columns as follows:
+//   - int and sized ints up to 32 bits unsigned -> int
+//   - float32, float64 -> float
+//   - bool -> bool
+//   - string -> string, or enum if tagged with "enum"
+//   - time.Time -> string, formatted as RFC 3339 with nanoseconds
+//
+// Pointer fields are nullable with nil read as a missing value. Since ints cannot
+// represent missing values pointers to ints are stored in float columns. Bools
+// cannot represent missing values at all, a nil pointer to a bool is an error.
+//
+// confFuncs are passed to New, enums tagged on the fields are added to
+// any enums configured.
+//
+// Time complexity O(m * n) where m = number of fields, n = number of structs.
+func FromStructs(slice interface{}, confFuncs ...newqf.ConfigFunc) QFrame {
+	v := reflect.ValueOf(slice)
+	if !v.IsValid() {
+		return QFrame{Err: qerrors.InvalidArgument.New("FromStructs", "expected slice of structs, was nil")}
+	}
+
+	elemType, isPtr, ok := structSliceElem(v.Type())
+	if !ok {
+		return QFrame{Err: qerrors.InvalidArgument.New("FromStructs", "expected slice of structs, was %s", v.Type())}
+	}
+
+	fields, err := structFields(elemType, nil)
+	if err != nil {
+		return QFrame{Err: qerrors.Propagate("FromStructs", err)}
+	}
+
+	rowCount := v.Len()
+	data := make(map[string]types.DataSlice, len(fields))
+	enums := map[string][]string{}
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+		if _, ok := data[f.name]; ok {
+			return QFrame{Err: qerrors.InvalidArgument.New("FromStructs", "duplicate column name %s", f.name)}
+		}
+
+		if f.enum {
+			enums[f.name] = nil
+		}
+
+		var col interface{}
+		switch f.dataType() {
+		case types.Int:
+			col = make([]int, rowCount)
+		case types.Float:
+			col = make([]float64, rowCount)
+		case types.Bool:
+			col = make([]bool, rowCount)
+		default:
+			col = make([]*string, rowCount)
+		}
+
+		for row := 0; row < rowCount; row++ {
+			sv := v.Index(row)
+			if isPtr {
+				if sv.IsNil() {
+					return QFrame{Err: qerrors.InvalidArgument.New("FromStructs", "nil struct pointer at index %d", row)}
+				}
+				sv = sv.Elem()
+			}
+
+			fv := sv.FieldByIndex(f.index)
+			isNull := false
+			if f.nullable {
+				isNull = fv.IsNil()
+				if !isNull {
+					fv = fv.Elem()
+				}
+			}
+
+			switch c := col.(type) {
+			case []int:
+				if fv.CanInt() {
+					c[row] = int(fv.Int())
+				} else {
+					c[row] = int(fv.Uint())
+				}
+			case []float64:
+				switch {
+				case isNull:
+					c[row] = math.NaN()
+				case fv.CanFloat():
+					c[row] = fv.Float()
+				case fv.CanInt():
+					c[row] = float64(fv.Int())
+				default:
+					c[row] = float64(fv.Uint())
+				}
+			case []bool:
+				if isNull {
+					return QFrame{Err: qerrors.InvalidArgument.New("FromStructs", "nil bool pointer in field %s at index %d", f.name, row)}
+				}
+				c[row] = fv.Bool()
+			case []*string:
+				if isNull {
+					continue
+				}
+
+				var s string
+				if f.typ == timeType {
+					s = fv.Interface().(time.Time).Format(time.RFC3339Nano)
+				} else {
+					s = fv.String()
+				}
+				c[row] = &s
+			}
+		}
+		data[f.name] = col
+	}
+
+	confFuncs = append([]newqf.ConfigFunc{newqf.ColumnOrder(names...)}, confFuncs...)
+	confFuncs = append(confFuncs, func(c *newqf.Config) {
+		if c.EnumColumns == nil {
+			c.EnumColumns = map[string][]string{}
+		}
+		for name, values := range enums {
+			if _, ok := c.EnumColumns[name]; !ok {
+				c.EnumColumns[name] = values
+			}
+		}
+	})
+
+	return New(data, confFuncs...)
+}
+
+// ToStructs stores the content of the QFrame in the slice of structs, or
+// pointers to structs, pointed to by dst. The slice is replaced by a new slice
+// with one element per row.
+//
+// Fields are mapped to columns the same way as in FromStructs. All fields
+// must have a matching column, columns without a matching field are ignored.
+// Conversions between int and float columns and fields are made as needed. Missing
+// values are set to nil in pointer fields and cause an error for other fields,
+// except for float fields where they are stored as NaN.
+//
+// Time complexity O(m * n) where m = number of fields, n = number of rows.
+func (qf QFrame) ToStructs(dst interface{}) error {
+	if qf.Err != nil {
+		return qerrors.Propagate("ToStructs", qf.Err)
+	}
+
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return qerrors.InvalidArgument.New("ToStructs", "expected pointer to slice of structs, was %v", reflect.TypeOf(dst))
+	}
+
+	elemType, isPtr, ok := structSliceElem(v.Elem().Type())
+	if !ok {
+		return qerrors.InvalidArgument.New("ToStructs", "expected pointer to slice of structs, was %s", v.Type())
+	}
+
+	fields, err := structFields(elemType, nil)
+	if err != nil {
+		return qerrors.Propagate("ToStructs", err)
+	}
+
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.name
+	}
+
+	if err := qf.checkColumns("ToStructs", names); err != nil {
+		return err
+	}
+
+	rowCount := qf.Len()
+	slice := reflect.MakeSlice(v.Elem().Type(), rowCount, rowCount)
+	structs := make([]reflect.Value, rowCount)
+	for row := range structs {
+		if isPtr {
+			sp := reflect.New(elemType)
+			slice.Index(row).Set(sp)
+			structs[row] = sp.Elem()
+		} else {
+			structs[row] = slice.Index(row)
+		}
+	}
+
+	for _, f := range fields {
+		values, err := qf.columnValues(f.name)
+		if err != nil {
+			return qerrors.Propagate("ToStructs", err)
+		}
+
+		for row, sv := range structs {
+			fv := sv.FieldByIndex(f.index)
+			if err := setStructField(fv, f, values, row); err != nil {
+				return qerrors.Propagate("ToStructs", err)
+			}
+		}
+	}
+
+	v.Elem().Set(slice)
+	return nil
+}
+
+func setStructField(fv reflect.Value, f structField, values types.DataSlice, row int) error {
+	isNull := false
+	switch vals := values.(type) {
+	case []float64:
+		// Non pointer float fields can hold NaN
+		isFloat := f.typ.Kind() == reflect.Float32 || f.typ.Kind() == reflect.Float64
+		isNull = math.IsNaN(vals[row]) && (f.nullable || !isFloat)
+	case []*string:
+		isNull = vals[row] == nil
+	}
+
+	if isNull {
+		if !f.nullable {
+			return qerrors.TypeMismatch.New("setStructField", "missing value in column %s, row %d, for non pointer field", f.name, row)
+		}
+		return nil
+	}
+
+	if f.nullable {
+		p := reflect.New(f.typ)
+		fv.Set(p)
+		fv = p.Elem()
+	}
+
+	typeErr := func() error {
+		return qerrors.TypeMismatch.New("setStructField", "cannot store column %s of type %T in field of type %s", f.name, values, f.typ)
+	}
+
+	overflowErr := func(x interface{}) error {
+		return qerrors.TypeMismatch.New("setStructField", "value %v in column %s, row %d, overflows field of type %s", x, f.name, row, f.typ)
+	}
+
+	switch vals := values.(type) {
+	case []int:
+		x := vals[row]
+		switch {
+		case fv.CanInt():
+			if fv.OverflowInt(int64(x)) {
+				return overflowErr(x)
+			}
+			fv.SetInt(int64(x))
+		case fv.CanUint():
+			if x < 0 || fv.OverflowUint(uint64(x)) {
+				return overflowErr(x)
+			}
+			fv.SetUint(uint64(x))
+		case fv.CanFloat():
+			fv.SetFloat(float64(x))
+		default:
+			return typeErr()
+		}
+	case []float64:
+		x := vals[row]
+		switch {
+		case fv.CanFloat():
+			if !math.IsInf(x, 0) && fv.OverflowFloat(x) {
+				return overflowErr(x)
+			}
+			fv.SetFloat(x)
+		case fv.CanInt() && x == math.Trunc(x):
+			// The conversion into int64 is undefined for values out of range
+			if x < math.MinInt64 || x >= math.MaxInt64 || fv.OverflowInt(int64(x)) {
+				return overflowErr(x)
+			}
+			fv.SetInt(int64(x))
+		case fv.CanUint() && x == math.Trunc(x) && x >= 0:
+			if x >= math.MaxUint64 || fv.OverflowUint(uint64(x)) {
+				return overflowErr(x)
+			}
+			fv.SetUint(uint64(x))
+		default:
+			return typeErr()
+		}
+	case []bool:
+		if fv.Kind() != reflect.Bool {
+			return typeErr()
+		}
+		fv.SetBool(vals[row])
+	case []*string:
+		switch {
+		case f.typ == timeType:
+			t, err := time.Parse(time.RFC3339Nano, *vals[row])
+			if err != nil {
+				return qerrors.Propagate("setStructField", err)
+			}
+			fv.Set(reflect.ValueOf(t))
+		case fv.Kind() == reflect.String:
+			fv.SetString(*vals[row])
+		default:
+			return typeErr()
+		}
+	}
+
+	return nil
+}
@@ -58,3 +58,21 @@ func (t FunctionType) String() string {
 		return "Unknown function"
 	}
 }
+
+// Compression identifies a compression format used for input or output data.
+type Compression string
+
+const (
+	// CompressionNone means that data is read and written as is. This is the default.
+	CompressionNone Compression = ""
+
+	// CompressionGzip reads and writes gzip compressed data.
+	CompressionGzip Compression = "gzip"
+
+	// CompressionZstd reads and writes zstd compressed data.
+	CompressionZstd Compression = "zstd"
+
+	// CompressionAuto detects the compression of input data based on its leading magic bytes.
+	// Data not matching any known format is read as is. Only valid when reading.
+	CompressionAuto Compression = "auto"
+)
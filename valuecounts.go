@@ -0,0 +1,85 @@
+package qframe
+
+import (
+	"github.com/tobgu/qframe/config/groupby"
+	"github.com/tobgu/qframe/config/valuecounts"
+	"github.com/tobgu/qframe/filter"
+	"github.com/tobgu/qframe/internal/fcolumn"
+	"github.com/tobgu/qframe/internal/icolumn"
+	"github.com/tobgu/qframe/qerrors"
+)
+
+// ValueCounts returns a frequency table of the distinct combinations of values in the
+// specified columns, or in all columns if none are specified. The returned QFrame has
+// one row per combination holding the values followed by an int column named "count"
+// with the number of occurrences of the combination. If normalized the counts are
+// replaced by a float column named "proportion" holding the share of the counted rows.
+//
+// Rows are sorted by descending count unless sorting has been turned off in which case
+// they are ordered by the position of their first row in the QFrame. Nulls are counted
+// as a value of their own unless dropped.
+//
+// Time complexity O(m * n + k * log(k)) where m = number of columns to count, n = number of rows,
+// k = number of distinct combinations.
+func (qf QFrame) ValueCounts(configFns ...valuecounts.ConfigFunc) QFrame {
+	if qf.Err != nil {
+		return qf
+	}
+
+	config := valuecounts.NewConfig(configFns)
+	if err := qf.checkColumns("ValueCounts", config.Columns); err != nil {
+		return qf.withErr(err)
+	}
+
+	columns := qf.columnsOrAll(config.Columns)
+	if len(columns) == 0 {
+		return qf.withErr(qerrors.InvalidArgument.New("ValueCounts", "no columns to count"))
+	}
+
+	dstCol := "count"
+	if config.Normalize {
+		dstCol = "proportion"
+	}
+
+	for _, col := range columns {
+		if col == dstCol {
+			return qf.withErr(qerrors.InvalidArgument.New("ValueCounts", "cannot count column named %s", dstCol))
+		}
+	}
+
+	src := qf
+	if config.DropNull {
+		clauses := make([]FilterClause, len(columns))
+		for i, col := range columns {
+			clauses[i] = Filter{Column: col, Comparator: filter.IsNotNull}
+		}
+		src = qf.Filter(And(clauses...))
+	}
+
+	g := src.GroupBy(groupby.Columns(columns...), groupby.Null(true))
+	result := g.Aggregate()
+	if result.Err != nil {
+		return qf.withErr(qerrors.Propagate("ValueCounts", result.Err))
+	}
+
+	if config.Normalize {
+		proportions := make([]float64, len(g.indices))
+		for i, ix := range g.indices {
+			proportions[i] = float64(len(ix)) / float64(src.Len())
+		}
+		result = result.setColumn(dstCol, fcolumn.New(proportions))
+	} else {
+		counts := make([]int, len(g.indices))
+		for i, ix := range g.indices {
+			counts[i] = len(ix)
+		}
+		result = result.setColumn(dstCol, icolumn.New(counts))
+	}
+
+	if !config.Unsorted {
+		// TopN is stable which keeps values with equal counts in order of appearance
+		result = result.TopN(result.Len(), Order{Column: dstCol, Reverse: true})
+	}
+
+	return result
+}